@@ -16,23 +16,46 @@ limitations under the License.
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/kudobuilder/kudo/pkg/version"
 
+	"github.com/go-logr/logr"
+	"github.com/go-logr/zapr"
 	"github.com/kudobuilder/kudo/pkg/apis"
 	"github.com/kudobuilder/kudo/pkg/controller"
+	"github.com/kudobuilder/kudo/pkg/util/kudo"
 	"github.com/kudobuilder/kudo/pkg/webhook"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	crzap "sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
-	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/signals"
 )
 
 func main() {
-	logf.SetLogger(logf.ZapLogger(false))
+	logLevel := flag.String("log-level", "info", "Log level of the manager: debug, info or error")
+	logJSON := flag.Bool("log-json", false, "Log in JSON instead of a human readable format")
+	enableLeaderElection := flag.Bool("enable-leader-election", false, "Elect a leader among the replicas of the manager, only the leader executes plans")
+	leaderElectionNamespace := flag.String("leader-election-namespace", "", "Namespace of the leader election lock, defaults to the namespace the manager runs in")
+	leaderElectionID := flag.String("leader-election-id", "kudo-manager-lock", "Name of the config map used as leader election lock")
+	watchNamespaces := flag.String("watch-namespaces", "", "Comma separated namespaces the manager watches objects in, defaults to all namespaces")
+	healthAddr := flag.String("health-addr", ":8081", "Address the /healthz and /readyz probe endpoints bind to")
+	flag.Parse()
+
+	logger, err := newLogger(*logLevel, *logJSON)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	logf.SetLogger(logger)
 	log := logf.Log.WithName("entrypoint")
 
 	// Get version of KUDO
@@ -48,7 +71,18 @@ func main() {
 
 	// Create a new Cmd to provide shared dependencies and start components
 	log.Info("setting up manager")
-	mgr, err := manager.New(cfg, manager.Options{})
+	options := manager.Options{
+		LeaderElection:          *enableLeaderElection,
+		LeaderElectionNamespace: *leaderElectionNamespace,
+		LeaderElectionID:        *leaderElectionID,
+	}
+	if *watchNamespaces != "" {
+		namespaces := strings.Split(*watchNamespaces, ",")
+		log.Info("restricting the manager to namespaces", "namespaces", namespaces)
+		options.NewCache = cache.MultiNamespacedCacheBuilder(namespaces)
+		kudo.SetWatchedNamespaces(namespaces)
+	}
+	mgr, err := manager.New(cfg, options)
 	if err != nil {
 		log.Error(err, "unable to set up overall controller manager")
 		os.Exit(1)
@@ -76,10 +110,42 @@ func main() {
 		os.Exit(1)
 	}
 
+	log.Info("setting up probes")
+	p := &probes{}
+	if err := mgr.Add(p); err != nil {
+		log.Error(err, "unable to register probes to the manager")
+		os.Exit(1)
+	}
+	stop := signals.SetupSignalHandler()
+	go func() {
+		if err := p.serve(*healthAddr, stop); err != nil {
+			log.Error(err, "unable to serve probes")
+			os.Exit(1)
+		}
+	}()
+
 	// Start the Cmd
 	log.Info("Starting the Cmd.")
-	if err := mgr.Start(signals.SetupSignalHandler()); err != nil {
+	if err := mgr.Start(stop); err != nil {
 		log.Error(err, "unable to run the manager")
 		os.Exit(1)
 	}
 }
+
+// newLogger returns a zap logger logging messages of the level and above to stderr, as JSON or human readable
+func newLogger(level string, json bool) (logr.Logger, error) {
+	var lvl zapcore.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil || (lvl != zap.DebugLevel && lvl != zap.InfoLevel && lvl != zap.ErrorLevel) {
+		return nil, fmt.Errorf("invalid log level %q: has to be debug, info or error", level)
+	}
+
+	var enc zapcore.Encoder
+	if json {
+		enc = zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	} else {
+		enc = zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig())
+	}
+	sink := zapcore.AddSync(os.Stderr)
+	core := zapcore.NewCore(&crzap.KubeAwareEncoder{Encoder: enc, Verbose: lvl == zap.DebugLevel}, sink, zap.NewAtomicLevelAt(lvl))
+	return zapr.NewLogger(zap.New(core, zap.AddCaller(), zap.ErrorOutput(sink))), nil
+}
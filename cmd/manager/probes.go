@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("probes")
+
+// probes serves the liveness and readiness endpoints of the manager. The manager is live as long as it serves
+// requests and ready once its caches are synced, which is when the manager starts the probes as a runnable.
+// All replicas are ready, regardless of whether they are the leader or not.
+type probes struct {
+	ready int32
+}
+
+// Start marks the manager as ready until it is stopped. It implements manager.Runnable.
+func (p *probes) Start(stop <-chan struct{}) error {
+	atomic.StoreInt32(&p.ready, 1)
+	<-stop
+	atomic.StoreInt32(&p.ready, 0)
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, the probes run on every replica
+func (p *probes) NeedLeaderElection() bool {
+	return false
+}
+
+// serve serves /healthz and /readyz on addr until stop is closed
+func (p *probes) serve(addr string, stop <-chan struct{}) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&p.ready) == 0 {
+			http.Error(w, "caches are not synced", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	})
+	server := &http.Server{Addr: addr, Handler: mux}
+
+	go func() {
+		<-stop
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			log.Error(err, "unable to shut down probes")
+		}
+	}()
+
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
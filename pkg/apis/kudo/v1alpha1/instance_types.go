@@ -34,6 +34,62 @@ type InstanceStatus struct {
 	// TODO turn into struct
 	ActivePlan corev1.ObjectReference `json:"activePlan,omitempty"`
 	Status     PhaseState             `json:"status,omitempty"`
+
+	// Conditions are the latest observations of the instance, e.g. whether its resources drifted from the state
+	// KUDO applied.
+	Conditions []InstanceCondition `json:"conditions,omitempty"`
+}
+
+// InstanceConditionType is the type of an instance condition.
+type InstanceConditionType string
+
+// InstanceConditionDrifted is true when resources of the instance were changed or deleted outside of KUDO after its
+// plan completed.
+const InstanceConditionDrifted InstanceConditionType = "Drifted"
+
+// InstanceCondition describes the state of an instance at a certain point.
+type InstanceCondition struct {
+	Type   InstanceConditionType  `json:"type"`
+	Status corev1.ConditionStatus `json:"status"`
+	// LastTransitionTime is the last time the condition transitioned from one status to another.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Reason is a brief CamelCase reason for the condition's last transition.
+	Reason string `json:"reason,omitempty"`
+	// Message is a human readable message with details about the transition.
+	Message string `json:"message,omitempty"`
+}
+
+// GetCondition returns the condition of the given type or nil if the instance has none.
+func (s *InstanceStatus) GetCondition(conditionType InstanceConditionType) *InstanceCondition {
+	for i := range s.Conditions {
+		if s.Conditions[i].Type == conditionType {
+			return &s.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// SetCondition adds or updates the condition of its type. The transition time is only updated when the status
+// changes. Returns true if the condition changed.
+func (s *InstanceStatus) SetCondition(condition InstanceCondition) bool {
+	existing := s.GetCondition(condition.Type)
+	if existing == nil {
+		if condition.LastTransitionTime.IsZero() {
+			condition.LastTransitionTime = metav1.Now()
+		}
+		s.Conditions = append(s.Conditions, condition)
+		return true
+	}
+	if existing.Status == condition.Status && existing.Reason == condition.Reason && existing.Message == condition.Message {
+		return false
+	}
+	if existing.Status != condition.Status {
+		existing.LastTransitionTime = metav1.Now()
+	}
+	existing.Status = condition.Status
+	existing.Reason = condition.Reason
+	existing.Message = condition.Message
+	return true
 }
 
 /*
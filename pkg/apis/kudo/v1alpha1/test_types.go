@@ -20,6 +20,9 @@ type TestSuite struct {
 	ManifestDirs []string `json:"manifestDirs"`
 	// Directories containing test cases to run.
 	TestDirs []string `json:"testDirs"`
+	// Operator packages to install into the namespace of every test case. The test cases in the tests directory of
+	// each package are run as well.
+	Packages []string `json:"packages"`
 	// Whether or not to start a local etcd and kubernetes API server for the tests.
 	StartControlPlane bool `json:"startControlPlane"`
 	// Whether or not to start a local kind cluster for the tests.
@@ -127,7 +127,7 @@ func (in *Instance) DeepCopyInto(out *Instance) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -149,6 +149,23 @@ func (in *Instance) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceCondition) DeepCopyInto(out *InstanceCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceCondition.
+func (in *InstanceCondition) DeepCopy() *InstanceCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InstanceList) DeepCopyInto(out *InstanceList) {
 	*out = *in
@@ -215,6 +232,13 @@ func (in *InstanceSpec) DeepCopy() *InstanceSpec {
 func (in *InstanceStatus) DeepCopyInto(out *InstanceStatus) {
 	*out = *in
 	out.ActivePlan = in.ActivePlan
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]InstanceCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -1411,6 +1435,11 @@ func (in *TestSuite) DeepCopyInto(out *TestSuite) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Packages != nil {
+		in, out := &in.Packages, &out.Packages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Kubectl != nil {
 		in, out := &in.Kubectl, &out.Kubectl
 		*out = make([]string, len(*in))
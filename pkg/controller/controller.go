@@ -26,6 +26,7 @@ import (
 // AddControllerToManagerFuncs is a list of functions to add all Controllers to the Manager
 var AddControllerToManagerFuncs = []func(manager.Manager) error{
 	planexecution.Add,
+	planexecution.AddDriftDetector,
 	instance.Add,
 	operator.Add,
 	operatorversion.Add,
@@ -18,11 +18,11 @@ package instance
 import (
 	"context"
 	"fmt"
-	"log"
 	"reflect"
 	"time"
 
 	"github.com/kudobuilder/kudo/pkg/util/kudo"
+	"github.com/kudobuilder/kudo/pkg/util/metrics"
 
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
@@ -38,17 +38,23 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
+var log = logf.Log.WithName("instance-controller")
+
 // Add creates a new Instance Controller and adds it to the Manager with default RBAC.
 //
 // The Manager will set fields on the Controller and start it when the Manager is started.
 func Add(mgr manager.Manager) error {
-	log.Printf("InstanceController: Registering instance controller.")
+	log.Info("Registering controller")
+	if err := metrics.RegisterInstanceCollector(mgr.GetClient()); err != nil {
+		return err
+	}
 	return add(mgr, newReconciler(mgr))
 }
 
@@ -90,7 +96,7 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 			)
 
 			if err != nil {
-				log.Printf("InstanceController: Error fetching instances list for operator %v: %v", a.Meta.GetName(), err)
+				log.Error(err, "Error fetching instances list", "operatorVersion", a.Meta.GetName())
 				return nil
 			}
 
@@ -100,13 +106,14 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 					instance.GetOperatorVersionNamespace() == a.Meta.GetNamespace() &&
 					instance.Status.ActivePlan.Name == "" {
 
-					log.Printf("InstanceController: Creating a deploy execution plan for the instance %v", instance.Name)
+					instanceLog := log.WithValues("instance", instance.Namespace+"/"+instance.Name)
+					instanceLog.Info("Creating a deploy execution plan for the instance")
 					err = createPlanAndUpdateReference(mgr.GetClient(), mgr.GetEventRecorderFor("instance-controller"), mgr.GetScheme(), "deploy", &instance)
 					if err != nil {
-						log.Printf("InstanceController: Error creating \"%v\" object for \"deploy\": %v", instance.Name, err)
+						instanceLog.Error(err, "Error creating execution plan", "plan", "deploy")
 					}
 
-					log.Printf("InstanceController: Queing instance %v for reconciliation", instance.Name)
+					instanceLog.Info("Queuing instance for reconciliation")
 					requests = append(requests, reconcile.Request{
 						NamespacedName: types.NamespacedName{
 							Name:      instance.Name,
@@ -115,14 +122,14 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 					})
 				}
 			}
-			log.Printf("InstanceController: Found %v instances to reconcile for operator %v", len(requests), a.Meta.GetName())
+			log.Info("Found instances to reconcile", "count", len(requests), "operatorVersion", a.Meta.GetName())
 			return requests
 		})
 
 	// This map function makes sure that we *ONLY* handle created operatorVersion
 	ovEventFilter := predicate.Funcs{
 		CreateFunc: func(e event.CreateEvent) bool {
-			log.Printf("InstanceController: Received create event for: %v", e.Meta.GetName())
+			log.Info("Received create event", "operatorVersion", e.Meta.GetNamespace()+"/"+e.Meta.GetName())
 			return true
 		},
 		UpdateFunc: func(e event.UpdateEvent) bool {
@@ -146,20 +153,21 @@ func instanceEventFilter(mgr manager.Manager) predicate.Funcs {
 		UpdateFunc: func(e event.UpdateEvent) bool {
 			old := e.ObjectOld.(*kudov1alpha1.Instance)
 			new := e.ObjectNew.(*kudov1alpha1.Instance)
+			instanceLog := log.WithValues("instance", new.Namespace+"/"+new.Name)
 
 			// Get the OperatorVersion that corresponds to the new instance.
 			ov := &kudov1alpha1.OperatorVersion{}
-			err := mgr.GetClient().Get(ctx,
-				types.NamespacedName{
-					Name:      new.Spec.OperatorVersion.Name,
-					Namespace: new.GetOperatorVersionNamespace(),
-				},
-				ov)
+			err := kudo.CheckNamespaceWatched("OperatorVersion", new.GetOperatorVersionNamespace(), new.Spec.OperatorVersion.Name)
+			if err == nil {
+				err = mgr.GetClient().Get(ctx,
+					types.NamespacedName{
+						Name:      new.Spec.OperatorVersion.Name,
+						Namespace: new.GetOperatorVersionNamespace(),
+					},
+					ov)
+			}
 			if err != nil {
-				log.Printf("InstanceController: Error getting operatorversion \"%v\" for instance \"%v\": %v",
-					new.Spec.OperatorVersion.Name,
-					new.Name,
-					err)
+				instanceLog.Error(err, "Error getting operator version", "operatorVersion", new.Spec.OperatorVersion.Name)
 				// TODO: We probably want to handle this differently and mark this instance as unhealthy
 				// since it's linking to a bad OV.
 				return false
@@ -180,7 +188,7 @@ func instanceEventFilter(mgr manager.Manager) predicate.Funcs {
 				}
 
 				if !planFound {
-					log.Printf("InstanceController: Could not find any plan to use to upgrade instance %v", new.Name)
+					instanceLog.Info("Could not find any plan to use to upgrade instance")
 					return false
 				}
 			} else if !reflect.DeepEqual(old.Spec, new.Spec) {
@@ -213,15 +221,15 @@ func instanceEventFilter(mgr manager.Manager) predicate.Funcs {
 							}
 
 							if planFound {
-								log.Printf("InstanceController: Instance %v updated parameter %v, but it is not associated to a trigger. Using default plan %v\n", new.Name, k, planName)
+								instanceLog.Info("Updated parameter is not associated to a trigger, using default plan", "parameter", k, "plan", planName)
 							}
 						}
 
 						if !planFound {
-							log.Printf("InstanceController: Could not find any plan to use to update instance %v", new.Name)
+							instanceLog.Info("Could not find any plan to use to update instance")
 						}
 					} else {
-						log.Printf("InstanceController: Instance %v updated parameter %v, but parameter not found in operatorversion %v\n", new.Name, k, ov.Name)
+						instanceLog.Info("Updated parameter not found in operator version", "parameter", k, "operatorVersion", ov.Name)
 					}
 				}
 			} else {
@@ -231,24 +239,24 @@ func instanceEventFilter(mgr manager.Manager) predicate.Funcs {
 				// `PlanExecution`.
 				// See https://github.com/kudobuilder/kudo/issues/422
 				if new.Status.ActivePlan.Name == "" {
-					log.Printf("InstanceController: Old and new spec matched...\n %+v ?= %+v\n", old.Spec, new.Spec)
+					instanceLog.V(1).Info("Old and new spec matched", "old", old.Spec, "new", new.Spec)
 					planName = "deploy"
 					planFound = true
 				}
 			}
 
 			if planFound {
-				log.Printf("InstanceController: Going to run plan \"%v\" for instance %v", planName, new.Name)
+				instanceLog.Info("Going to run plan", "plan", planName)
 				// Suspend the the current plan.
 				current := &kudov1alpha1.PlanExecution{}
 				err = mgr.GetClient().Get(ctx, client.ObjectKey{Name: new.Status.ActivePlan.Name, Namespace: new.Status.ActivePlan.Namespace}, current)
 				if err != nil {
-					log.Printf("InstanceController: Ignoring error when getting plan for new instance: %v", err)
+					instanceLog.Info("Ignoring error when getting plan for new instance", "error", err.Error())
 				} else {
 					if current.Status.State == kudov1alpha1.PhaseStateComplete {
-						log.Printf("InstanceController: Current plan for instance %v is already done, won't change the Suspend flag.", new.Name)
+						instanceLog.Info("Current plan is already done, won't change the suspend flag")
 					} else {
-						log.Printf("InstanceController: Suspending the PlanExecution for instance %v", new.Name)
+						instanceLog.Info("Suspending the current plan execution", "planExecution", current.Name)
 						t := true
 						current.Spec.Suspend = &t
 						did, err := controllerutil.CreateOrUpdate(ctx, mgr.GetClient(), current, func() error {
@@ -257,15 +265,15 @@ func instanceEventFilter(mgr manager.Manager) predicate.Funcs {
 							return nil
 						})
 						if err != nil {
-							log.Printf("InstanceController: Error suspending PlanExecution for instance %v: %v", new.Name, err)
+							instanceLog.Error(err, "Error suspending plan execution", "planExecution", current.Name)
 						} else {
-							log.Printf("InstanceController: Successfully suspended PlanExecution for instance %v. Returned: %v", new.Name, did)
+							instanceLog.Info("Suspended plan execution", "planExecution", current.Name, "result", did)
 						}
 					}
 				}
 
 				if err = createPlanAndUpdateReference(mgr.GetClient(), mgr.GetEventRecorderFor("instance-controller"), mgr.GetScheme(), planName, new); err != nil {
-					log.Printf("InstanceController: Error creating PlanExecution \"%v\" for instance \"%v\": %v", planName, new.Name, err)
+					instanceLog.Error(err, "Error creating plan execution", "plan", planName)
 				}
 			}
 
@@ -274,7 +282,7 @@ func instanceEventFilter(mgr manager.Manager) predicate.Funcs {
 		},
 		// New Instances should confirm there is a deployment plan without side-effects)
 		CreateFunc: func(e event.CreateEvent) bool {
-			log.Printf("InstanceController: Received create event for instance \"%v\"", e.Meta.GetName())
+			log.Info("Received create event", "instance", e.Meta.GetNamespace()+"/"+e.Meta.GetName())
 			ctx := context.TODO()
 
 			// In order to create we must have an OV and it must have a "deploy" plan.
@@ -282,10 +290,7 @@ func instanceEventFilter(mgr manager.Manager) predicate.Funcs {
 
 			ov, err := getOperatorVersion(ctx, mgr.GetClient(), nil, instance)
 			if err != nil || ov == nil {
-				log.Printf("InstanceController: Error getting operatorversion \"%v\" for instance \"%v\": %v",
-					instance.Spec.OperatorVersion.Name,
-					instance.Name,
-					err)
+				log.Error(err, "Error getting operator version", "instance", instance.Namespace+"/"+instance.Name, "operatorVersion", instance.Spec.OperatorVersion.Name)
 				// TODO: We probably want to handle this differently and mark this instance as unhealthy
 				// no ov, no create of instance
 				return false
@@ -293,13 +298,13 @@ func instanceEventFilter(mgr manager.Manager) predicate.Funcs {
 
 			planName := "deploy"
 			if _, ok := ov.Spec.Plans[planName]; !ok {
-				log.Printf("InstanceController: Could not find deploy plan \"%v\" for instance \"%v\"", planName, instance.Name)
+				log.Info("Could not find deploy plan", "instance", instance.Namespace+"/"+instance.Name, "plan", planName)
 				return false
 			}
 			return true
 		},
 		DeleteFunc: func(e event.DeleteEvent) bool {
-			log.Printf("InstanceController: Received delete event for instance \"%v\"", e.Meta.GetName())
+			log.Info("Received delete event", "instance", e.Meta.GetNamespace()+"/"+e.Meta.GetName())
 			return true
 		},
 	}
@@ -309,20 +314,21 @@ func createPlanAndUpdateReference(c client.Client, r record.EventRecorder, schem
 	ctx := context.TODO()
 
 	planExecution := newPlanExecution(instance, planName, scheme)
-	log.Printf("Creating PlanExecution of planExecution %s for instance %s", planName, instance.Name)
+	instanceLog := log.WithValues("instance", instance.Namespace+"/"+instance.Name, "plan", planName)
+	instanceLog.Info("Creating plan execution")
 	r.Event(instance, "Normal", "CreatePlanExecution", fmt.Sprintf("Creating \"%v\" planExecution on %s", planName, instance.Name))
 
 	// Make this instance the owner of the PlanExecution
 	if err := controllerutil.SetControllerReference(instance, planExecution, scheme); err != nil {
-		log.Printf("InstanceController: Error setting ControllerReference")
+		instanceLog.Error(err, "Error setting controller reference")
 		return err
 	}
 	if err := c.Create(ctx, planExecution); err != nil {
-		log.Printf("InstanceController: Error creating planexecution \"%v\": %v", planExecution.Name, err)
+		instanceLog.Error(err, "Error creating plan execution", "planExecution", planExecution.Name)
 		r.Event(instance, "Warning", "CreatePlanExecution", fmt.Sprintf("Error creating planexecution \"%v\": %v", planExecution.Name, err))
 		return err
 	}
-	log.Printf("Created PlanExecution of planExecution %s for instance %s", planName, instance.Name)
+	instanceLog.Info("Created plan execution", "planExecution", planExecution.Name)
 	r.Event(instance, "Normal", "PlanCreated", fmt.Sprintf("PlanExecution \"%v\" created", planExecution.Name))
 
 	return addActivePlanReference(c, r, planExecution, instance)
@@ -373,7 +379,7 @@ func (r *ReconcileInstance) Reconcile(request reconcile.Request) (reconcile.Resu
 		return reconcile.Result{}, err
 	}
 
-	log.Printf("InstanceController: Received Reconcile request for instance \"%+v\"", request.Name)
+	log.Info("Received reconcile request", "instance", request.NamespacedName)
 
 	// if this is new create and create and assign a planexecution and return
 	if isNewInstance(instance) {
@@ -398,19 +404,19 @@ func (r *ReconcileInstance) Reconcile(request reconcile.Request) (reconcile.Resu
 // getOperatorVersionFromNameSpacedName does the work of getting an OV from a namespaced name in an instance.  It is possible to pass a recorder as nil if an instance does not exist yet.
 func getOperatorVersion(ctx context.Context, c client.Client, r record.EventRecorder, instance *kudov1alpha1.Instance) (ov *kudov1alpha1.OperatorVersion, err error) {
 	ov = &kudov1alpha1.OperatorVersion{}
-	err = c.Get(ctx,
-		types.NamespacedName{
-			Name:      instance.Spec.OperatorVersion.Name,
-			Namespace: instance.GetOperatorVersionNamespace(),
-		},
-		ov)
+	err = kudo.CheckNamespaceWatched("OperatorVersion", instance.GetOperatorVersionNamespace(), instance.Spec.OperatorVersion.Name)
+	if err == nil {
+		err = c.Get(ctx,
+			types.NamespacedName{
+				Name:      instance.Spec.OperatorVersion.Name,
+				Namespace: instance.GetOperatorVersionNamespace(),
+			},
+			ov)
+	}
 	if err != nil {
-		log.Printf("InstanceController: Error getting operatorversion \"%v\" for instance \"%v\": %v",
-			instance.Spec.OperatorVersion.Name,
-			instance.Name,
-			err)
+		log.Error(err, "Error getting operator version", "instance", instance.Namespace+"/"+instance.Name, "operatorVersion", instance.Spec.OperatorVersion.Name)
 		if r != nil {
-			r.Event(instance, "Warning", "InvalidOperatorVersion", fmt.Sprintf("Error getting operatorversion \"%v\": %v", ov.Name, err))
+			r.Event(instance, "Warning", "InvalidOperatorVersion", fmt.Sprintf("Error getting operatorversion \"%v\": %v", instance.Spec.OperatorVersion.Name, err))
 		}
 		return nil, err
 	}
@@ -430,7 +436,6 @@ func getInstance(ctx context.Context, r *ReconcileInstance, request reconcile.Re
 		// Error reading the object - requeue the request.
 		return nil, err
 	}
-	log.Printf("InstanceController: Received Reconcile request for instance \"%+v\"", request.Name)
 	return instance, nil
 }
 
@@ -17,7 +17,6 @@ package operator
 
 import (
 	"context"
-	"log"
 
 	"k8s.io/client-go/tools/record"
 
@@ -27,15 +26,18 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
+var log = logf.Log.WithName("operator-controller")
+
 // Add creates a new Operator Controller and adds it to the Manager with default RBAC. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager) error {
-	log.Printf("OperatorController: Registering operator controller.")
+	log.Info("Registering controller")
 	return add(mgr, newReconciler(mgr))
 }
 
@@ -90,7 +92,7 @@ func (r *ReconcileOperator) Reconcile(request reconcile.Request) (reconcile.Resu
 		return reconcile.Result{}, err
 	}
 
-	log.Printf("OperatorController: Received Reconcile request for an operator named: %v", request.Name)
+	log.Info("Received reconcile request", "operator", request.NamespacedName)
 
 	return reconcile.Result{}, nil
 }
@@ -17,7 +17,6 @@ package operatorversion
 
 import (
 	"context"
-	"log"
 
 	kudov1alpha1 "github.com/kudobuilder/kudo/pkg/apis/kudo/v1alpha1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -25,15 +24,18 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
+var log = logf.Log.WithName("operatorversion-controller")
+
 // Add creates a new OperatorVersion Controller and adds it to the Manager with default RBAC. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager) error {
-	log.Printf("OperatorVersionController: Registering operatorversion controller.")
+	log.Info("Registering controller")
 	return add(mgr, newReconciler(mgr))
 }
 
@@ -87,7 +89,7 @@ func (r *ReconcileOperatorVersion) Reconcile(request reconcile.Request) (reconci
 		return reconcile.Result{}, err
 	}
 
-	log.Printf("OperatorVersionController: Received Reconcile request for an operatorVersion named: %v", request.Name)
+	log.Info("Received reconcile request", "operatorVersion", request.NamespacedName)
 
 	// TODO: Validate OperatorVersion is appropriate.
 	return reconcile.Result{}, nil
@@ -0,0 +1,235 @@
+package planexecution
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/kudobuilder/kudo/pkg/util/kudo"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	apijson "k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/apimachinery/pkg/util/jsonmergepatch"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// createObject creates a new resource and records its configuration as the last applied one
+func createObject(newResource runtime.Object, c client.Client) error {
+	if _, err := setHash(newResource); err != nil {
+		return err
+	}
+	if _, err := setLastAppliedConfig(newResource); err != nil {
+		return err
+	}
+	return c.Create(context.TODO(), newResource)
+}
+
+// applyObject updates an existing resource with a three-way merge of the configuration KUDO applied last, the new
+// configuration and the current state of the resource, like `kubectl apply` does.
+//
+// Fields that were removed from the templates since the last apply are removed from the resource, fields that were
+// set by others and are not part of the templates are kept. Fields changed by others and by the new configuration are
+// conflicts, they are logged and overwritten with the new configuration.
+//
+// Nothing is written when the hash of the rendered resource matches the one of the last applied configuration.
+func applyObject(newResource runtime.Object, existingResource runtime.Object, c client.Client, objLog logr.Logger) error {
+	hash, err := setHash(newResource)
+	if err != nil {
+		return err
+	}
+	existingAccessor, err := meta.Accessor(existingResource)
+	if err != nil {
+		return err
+	}
+	if existingAccessor.GetAnnotations()[kudo.HashAnnotation] == hash {
+		objLog.V(1).Info("Object is unchanged, skipping update")
+		return nil
+	}
+
+	modified, err := setLastAppliedConfig(newResource)
+	if err != nil {
+		return err
+	}
+	original, err := getLastAppliedConfig(existingResource)
+	if err != nil {
+		return err
+	}
+	current, err := apijson.Marshal(existingResource)
+	if err != nil {
+		return err
+	}
+
+	if fields, err := conflictingFields(original, modified, current); err != nil {
+		return err
+	} else if len(fields) > 0 {
+		objLog.Info("Overwriting fields changed outside of KUDO", "fields", strings.Join(fields, ", "))
+	}
+
+	if _, ok := newResource.(*unstructured.Unstructured); !ok {
+		err = applyStrategicMergePatch(original, modified, current, newResource, existingResource, c)
+		// Right now applying a Strategic Merge Patch to custom resources does not work. There is
+		// certain metadata needed, which when missing, leads to an invalid Content-Type Header and
+		// causes the request to fail.
+		// ( see https://github.com/kubernetes-sigs/kustomize/issues/742#issuecomment-458650435 )
+		//
+		// We solve this by checking for the specific error when a SMP is applied to custom resources
+		// and handle it by defaulting to a Merge Patch.
+		if !apierrors.IsUnsupportedMediaType(err) {
+			if err != nil {
+				objLog.Error(err, "Error when applying strategic merge patch")
+			}
+			return err
+		}
+	}
+
+	patch, err := jsonmergepatch.CreateThreeWayJSONMergePatch(original, modified, current)
+	if err != nil {
+		return err
+	}
+	if err := c.Patch(context.TODO(), existingResource, client.ConstantPatch(types.MergePatchType, patch)); err != nil {
+		objLog.Error(err, "Error when applying merge patch")
+		return err
+	}
+	return nil
+}
+
+func applyStrategicMergePatch(original, modified, current []byte, newResource runtime.Object, existingResource runtime.Object, c client.Client) error {
+	patchMeta, err := strategicpatch.NewPatchMetaFromStruct(newResource)
+	if err != nil {
+		return err
+	}
+	patch, err := strategicpatch.CreateThreeWayMergePatch(original, modified, current, patchMeta, true)
+	if err != nil {
+		return err
+	}
+	return c.Patch(context.TODO(), existingResource, client.ConstantPatch(types.StrategicMergePatchType, patch))
+}
+
+// setHash records the hash of the rendered resource in its hash annotation and returns it. The hash annotation and
+// last applied configuration are not part of the hash.
+func setHash(obj runtime.Object) (string, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return "", err
+	}
+	annotations := accessor.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	delete(annotations, kudo.HashAnnotation)
+	delete(annotations, kudo.LastAppliedConfigAnnotation)
+	accessor.SetAnnotations(annotations)
+
+	rendered, err := apijson.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+	hash := fmt.Sprintf("%x", sha256.Sum256(rendered))
+	annotations[kudo.HashAnnotation] = hash
+	accessor.SetAnnotations(annotations)
+	return hash, nil
+}
+
+// setLastAppliedConfig records the configuration of the resource in its last applied annotation. Returns the
+// resource with the annotation as JSON.
+func setLastAppliedConfig(obj runtime.Object) ([]byte, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	annotations := accessor.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	delete(annotations, kudo.LastAppliedConfigAnnotation)
+	accessor.SetAnnotations(annotations)
+
+	config, err := apijson.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	annotations[kudo.LastAppliedConfigAnnotation] = string(config)
+	accessor.SetAnnotations(annotations)
+
+	return apijson.Marshal(obj)
+}
+
+// getLastAppliedConfig returns the configuration applied to the resource last with the annotation itself, so that it
+// can be compared with a new configuration returned by setLastAppliedConfig. Returns nil if there is none.
+func getLastAppliedConfig(obj runtime.Object) ([]byte, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	config, ok := accessor.GetAnnotations()[kudo.LastAppliedConfigAnnotation]
+	if !ok {
+		return nil, nil
+	}
+
+	m := map[string]interface{}{}
+	if err := apijson.Unmarshal([]byte(config), &m); err != nil {
+		return nil, fmt.Errorf("parsing last applied configuration: %v", err)
+	}
+	if err := unstructured.SetNestedField(m, config, "metadata", "annotations", kudo.LastAppliedConfigAnnotation); err != nil {
+		return nil, err
+	}
+	return apijson.Marshal(m)
+}
+
+// conflictingFields returns the paths of the fields which are changed from the original configuration by the modified
+// configuration and by someone else in the current state to different values.
+func conflictingFields(original, modified, current []byte) ([]string, error) {
+	if original == nil {
+		// without the last applied configuration changes of others can not be told apart
+		return nil, nil
+	}
+
+	var maps [3]map[string]interface{}
+	for i, doc := range [][]byte{original, modified, current} {
+		maps[i] = map[string]interface{}{}
+		if err := apijson.Unmarshal(doc, &maps[i]); err != nil {
+			return nil, err
+		}
+		unstructured.RemoveNestedField(maps[i], "metadata", "annotations", kudo.LastAppliedConfigAnnotation)
+	}
+
+	fields := conflicts(maps[0], maps[1], maps[2], "")
+	sort.Strings(fields)
+	return fields, nil
+}
+
+func conflicts(original, modified, current map[string]interface{}, path string) []string {
+	keys := map[string]bool{}
+	for k := range original {
+		keys[k] = true
+	}
+	for k := range modified {
+		keys[k] = true
+	}
+
+	var fields []string
+	for k := range keys {
+		o, m, c := original[k], modified[k], current[k]
+		if reflect.DeepEqual(o, m) || reflect.DeepEqual(o, c) || reflect.DeepEqual(m, c) {
+			// not changed by one of both or changed to the same value
+			continue
+		}
+		om, ok1 := o.(map[string]interface{})
+		mm, ok2 := m.(map[string]interface{})
+		cm, ok3 := c.(map[string]interface{})
+		if ok1 && ok2 && ok3 {
+			fields = append(fields, conflicts(om, mm, cm, path+k+".")...)
+			continue
+		}
+		fields = append(fields, path+k)
+	}
+	return fields
+}
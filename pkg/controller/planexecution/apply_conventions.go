@@ -47,6 +47,8 @@ type kubernetesObjectEnhancer interface {
 // kustomizeEnhancer is implementation of kubernetesObjectEnhancer that uses kustomize to apply the defined conventions
 type kustomizeEnhancer struct {
 	scheme *runtime.Scheme
+	// unstructured makes the enhancer return unstructured objects which contain only the fields set in the templates
+	unstructured bool
 }
 
 // ApplyConventions accepts templates to be rendered in kubernetes and enhances them with our own KUDO conventions
@@ -118,7 +120,7 @@ func (k *kustomizeEnhancer) applyConventionsToTemplates(templates map[string]str
 		return nil, errors.Wrapf(err, "error encoding kustomized files into yaml")
 	}
 
-	objsToAdd, err := template.ParseKubernetesObjects(string(res))
+	objsToAdd, err := k.parseObjects(string(res))
 	if err != nil {
 		return nil, errors.Wrapf(err, "error parsing kubernetes objects after applying kustomize")
 	}
@@ -133,6 +135,21 @@ func (k *kustomizeEnhancer) applyConventionsToTemplates(templates map[string]str
 	return objsToAdd, nil
 }
 
+func (k *kustomizeEnhancer) parseObjects(res string) ([]runtime.Object, error) {
+	if !k.unstructured {
+		return template.ParseKubernetesObjects(res)
+	}
+	unstructuredObjs, err := template.ParseUnstructuredObjects(res)
+	if err != nil {
+		return nil, err
+	}
+	objs := make([]runtime.Object, 0, len(unstructuredObjs))
+	for _, o := range unstructuredObjs {
+		objs = append(objs, o)
+	}
+	return objs, nil
+}
+
 func setControllerReference(owner v1.Object, obj runtime.Object, scheme *runtime.Scheme) error {
 	if err := controllerutil.SetControllerReference(owner, obj.(v1.Object), scheme); err != nil {
 		return err
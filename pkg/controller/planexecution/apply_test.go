@@ -0,0 +1,205 @@
+package planexecution
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/kudobuilder/kudo/pkg/apis/kudo/v1alpha1"
+	"github.com/kudobuilder/kudo/pkg/util/kudo"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func getConfigMap(data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default"},
+		Data:       data,
+	}
+}
+
+// patchRecorder records the patches sent to the client. The fake client merges patched objects into the stored ones,
+// so removed fields can only be verified with the patch.
+type patchRecorder struct {
+	client.Client
+	patches []map[string]interface{}
+	types   []types.PatchType
+}
+
+func (r *patchRecorder) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	data, err := patch.Data(obj)
+	if err != nil {
+		return err
+	}
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	r.patches = append(r.patches, m)
+	r.types = append(r.types, patch.Type())
+	return r.Client.Patch(ctx, obj, patch, opts...)
+}
+
+// apply creates or applies obj like executeStep does
+func apply(t *testing.T, c client.Client, obj runtime.Object) {
+	existing := obj.DeepCopyObject()
+	key, _ := client.ObjectKeyFromObject(obj)
+	if err := c.Get(context.TODO(), key, existing); err != nil {
+		assert.NoError(t, createObject(obj, c))
+		return
+	}
+	assert.NoError(t, applyObject(obj, existing, c, log))
+}
+
+func TestApplyObject(t *testing.T) {
+	c := &patchRecorder{Client: fake.NewFakeClientWithScheme(scheme.Scheme)}
+
+	apply(t, c, getConfigMap(map[string]string{"a": "1", "b": "2"}))
+
+	// someone else adds a label and a key
+	cm := &corev1.ConfigMap{}
+	assert.NoError(t, c.Get(context.TODO(), client.ObjectKey{Name: "cm", Namespace: "default"}, cm))
+	assert.Contains(t, cm.Annotations, kudo.LastAppliedConfigAnnotation)
+	cm.Labels = map[string]string{"other": "x"}
+	cm.Data["c"] = "3"
+	assert.NoError(t, c.Update(context.TODO(), cm))
+
+	// the new configuration drops b and changes a
+	apply(t, c, getConfigMap(map[string]string{"a": "changed"}))
+
+	// b is removed, the changes of others are kept
+	if assert.Len(t, c.patches, 1) {
+		assert.Equal(t, types.StrategicMergePatchType, c.types[0])
+		assert.Equal(t, map[string]interface{}{"a": "changed", "b": nil}, c.patches[0]["data"])
+		assert.NotContains(t, c.patches[0]["metadata"], "labels")
+	}
+
+	cm = &corev1.ConfigMap{}
+	assert.NoError(t, c.Get(context.TODO(), client.ObjectKey{Name: "cm", Namespace: "default"}, cm))
+	assert.Equal(t, "changed", cm.Data["a"])
+	assert.Equal(t, "3", cm.Data["c"])
+	assert.Equal(t, map[string]string{"other": "x"}, cm.Labels)
+	assert.Contains(t, cm.Annotations[kudo.LastAppliedConfigAnnotation], `"data":{"a":"changed"}`)
+
+	// applying the same configuration again does not write anything
+	apply(t, c, getConfigMap(map[string]string{"a": "changed"}))
+	assert.Len(t, c.patches, 1)
+}
+
+// writeCounter counts the writes sent to the client
+type writeCounter struct {
+	client.Client
+	writes int
+}
+
+func (w *writeCounter) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	w.writes++
+	return w.Client.Create(ctx, obj, opts...)
+}
+
+func (w *writeCounter) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	w.writes++
+	return w.Client.Update(ctx, obj, opts...)
+}
+
+func (w *writeCounter) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	w.writes++
+	return w.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func TestExecuteStepSkipsUnchangedResources(t *testing.T) {
+	c := &writeCounter{Client: fake.NewFakeClientWithScheme(scheme.Scheme)}
+	render := func() []runtime.Object {
+		var resources []runtime.Object
+		for i := 0; i < 50; i++ {
+			cm := getConfigMap(map[string]string{"a": "1"})
+			cm.Name = fmt.Sprintf("cm-%d", i)
+			resources = append(resources, cm)
+		}
+		return resources
+	}
+
+	state := &v1alpha1.StepStatus{State: v1alpha1.PhaseStatePending}
+	assert.NoError(t, executeStep(v1alpha1.Step{Name: "step"}, state, render(), c, log))
+	assert.Equal(t, 50, c.writes)
+	assert.Equal(t, v1alpha1.PhaseStateComplete, state.State)
+
+	c.writes = 0
+	state = &v1alpha1.StepStatus{State: v1alpha1.PhaseStateInProgress}
+	assert.NoError(t, executeStep(v1alpha1.Step{Name: "step"}, state, render(), c, log))
+	assert.Equal(t, 0, c.writes)
+
+	changed := render()
+	changed[0].(*corev1.ConfigMap).Data["a"] = "2"
+	state = &v1alpha1.StepStatus{State: v1alpha1.PhaseStateInProgress}
+	assert.NoError(t, executeStep(v1alpha1.Step{Name: "step"}, state, changed, c, log))
+	assert.Equal(t, 1, c.writes)
+}
+
+func TestApplyObjectUnstructured(t *testing.T) {
+	s := runtime.NewScheme()
+	assert.NoError(t, v1alpha1.AddToScheme(s))
+	c := &patchRecorder{Client: fake.NewFakeClientWithScheme(s)}
+
+	instance := func(params map[string]interface{}) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion("kudo.dev/v1alpha1")
+		u.SetKind("Instance")
+		u.SetName("instance")
+		u.SetNamespace("default")
+		assert.NoError(t, unstructured.SetNestedField(u.Object, params, "spec", "parameters"))
+		return u
+	}
+
+	apply(t, c, instance(map[string]interface{}{"A": "1", "B": "2"}))
+	apply(t, c, instance(map[string]interface{}{"A": "changed"}))
+
+	if assert.Len(t, c.patches, 1) {
+		assert.Equal(t, types.MergePatchType, c.types[0])
+		assert.Equal(t, map[string]interface{}{"parameters": map[string]interface{}{"A": "changed", "B": nil}}, c.patches[0]["spec"])
+	}
+
+	i := &v1alpha1.Instance{}
+	assert.NoError(t, c.Get(context.TODO(), client.ObjectKey{Name: "instance", Namespace: "default"}, i))
+	assert.Equal(t, "changed", i.Spec.Parameters["A"])
+}
+
+func TestConflictingFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		modified string
+		current  string
+		expected []string
+	}{
+		{"no last applied configuration", "", `{"data":{"a":"1"}}`, `{"data":{"a":"2"}}`, nil},
+		{"unchanged", `{"data":{"a":"1"}}`, `{"data":{"a":"1"}}`, `{"data":{"a":"2"}}`, nil},
+		{"changed by others only", `{"data":{"a":"1"}}`, `{"data":{"a":"1","b":"2"}}`, `{"data":{"a":"3"}}`, nil},
+		{"changed to the same value", `{"data":{"a":"1"}}`, `{"data":{"a":"2"}}`, `{"data":{"a":"2"}}`, nil},
+		{"conflict", `{"data":{"a":"1","b":"1"}}`, `{"data":{"a":"2","b":"2"}}`, `{"data":{"a":"3","b":"1"}}`, []string{"data.a"}},
+		{"removed by others", `{"spec":{"replicas":1}}`, `{"spec":{"replicas":2}}`, `{"spec":{}}`, []string{"spec.replicas"}},
+		{"annotation is ignored",
+			`{"metadata":{"annotations":{"kudo.dev/last-applied-configuration":"1"}}}`,
+			`{"metadata":{"annotations":{"kudo.dev/last-applied-configuration":"2"}}}`,
+			`{"metadata":{"annotations":{"kudo.dev/last-applied-configuration":"3"}}}`, nil},
+	}
+
+	for _, tt := range tests {
+		var original []byte
+		if tt.original != "" {
+			original = []byte(tt.original)
+		}
+		fields, err := conflictingFields(original, []byte(tt.modified), []byte(tt.current))
+		assert.NoError(t, err, tt.name)
+		assert.Equal(t, tt.expected, fields, tt.name)
+	}
+}
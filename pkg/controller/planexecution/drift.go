@@ -0,0 +1,424 @@
+package planexecution
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	kudov1alpha1 "github.com/kudobuilder/kudo/pkg/apis/kudo/v1alpha1"
+	"github.com/kudobuilder/kudo/pkg/util/kudo"
+	"github.com/kudobuilder/kudo/pkg/util/template"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	apijson "k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// driftReport reports drift of the resources of an instance as condition and event
+	driftReport = "report"
+	// driftHeal additionally re-applies the drifted resources
+	driftHeal = "heal"
+)
+
+var driftLog = logf.Log.WithName("drift-detector")
+
+// driftWatchedTypes are the types of owned objects which trigger drift detection of their instance when they change
+var driftWatchedTypes = []runtime.Object{
+	&appsv1.StatefulSet{},
+	&appsv1.Deployment{},
+	&appsv1.DaemonSet{},
+	&corev1.Service{},
+	&corev1.ConfigMap{},
+}
+
+// AddDriftDetector creates a new drift detector and adds it to the Manager. The drift detector compares the resources of
+// instances which have drift detection enabled with the resources rendered by their last plan.
+func AddDriftDetector(mgr manager.Manager) error {
+	driftLog.Info("Registering controller")
+
+	r := &ReconcileDrift{Client: mgr.GetClient(), scheme: mgr.GetScheme(), recorder: mgr.GetEventRecorderFor("drift-detector"), impersonate: newImpersonator(mgr)}
+	c, err := controller.New("drift-detector", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	if err := c.Watch(&source.Kind{Type: &kudov1alpha1.Instance{}}, &handler.EnqueueRequestForObject{}); err != nil {
+		return err
+	}
+	for _, t := range driftWatchedTypes {
+		err := c.Watch(&source.Kind{Type: t}, &handler.EnqueueRequestForOwner{OwnerType: &kudov1alpha1.Instance{}, IsController: true})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var _ reconcile.Reconciler = &ReconcileDrift{}
+
+// ReconcileDrift detects changes to the resources of an instance which were made outside of KUDO after its plan
+// completed, e.g. a `kubectl edit` of a StatefulSet or a deleted Service.
+//
+// Drift detection is enabled per instance with the kudo.dev/drift-detection annotation. With "report" drift is
+// reported as Drifted condition of the instance and as event, with "heal" the drifted resources are also re-applied.
+type ReconcileDrift struct {
+	client.Client
+	scheme      *runtime.Scheme
+	recorder    record.EventRecorder
+	impersonate impersonator
+}
+
+// Reconcile compares the resources of an instance with the resources rendered by its last plan
+func (r *ReconcileDrift) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	instance := &kudov1alpha1.Instance{}
+	err := r.Get(context.TODO(), request.NamespacedName, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	instanceLog := driftLog.WithValues("instance", request.NamespacedName)
+	mode := instance.Annotations[kudo.DriftDetectionAnnotation]
+	if mode != driftReport && mode != driftHeal {
+		return reconcile.Result{}, nil
+	}
+	if instance.Status.Status != kudov1alpha1.PhaseStateComplete {
+		// resources are expected to change while a plan is running
+		return reconcile.Result{}, nil
+	}
+
+	resources, err := r.renderedResources(instance)
+	if err != nil {
+		instanceLog.Error(err, "Error rendering resources")
+		return reconcile.Result{}, err
+	}
+
+	drifts, err := detectDrift(resources, r.Client)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	condition := kudov1alpha1.InstanceCondition{
+		Type:   kudov1alpha1.InstanceConditionDrifted,
+		Status: corev1.ConditionFalse,
+		Reason: "NoDrift",
+	}
+	if len(drifts) > 0 {
+		message := strings.Join(drifts.descriptions(), "; ")
+		instanceLog.Info("Resources drifted", "drift", message)
+		r.recorder.Event(instance, "Warning", "Drift", fmt.Sprintf("Resources changed outside of KUDO: %s", message))
+
+		condition.Status = corev1.ConditionTrue
+		condition.Reason = "ResourcesChanged"
+		condition.Message = message
+
+		if mode == driftHeal {
+			c, err := clientFor(instance, r.Client, r.impersonate)
+			if err != nil {
+				instanceLog.Error(err, "Error creating client impersonating the service account of the instance")
+				return reconcile.Result{}, err
+			}
+			if err := drifts.heal(c, instanceLog); err != nil {
+				r.recorder.Event(instance, "Warning", "DriftHealFailed", err.Error())
+				return reconcile.Result{}, err
+			}
+			r.recorder.Event(instance, "Normal", "DriftHealed", fmt.Sprintf("Re-applied resources: %s", message))
+
+			condition.Status = corev1.ConditionFalse
+			condition.Reason = "Healed"
+		}
+	}
+
+	if instance.Status.SetCondition(condition) {
+		if err := r.Update(context.TODO(), instance); err != nil {
+			instanceLog.Error(err, "Error updating conditions")
+			return reconcile.Result{}, err
+		}
+	}
+	return reconcile.Result{}, nil
+}
+
+// renderedResources renders the resources the last plan of the instance applied, excluding the ones of steps deleting
+// resources and Jobs, which are expected to run to completion and may be cleaned up. The resources are unstructured
+// and contain only the fields set in the templates, so that defaults set by the API server are no drift.
+func (r *ReconcileDrift) renderedResources(instance *kudov1alpha1.Instance) ([]*unstructured.Unstructured, error) {
+	planExecution := &kudov1alpha1.PlanExecution{}
+	err := r.Get(context.TODO(), types.NamespacedName{Name: instance.Status.ActivePlan.Name, Namespace: instance.Status.ActivePlan.Namespace}, planExecution)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := kudo.CheckNamespaceWatched("OperatorVersion", instance.GetOperatorVersionNamespace(), instance.Spec.OperatorVersion.Name); err != nil {
+		return nil, err
+	}
+	operatorVersion := &kudov1alpha1.OperatorVersion{}
+	err = r.Get(context.TODO(), types.NamespacedName{Name: instance.Spec.OperatorVersion.Name, Namespace: instance.GetOperatorVersionNamespace()}, operatorVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := getParameters(instance, operatorVersion)
+	if err != nil {
+		return nil, err
+	}
+	plan, ok := operatorVersion.Spec.Plans[planExecution.Spec.PlanName]
+	if !ok {
+		return nil, fmt.Errorf("could not find required plan (%v)", planExecution.Spec.PlanName)
+	}
+
+	active := &activePlan{
+		Name:      planExecution.Spec.PlanName,
+		Spec:      &plan,
+		State:     &kudov1alpha1.PlanExecutionStatus{},
+		Tasks:     operatorVersion.Spec.Tasks,
+		Templates: operatorVersion.Spec.Templates,
+		params:    params,
+	}
+	// the status of the plan execution is not changed, rendering only needs a status with all phases and steps
+	initializePlanStatus(active.State, active)
+
+	planResources, err := prepareKubeResources(active, &executionMetadata{
+		operatorVersionName: operatorVersion.Name,
+		operatorVersion:     operatorVersion.Spec.Version,
+		resourcesOwner:      instance,
+		operatorName:        operatorVersion.Spec.Operator.Name,
+		instanceNamespace:   instance.Namespace,
+		instanceName:        instance.Name,
+		planExecutionID:     planExecution.Name,
+	}, &kustomizeEnhancer{scheme: r.scheme, unstructured: true}, driftLog.WithValues("instance", instance.Namespace+"/"+instance.Name, "plan", active.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []*unstructured.Unstructured
+	for _, ph := range plan.Phases {
+		for _, st := range ph.Steps {
+			if st.Delete {
+				continue
+			}
+			for _, res := range planResources.PhaseResources[ph.Name].StepResources[st.Name] {
+				if res.GetObjectKind().GroupVersionKind().Kind == "Job" {
+					continue
+				}
+				resources = append(resources, res.(*unstructured.Unstructured))
+			}
+		}
+	}
+	return resources, nil
+}
+
+// drift is a rendered resource which was changed or deleted
+type drift struct {
+	rendered runtime.Object
+	// current is the resource in the cluster, nil if it was deleted
+	current runtime.Object
+	// fields are the paths of the fields which differ from the rendered resource
+	fields []string
+}
+
+type drifts []drift
+
+func (d drifts) descriptions() []string {
+	descriptions := make([]string, 0, len(d))
+	for _, dr := range d {
+		key, _ := client.ObjectKeyFromObject(dr.rendered)
+		kind := dr.rendered.GetObjectKind().GroupVersionKind().Kind
+		if dr.current == nil {
+			descriptions = append(descriptions, fmt.Sprintf("%s %v was deleted", kind, key))
+		} else {
+			descriptions = append(descriptions, fmt.Sprintf("%s %v changed %s", kind, key, strings.Join(dr.fields, ", ")))
+		}
+	}
+	return descriptions
+}
+
+// heal re-applies the rendered resources. The hash of the current resources is dropped, so they are patched even
+// though they were rendered the same way before.
+func (d drifts) heal(c client.Client, instanceLog logr.Logger) error {
+	for _, dr := range d {
+		if dr.current == nil {
+			if err := createObject(dr.rendered, c); err != nil {
+				return err
+			}
+			continue
+		}
+		accessor, err := meta.Accessor(dr.current)
+		if err != nil {
+			return err
+		}
+		annotations := accessor.GetAnnotations()
+		delete(annotations, kudo.HashAnnotation)
+		accessor.SetAnnotations(annotations)
+		key, _ := client.ObjectKeyFromObject(dr.rendered)
+		if err := applyObject(dr.rendered, dr.current, c, instanceLog.WithValues("object", key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// detectDrift returns the resources which differ from the rendered ones in the cluster
+func detectDrift(resources []*unstructured.Unstructured, c client.Client) (drifts, error) {
+	var result drifts
+	for _, u := range resources {
+		r, err := typedObject(u)
+		if err != nil {
+			return nil, err
+		}
+		current := r.DeepCopyObject()
+		key, _ := client.ObjectKeyFromObject(r)
+		err = c.Get(context.TODO(), key, current)
+		if errors.IsNotFound(err) {
+			result = append(result, drift{rendered: r})
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		set, err := setFields(r, u)
+		if err != nil {
+			return nil, err
+		}
+		fields, err := driftedFields(set, current)
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) > 0 {
+			result = append(result, drift{rendered: r, current: current, fields: fields})
+		}
+	}
+	return result, nil
+}
+
+// typedObject decodes a rendered resource into its type the same way a plan does, so that it is applied the same way
+func typedObject(u *unstructured.Unstructured) (runtime.Object, error) {
+	doc, err := apijson.Marshal(u)
+	if err != nil {
+		return nil, err
+	}
+	objs, err := template.ParseKubernetesObjects(string(doc))
+	if err != nil {
+		return nil, err
+	}
+	if len(objs) != 1 {
+		return nil, fmt.Errorf("rendered resource %s/%s decoded into %d objects", u.GetKind(), u.GetName(), len(objs))
+	}
+	return objs[0], nil
+}
+
+// setFields returns the fields of the typed resource which are set in the rendered resource, with the values of the
+// typed resource. A typed resource also contains the zero values of all fields which are not set, these would differ
+// from the defaults set by the API server.
+func setFields(typed runtime.Object, rendered *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	m, err := runtime.DefaultUnstructuredConverter.ToUnstructured(typed)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: intersectFields(m, rendered.Object)}, nil
+}
+
+func intersectFields(typed, set map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for k, s := range set {
+		if t, ok := typed[k]; ok {
+			result[k] = intersectValues(t, s)
+		}
+	}
+	return result
+}
+
+func intersectValues(typed, set interface{}) interface{} {
+	switch s := set.(type) {
+	case map[string]interface{}:
+		if t, ok := typed.(map[string]interface{}); ok {
+			return intersectFields(t, s)
+		}
+	case []interface{}:
+		if t, ok := typed.([]interface{}); ok && len(t) == len(s) {
+			result := make([]interface{}, len(t))
+			for i := range t {
+				result[i] = intersectValues(t[i], s[i])
+			}
+			return result
+		}
+	}
+	return typed
+}
+
+// driftedFields returns the paths of the fields set in the rendered resource which have a different value in the current
+// resource. Fields not set in the rendered resource, e.g. defaults or the status, are ignored.
+func driftedFields(rendered runtime.Object, current runtime.Object) ([]string, error) {
+	var maps [2]map[string]interface{}
+	for i, obj := range []runtime.Object{rendered, current} {
+		doc, err := apijson.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		maps[i] = map[string]interface{}{}
+		if err := apijson.Unmarshal(doc, &maps[i]); err != nil {
+			return nil, err
+		}
+	}
+	delete(maps[0], "status")
+
+	fields := differentFields(maps[0], maps[1], "")
+	sort.Strings(fields)
+	return fields, nil
+}
+
+func differentFields(rendered, current map[string]interface{}, path string) []string {
+	var fields []string
+	for k, r := range rendered {
+		fields = append(fields, differentValues(r, current[k], path+k)...)
+	}
+	return fields
+}
+
+func differentValues(rendered, current interface{}, path string) []string {
+	switch r := rendered.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		c, ok := current.(map[string]interface{})
+		if !ok {
+			if len(r) == 0 {
+				return nil
+			}
+			return []string{path}
+		}
+		return differentFields(r, c, path+".")
+	case []interface{}:
+		c, ok := current.([]interface{})
+		if !ok || len(r) != len(c) {
+			return []string{path}
+		}
+		var fields []string
+		for i := range r {
+			fields = append(fields, differentValues(r[i], c[i], fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return fields
+	default:
+		if !reflect.DeepEqual(rendered, current) {
+			return []string{path}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,275 @@
+package planexecution
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/kudobuilder/kudo/pkg/apis/kudo/v1alpha1"
+	"github.com/kudobuilder/kudo/pkg/util/kudo"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const driftConfigMap = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm\ndata:\n  a: \"1\"\n"
+
+func newDriftReconciler(t *testing.T, mode string, templates map[string]string) (*ReconcileDrift, *record.FakeRecorder) {
+	s := runtime.NewScheme()
+	assert.NoError(t, scheme.AddToScheme(s))
+	resources := make([]string, 0, len(templates))
+	for name := range templates {
+		resources = append(resources, name)
+	}
+	sort.Strings(resources)
+	assert.NoError(t, v1alpha1.AddToScheme(s))
+
+	instance := &v1alpha1.Instance{
+		TypeMeta: metav1.TypeMeta{APIVersion: "kudo.dev/v1alpha1", Kind: "Instance"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "instance",
+			Namespace:   "default",
+			UID:         "1234",
+			Annotations: map[string]string{kudo.DriftDetectionAnnotation: mode},
+		},
+		Spec: v1alpha1.InstanceSpec{OperatorVersion: corev1.ObjectReference{Name: "ov"}},
+		Status: v1alpha1.InstanceStatus{
+			ActivePlan: corev1.ObjectReference{Name: "pe", Namespace: "default"},
+			Status:     v1alpha1.PhaseStateComplete,
+		},
+	}
+	operatorVersion := &v1alpha1.OperatorVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: "ov", Namespace: "default"},
+		Spec: v1alpha1.OperatorVersionSpec{
+			Version:   "1.0",
+			Templates: templates,
+			Tasks:     map[string]v1alpha1.TaskSpec{"task": {Resources: resources}},
+			Plans: map[string]v1alpha1.Plan{"deploy": {
+				Strategy: v1alpha1.Serial,
+				Phases: []v1alpha1.Phase{{
+					Name:     "phase",
+					Strategy: v1alpha1.Serial,
+					Steps:    []v1alpha1.Step{{Name: "step", Tasks: []string{"task"}}},
+				}},
+			}},
+		},
+	}
+	planExecution := &v1alpha1.PlanExecution{
+		ObjectMeta: metav1.ObjectMeta{Name: "pe", Namespace: "default"},
+		Spec:       v1alpha1.PlanExecutionSpec{PlanName: "deploy"},
+		Status:     v1alpha1.PlanExecutionStatus{State: v1alpha1.PhaseStateComplete},
+	}
+
+	recorder := record.NewFakeRecorder(10)
+	return &ReconcileDrift{
+		Client:   fake.NewFakeClientWithScheme(s, instance, operatorVersion, planExecution),
+		scheme:   s,
+		recorder: recorder,
+		impersonate: func(username string) (client.Client, error) {
+			return nil, fmt.Errorf("unexpected impersonation of %s", username)
+		},
+	}, recorder
+}
+
+func reconcileDrift(t *testing.T, r *ReconcileDrift) *v1alpha1.Instance {
+	_, err := r.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "instance", Namespace: "default"}})
+	assert.NoError(t, err)
+
+	instance := &v1alpha1.Instance{}
+	assert.NoError(t, r.Get(context.TODO(), types.NamespacedName{Name: "instance", Namespace: "default"}, instance))
+	return instance
+}
+
+func TestDriftReport(t *testing.T) {
+	r, recorder := newDriftReconciler(t, "report", map[string]string{"cm.yaml": driftConfigMap})
+
+	instance := reconcileDrift(t, r)
+	condition := instance.Status.GetCondition(v1alpha1.InstanceConditionDrifted)
+	if assert.NotNil(t, condition) {
+		assert.Equal(t, corev1.ConditionTrue, condition.Status)
+		assert.Equal(t, "ConfigMap default/instance-cm was deleted", condition.Message)
+	}
+	assert.Contains(t, <-recorder.Events, "Drift")
+
+	// reporting does not re-create the resource
+	err := r.Get(context.TODO(), client.ObjectKey{Name: "instance-cm", Namespace: "default"}, &corev1.ConfigMap{})
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestDriftHeal(t *testing.T) {
+	r, _ := newDriftReconciler(t, "heal", map[string]string{"cm.yaml": driftConfigMap})
+
+	// the deleted resource is re-created
+	instance := reconcileDrift(t, r)
+	condition := instance.Status.GetCondition(v1alpha1.InstanceConditionDrifted)
+	if assert.NotNil(t, condition) {
+		assert.Equal(t, corev1.ConditionFalse, condition.Status)
+		assert.Equal(t, "Healed", condition.Reason)
+	}
+	cm := &corev1.ConfigMap{}
+	assert.NoError(t, r.Get(context.TODO(), client.ObjectKey{Name: "instance-cm", Namespace: "default"}, cm))
+	assert.Equal(t, "1", cm.Data["a"])
+
+	// the changed resource is re-applied
+	cm.Data["a"] = "edited"
+	assert.NoError(t, r.Update(context.TODO(), cm))
+	instance = reconcileDrift(t, r)
+	assert.Contains(t, instance.Status.GetCondition(v1alpha1.InstanceConditionDrifted).Message, "changed data.a")
+	assert.NoError(t, r.Get(context.TODO(), client.ObjectKey{Name: "instance-cm", Namespace: "default"}, cm))
+	assert.Equal(t, "1", cm.Data["a"])
+}
+
+func TestDriftHealImpersonates(t *testing.T) {
+	r, _ := newDriftReconciler(t, "heal", map[string]string{"cm.yaml": driftConfigMap})
+	instance := &v1alpha1.Instance{}
+	assert.NoError(t, r.Get(context.TODO(), types.NamespacedName{Name: "instance", Namespace: "default"}, instance))
+	instance.Annotations[kudo.ServiceAccountAnnotation] = "zk"
+	assert.NoError(t, r.Update(context.TODO(), instance))
+
+	var impersonated string
+	r.impersonate = func(username string) (client.Client, error) {
+		impersonated = username
+		return r.Client, nil
+	}
+
+	instance = reconcileDrift(t, r)
+	assert.Equal(t, "system:serviceaccount:default:zk", impersonated)
+	assert.Equal(t, "Healed", instance.Status.GetCondition(v1alpha1.InstanceConditionDrifted).Reason)
+}
+
+func TestDriftServerDefaults(t *testing.T) {
+	r, recorder := newDriftReconciler(t, "heal", map[string]string{
+		"service.yaml": `apiVersion: v1
+kind: Service
+metadata:
+  name: svc
+spec:
+  ports:
+    - port: 80
+      name: http
+  selector:
+    app: test
+`,
+		"statefulset.yaml": `apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: app
+spec:
+  selector:
+    matchLabels:
+      app: test
+  serviceName: svc
+  replicas: 1
+  template:
+    metadata:
+      labels:
+        app: test
+    spec:
+      containers:
+        - name: app
+          image: nginx
+          ports:
+            - containerPort: 80
+  volumeClaimTemplates:
+    - metadata:
+        name: data
+      spec:
+        accessModes: ["ReadWriteOnce"]
+        resources:
+          requests:
+            storage: 1Gi
+`,
+	})
+
+	// the first reconcile creates the resources
+	reconcileDrift(t, r)
+	if assert.Len(t, recorder.Events, 2) {
+		assert.Contains(t, <-recorder.Events, "Drift")
+		assert.Contains(t, <-recorder.Events, "DriftHealed")
+	}
+
+	// the API server sets defaults of fields which are not set in the templates
+	svc := &corev1.Service{}
+	assert.NoError(t, r.Get(context.TODO(), client.ObjectKey{Name: "instance-svc", Namespace: "default"}, svc))
+	svc.Spec.Ports[0].TargetPort = intstr.FromInt(80)
+	svc.Spec.Ports[0].Protocol = corev1.ProtocolTCP
+	svc.Spec.ClusterIP = "10.0.0.1"
+	svc.Spec.Type = corev1.ServiceTypeClusterIP
+	svc.Spec.SessionAffinity = corev1.ServiceAffinityNone
+	assert.NoError(t, r.Update(context.TODO(), svc))
+
+	sts := &appsv1.StatefulSet{}
+	assert.NoError(t, r.Get(context.TODO(), client.ObjectKey{Name: "instance-app", Namespace: "default"}, sts))
+	revisionHistoryLimit := int32(10)
+	sts.Spec.RevisionHistoryLimit = &revisionHistoryLimit
+	sts.Spec.PodManagementPolicy = appsv1.OrderedReadyPodManagement
+	sts.Spec.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType}
+	sts.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyAlways
+	sts.Spec.Template.Spec.DNSPolicy = corev1.DNSClusterFirst
+	sts.Spec.Template.Spec.Containers[0].Ports[0].Protocol = corev1.ProtocolTCP
+	sts.Spec.Template.Spec.Containers[0].TerminationMessagePath = corev1.TerminationMessagePathDefault
+	sts.Spec.Template.Spec.Containers[0].ImagePullPolicy = corev1.PullAlways
+	volumeMode := corev1.PersistentVolumeFilesystem
+	sts.Spec.VolumeClaimTemplates[0].Spec.VolumeMode = &volumeMode
+	assert.NoError(t, r.Update(context.TODO(), sts))
+
+	// defaults are no drift
+	instance := reconcileDrift(t, r)
+	condition := instance.Status.GetCondition(v1alpha1.InstanceConditionDrifted)
+	if assert.NotNil(t, condition) {
+		assert.Equal(t, corev1.ConditionFalse, condition.Status)
+		assert.Equal(t, "NoDrift", condition.Reason)
+	}
+	assert.Len(t, recorder.Events, 0)
+
+	// changes of fields set in the templates are
+	replicas := int32(3)
+	sts.Spec.Replicas = &replicas
+	assert.NoError(t, r.Update(context.TODO(), sts))
+	instance = reconcileDrift(t, r)
+	assert.Contains(t, instance.Status.GetCondition(v1alpha1.InstanceConditionDrifted).Message, "StatefulSet default/instance-app changed spec.replicas")
+	assert.NoError(t, r.Get(context.TODO(), client.ObjectKey{Name: "instance-app", Namespace: "default"}, sts))
+	assert.Equal(t, int32(1), *sts.Spec.Replicas)
+}
+
+func TestDriftedFields(t *testing.T) {
+	rendered := getConfigMap(map[string]string{"a": "1", "b": "2"})
+	rendered.Labels = map[string]string{"app": "test"}
+
+	tests := []struct {
+		name     string
+		modify   func(cm *corev1.ConfigMap)
+		expected []string
+	}{
+		{"unchanged", func(cm *corev1.ConfigMap) {}, nil},
+		{"fields added by others", func(cm *corev1.ConfigMap) {
+			cm.Labels["other"] = "x"
+			cm.Data["c"] = "3"
+			cm.ResourceVersion = "42"
+		}, nil},
+		{"changed value", func(cm *corev1.ConfigMap) { cm.Data["a"] = "changed" }, []string{"data.a"}},
+		{"removed values", func(cm *corev1.ConfigMap) {
+			cm.Labels = nil
+			delete(cm.Data, "b")
+		}, []string{"data.b", "metadata.labels"}},
+	}
+
+	for _, tt := range tests {
+		current := rendered.DeepCopy()
+		tt.modify(current)
+		fields, err := driftedFields(rendered, current)
+		assert.NoError(t, err, tt.name)
+		assert.Equal(t, tt.expected, fields, tt.name)
+	}
+}
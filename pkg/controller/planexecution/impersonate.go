@@ -0,0 +1,71 @@
+package planexecution
+
+import (
+	"context"
+	"fmt"
+
+	kudov1alpha1 "github.com/kudobuilder/kudo/pkg/apis/kudo/v1alpha1"
+	"github.com/kudobuilder/kudo/pkg/util/kudo"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// impersonator returns a client impersonating a user
+type impersonator func(username string) (client.Client, error)
+
+// newImpersonator returns an impersonator creating clients with the config and scheme of the manager
+func newImpersonator(mgr manager.Manager) impersonator {
+	return func(username string) (client.Client, error) {
+		config := rest.CopyConfig(mgr.GetConfig())
+		config.Impersonate = rest.ImpersonationConfig{UserName: username}
+		return client.New(config, client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	}
+}
+
+// clientFor returns the client applying the resources of the instance. When the instance is annotated with a service
+// account, resources are written impersonating that service account, so it needs the permissions to create, patch and
+// delete them. Resources are still read from the cache of the manager.
+func clientFor(instance *kudov1alpha1.Instance, c client.Client, impersonate impersonator) (client.Client, error) {
+	serviceAccount := instance.Annotations[kudo.ServiceAccountAnnotation]
+	if serviceAccount == "" {
+		return c, nil
+	}
+	writer, err := impersonate(fmt.Sprintf("system:serviceaccount:%s:%s", instance.Namespace, serviceAccount))
+	if err != nil {
+		return nil, err
+	}
+	return &impersonatingClient{Client: c, writer: writer}, nil
+}
+
+// impersonatingClient reads with the embedded client and writes with an impersonating client
+type impersonatingClient struct {
+	client.Client
+	writer client.Client
+}
+
+// Create implements client.Writer
+func (c *impersonatingClient) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	return c.writer.Create(ctx, obj, opts...)
+}
+
+// Update implements client.Writer
+func (c *impersonatingClient) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	return c.writer.Update(ctx, obj, opts...)
+}
+
+// Patch implements client.Writer
+func (c *impersonatingClient) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return c.writer.Patch(ctx, obj, patch, opts...)
+}
+
+// Delete implements client.Writer
+func (c *impersonatingClient) Delete(ctx context.Context, obj runtime.Object, opts ...client.DeleteOption) error {
+	return c.writer.Delete(ctx, obj, opts...)
+}
+
+// DeleteAllOf implements client.Writer
+func (c *impersonatingClient) DeleteAllOf(ctx context.Context, obj runtime.Object, opts ...client.DeleteAllOfOption) error {
+	return c.writer.DeleteAllOf(ctx, obj, opts...)
+}
@@ -0,0 +1,44 @@
+package planexecution
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kudobuilder/kudo/pkg/apis/kudo/v1alpha1"
+	"github.com/kudobuilder/kudo/pkg/util/kudo"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestClientFor(t *testing.T) {
+	reader := fake.NewFakeClientWithScheme(scheme.Scheme)
+	writer := fake.NewFakeClientWithScheme(scheme.Scheme)
+	var impersonated string
+	impersonate := func(username string) (client.Client, error) {
+		impersonated = username
+		return writer, nil
+	}
+
+	instance := &v1alpha1.Instance{ObjectMeta: metav1.ObjectMeta{Name: "instance", Namespace: "default"}}
+	c, err := clientFor(instance, reader, impersonate)
+	assert.NoError(t, err)
+	assert.Equal(t, reader, c)
+	assert.Empty(t, impersonated)
+
+	instance.Annotations = map[string]string{kudo.ServiceAccountAnnotation: "zk"}
+	c, err = clientFor(instance, reader, impersonate)
+	assert.NoError(t, err)
+	assert.Equal(t, "system:serviceaccount:default:zk", impersonated)
+
+	// objects are written by the impersonating client but read by the client of the manager
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default"}}
+	assert.NoError(t, c.Create(context.TODO(), cm))
+	key := client.ObjectKey{Name: "cm", Namespace: "default"}
+	assert.NoError(t, writer.Get(context.TODO(), key, &corev1.ConfigMap{}))
+	assert.True(t, apierrors.IsNotFound(c.Get(context.TODO(), key, &corev1.ConfigMap{})))
+}
@@ -3,22 +3,25 @@ package planexecution
 import (
 	"context"
 	"fmt"
-	"log"
 	"strconv"
 
-	"k8s.io/apimachinery/pkg/types"
-
+	"github.com/go-logr/logr"
 	"github.com/kudobuilder/kudo/pkg/apis/kudo/v1alpha1"
 	kudoengine "github.com/kudobuilder/kudo/pkg/engine"
 	"github.com/kudobuilder/kudo/pkg/util/health"
+	"github.com/kudobuilder/kudo/pkg/util/kudo"
+	"github.com/kudobuilder/kudo/pkg/util/metrics"
 	"github.com/pkg/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	apijson "k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// timers measure the durations of the phases and steps in progress
+var timers = metrics.NewTimers()
+
 type activePlan struct {
 	Name      string
 	State     *v1alpha1.PlanExecutionStatus
@@ -54,9 +57,18 @@ type executionMetadata struct {
 // result of running this function is new state of the execution that is returned to the caller (it can either be completed, or still in progress or errored)
 // in case of error, error is returned along with the state as well (so that it's possible to report which step caused the error)
 // in case of error, method returns both error or fatalError which should indicate unrecoverable error meaning there is no point in retrying that execution
-func executePlan(plan *activePlan, metadata *executionMetadata, c client.Client, renderer kubernetesObjectEnhancer) (*v1alpha1.PlanExecutionStatus, error) {
+// transitions of phases and steps and errors are recorded as events on the owner of the resources
+func executePlan(plan *activePlan, metadata *executionMetadata, c client.Client, renderer kubernetesObjectEnhancer, recorder record.EventRecorder) (*v1alpha1.PlanExecutionStatus, error) {
+	planLog := log.WithValues("instance", metadata.instanceNamespace+"/"+metadata.instanceName, "plan", plan.Name)
+	owner, _ := metadata.resourcesOwner.(runtime.Object)
+	event := func(eventType, reason, messageFmt string, args ...interface{}) {
+		if owner != nil {
+			recorder.Eventf(owner, eventType, reason, messageFmt, args...)
+		}
+	}
+
 	if isFinished(plan.State.State) {
-		log.Printf("PlanExecution: Plan %s for instance %s is completed, nothing to do", plan.Name, metadata.instanceName)
+		planLog.Info("Plan is completed, nothing to do")
 		return plan.State, nil
 	}
 
@@ -64,8 +76,9 @@ func executePlan(plan *activePlan, metadata *executionMetadata, c client.Client,
 	newState := &(*plan.State)
 
 	// render kubernetes resources needed to execute this plan
-	planResources, err := prepareKubeResources(plan, metadata, renderer)
+	planResources, err := prepareKubeResources(plan, metadata, renderer, planLog)
 	if err != nil {
+		event("Warning", "PlanFailed", "Plan %s failed to render: %v", plan.Name, err)
 		newState.State = v1alpha1.PhaseStateError
 		return newState, err
 	}
@@ -74,28 +87,48 @@ func executePlan(plan *activePlan, metadata *executionMetadata, c client.Client,
 	allPhasesCompleted := true
 	for _, ph := range plan.Spec.Phases {
 		currentPhaseState, _ := getPhaseFromStatus(ph.Name, newState)
+		phaseLog := planLog.WithValues("phase", ph.Name)
 		if isFinished(currentPhaseState.State) {
 			// nothing to do
-			log.Printf("PlanExecution: Phase %s on plan %s and instance %s is in state %s, nothing to do", ph.Name, plan.Name, metadata.instanceName, currentPhaseState.State)
+			phaseLog.V(1).Info("Phase is finished, nothing to do", "state", currentPhaseState.State)
 			continue
 		} else if isInProgress(currentPhaseState.State) {
+			if currentPhaseState.State == v1alpha1.PhaseStatePending {
+				event("Normal", "PhaseStarted", "Phase %s of plan %s started", ph.Name, plan.Name)
+			}
 			currentPhaseState.State = v1alpha1.PhaseStateInProgress
-			log.Printf("PlanExecution: Executing phase %s on plan %s and instance %s - it's in progress", ph.Name, plan.Name, metadata.instanceName)
+			phaseLog.Info("Executing phase")
+			phaseKey := metadata.planExecutionID + "/" + ph.Name
+			timers.Start(phaseKey)
 
 			// we're currently executing this phase
 			allStepsHealthy := true
 			for _, st := range ph.Steps {
 				currentStepState, _ := getStepFromStatus(st.Name, currentPhaseState)
 				resources := planResources.PhaseResources[ph.Name].StepResources[st.Name]
-				log.Printf("Resources count: %d", len(resources))
+				stepLog := phaseLog.WithValues("step", st.Name)
 
-				log.Printf("PlanExecution: Executing step %s on plan %s and instance %s - it's in %s state", st.Name, plan.Name, metadata.instanceName, currentStepState.State)
-				err := executeStep(st, currentStepState, resources, c)
+				stepKey := phaseKey + "/" + st.Name
+				if isInProgress(currentStepState.State) {
+					timers.Start(stepKey)
+				}
+				if currentStepState.State == v1alpha1.PhaseStatePending {
+					event("Normal", "StepStarted", "Step %s of phase %s started", st.Name, ph.Name)
+				}
+
+				stepLog.Info("Executing step", "state", currentStepState.State, "resources", len(resources))
+				wasFinished := isFinished(currentStepState.State)
+				err := executeStep(st, currentStepState, resources, c, stepLog)
 				if err != nil {
+					event("Warning", "StepFailed", "Step %s of phase %s failed: %v", st.Name, ph.Name, err)
 					currentPhaseState.State = v1alpha1.PhaseStateError
 					currentStepState.State = v1alpha1.PhaseStateError
 					return newState, err
 				}
+				if !wasFinished && isFinished(currentStepState.State) {
+					event("Normal", "StepCompleted", "Step %s of phase %s completed", st.Name, ph.Name)
+					timers.Stop(stepKey, metrics.StepDuration.WithLabelValues(metadata.operatorName, plan.Name, ph.Name, st.Name))
+				}
 
 				if !isFinished(currentStepState.State) {
 					allStepsHealthy = false
@@ -107,8 +140,10 @@ func executePlan(plan *activePlan, metadata *executionMetadata, c client.Client,
 			}
 
 			if allStepsHealthy {
-				log.Printf("PlanExecution: All steps on phase %s plan %s and instance %s are healthy", ph.Name, plan.Name, metadata.instanceName)
+				phaseLog.Info("All steps of phase are healthy")
 				currentPhaseState.State = v1alpha1.PhaseStateComplete
+				event("Normal", "PhaseCompleted", "Phase %s of plan %s completed", ph.Name, plan.Name)
+				timers.Stop(phaseKey, metrics.PhaseDuration.WithLabelValues(metadata.operatorName, plan.Name, ph.Name))
 			}
 		}
 
@@ -120,55 +155,64 @@ func executePlan(plan *activePlan, metadata *executionMetadata, c client.Client,
 	}
 
 	if allPhasesCompleted {
-		log.Printf("PlanExecution: All phases on plan %s and instance %s are healthy", plan.Name, metadata.instanceName)
+		planLog.Info("All phases of plan are healthy")
+		if kudo.PrunedPlans[plan.Name] {
+			if err := pruneResources(planResources, metadata, c, planLog); err != nil {
+				planLog.Error(err, "Error when pruning resources")
+				event("Warning", "PruneFailed", "Pruning resources of prior operator versions failed: %v", err)
+				return newState, err
+			}
+		}
 		newState.State = v1alpha1.PhaseStateComplete
+		event("Normal", "PlanCompleted", "Plan %s completed", plan.Name)
 	}
 
 	return newState, nil
 }
 
-func executeStep(step v1alpha1.Step, state *v1alpha1.StepStatus, resources []runtime.Object, c client.Client) error {
+func executeStep(step v1alpha1.Step, state *v1alpha1.StepStatus, resources []runtime.Object, c client.Client, stepLog logr.Logger) error {
 	if isInProgress(state.State) {
 		state.State = v1alpha1.PhaseStateInProgress
 
 		// check if step is already healthy
 		allHealthy := true
 		for _, r := range resources {
+			key, _ := client.ObjectKeyFromObject(r)
+			objLog := stepLog.WithValues("object", key, "kind", r.GetObjectKind().GroupVersionKind().Kind)
 			if step.Delete {
 				// delete
-				log.Printf("PlanExecution: Step %s will delete object %v", step.Name, r)
+				objLog.Info("Deleting object")
 				err := c.Delete(context.TODO(), r, client.PropagationPolicy(metav1.DeletePropagationForeground))
 				if !apierrors.IsNotFound(err) && err != nil {
-					return err
+					return errors.Wrapf(err, "error deleting %s %s", r.GetObjectKind().GroupVersionKind().Kind, key)
 				}
 			} else {
 				// create or update
-				log.Printf("Going to create/update %v", r)
+				objLog.V(1).Info("Creating or updating object")
 				existingResource := r.DeepCopyObject()
-				key, _ := client.ObjectKeyFromObject(r)
 				err := c.Get(context.TODO(), key, existingResource)
 				if apierrors.IsNotFound(err) {
 					// create
-					err = c.Create(context.TODO(), r)
+					err = createObject(r, c)
 					if err != nil {
-						log.Printf("PlanExecution: error when creating resource in step %v: %v", step.Name, err)
-						return err
+						objLog.Error(err, "Error when creating object")
+						return errors.Wrapf(err, "error creating %s %s", r.GetObjectKind().GroupVersionKind().Kind, key)
 					}
 				} else if err != nil {
 					// other than not found error - raise it
-					return err
+					return errors.Wrapf(err, "error getting %s %s", r.GetObjectKind().GroupVersionKind().Kind, key)
 				} else {
 					// update
-					err := patchExistingObject(r, existingResource, c)
+					err := applyObject(r, existingResource, c, objLog)
 					if err != nil {
-						return err
+						return errors.Wrapf(err, "error updating %s %s", r.GetObjectKind().GroupVersionKind().Kind, key)
 					}
 				}
 
 				err = health.IsHealthy(c, r)
 				if err != nil {
 					allHealthy = false
-					log.Printf("PlanExecution: Obj is NOT healthy: %s", prettyPrint(key))
+					objLog.Info("Object is not healthy", "reason", err.Error())
 				}
 			}
 		}
@@ -180,47 +224,9 @@ func executeStep(step v1alpha1.Step, state *v1alpha1.StepStatus, resources []run
 	return nil
 }
 
-// patchExistingObject calls update method on kubernetes client to make sure the current resource reflects what is on server
-//
-// an obvious optimization here would be to not patch when objects are the same, however that is not easy
-// kubernetes native objects might be a problem because we cannot just compare the spec as the spec might have extra fields
-// and those extra fields are set by some kubernetes component
-// because of that for now we just try to apply the patch every time
-func patchExistingObject(newResource runtime.Object, existingResource runtime.Object, c client.Client) error {
-	newResourceJSON, _ := apijson.Marshal(newResource)
-	key, _ := client.ObjectKeyFromObject(newResource)
-	err := c.Patch(context.TODO(), existingResource, client.ConstantPatch(types.StrategicMergePatchType, newResourceJSON))
-	if err != nil {
-		// Right now applying a Strategic Merge Patch to custom resources does not work. There is
-		// certain metadata needed, which when missing, leads to an invalid Content-Type Header and
-		// causes the request to fail.
-		// ( see https://github.com/kubernetes-sigs/kustomize/issues/742#issuecomment-458650435 )
-		//
-		// We temporarily solve this by checking for the specific error when a SMP is applied to
-		// custom resources and handle it by defaulting to a Merge Patch.
-		//
-		// The error message for which we check is:
-		// 		the body of the request was in an unknown format - accepted media types include:
-		//			application/json-patch+json, application/merge-patch+json
-		//
-		// 		Reason: "UnsupportedMediaType" Code: 415
-		if apierrors.IsUnsupportedMediaType(err) {
-			err = c.Patch(context.TODO(), newResource, client.ConstantPatch(types.MergePatchType, newResourceJSON))
-			if err != nil {
-				log.Printf("PlanExecution: Error when applying merge patch to object %v: %v", key, err)
-				return err
-			}
-		} else {
-			log.Printf("PlanExecution: Error when applying StrategicMergePatch to object %v: %v", key, err)
-			return err
-		}
-	}
-	return nil
-}
-
 // prepareKubeResources takes all resources in all tasks for a plan and renders them with the right parameters
 // it also takes care of applying KUDO specific conventions to the resources like commond labels
-func prepareKubeResources(plan *activePlan, meta *executionMetadata, renderer kubernetesObjectEnhancer) (*planResources, error) {
+func prepareKubeResources(plan *activePlan, meta *executionMetadata, renderer kubernetesObjectEnhancer, planLog logr.Logger) (*planResources, error) {
 	configs := make(map[string]interface{})
 	configs["OperatorName"] = meta.operatorName
 	configs["Name"] = meta.instanceName
@@ -258,7 +264,8 @@ func prepareKubeResources(plan *activePlan, meta *executionMetadata, renderer ku
 								stepState.State = v1alpha1.PhaseStateError
 
 								err := errors.Wrapf(err, "error expanding template")
-								log.Print(err)
+								metrics.RenderErrors.WithLabelValues(meta.operatorName, "template").Inc()
+								planLog.Error(err, "Error rendering template", "phase", phase.Name, "step", step.Name, "template", res)
 								return nil, fatalError{err: err}
 							}
 							resourcesAsString[res] = templatedYaml
@@ -267,7 +274,8 @@ func prepareKubeResources(plan *activePlan, meta *executionMetadata, renderer ku
 							stepState.State = v1alpha1.PhaseStateError
 
 							err := fmt.Errorf("PlanExecution: Error finding resource named %v for operator version %v", res, meta.operatorVersionName)
-							log.Print(err)
+							metrics.RenderErrors.WithLabelValues(meta.operatorName, "template").Inc()
+							planLog.Error(err, "Error finding template", "phase", phase.Name, "step", step.Name)
 							return nil, fatalError{err: err}
 						}
 					}
@@ -287,7 +295,8 @@ func prepareKubeResources(plan *activePlan, meta *executionMetadata, renderer ku
 						phaseState.State = v1alpha1.PhaseStateError
 						stepState.State = v1alpha1.PhaseStateError
 
-						log.Printf("Error creating Kubernetes objects from step %v in phase %v of plan %v: %v", step.Name, phase.Name, meta.planExecutionID, err)
+						metrics.RenderErrors.WithLabelValues(meta.operatorName, "kustomize").Inc()
+						planLog.Error(err, "Error creating Kubernetes objects", "phase", phase.Name, "step", step.Name)
 						return nil, err
 					}
 					resources = append(resources, resourcesWithConventions...)
@@ -296,7 +305,7 @@ func prepareKubeResources(plan *activePlan, meta *executionMetadata, renderer ku
 					stepState.State = v1alpha1.PhaseStateError
 
 					err := fmt.Errorf("Error finding task named %s for operator version %s", taskSpec, meta.operatorVersionName)
-					log.Print(err)
+					planLog.Error(err, "Error finding task", "phase", phase.Name, "step", step.Name)
 					return nil, fatalError{err: err}
 				}
 			}
@@ -15,6 +15,7 @@ import (
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -33,6 +34,7 @@ func TestExecutePlan(t *testing.T) {
 		activePlan     *activePlan
 		metadata       *executionMetadata
 		expectedStatus *v1alpha1.PlanExecutionStatus
+		expectedEvents []string
 	}{
 		{"plan already finished", &activePlan{
 			Name: "test",
@@ -41,7 +43,7 @@ func TestExecutePlan(t *testing.T) {
 			},
 		}, defaultMetadata, &v1alpha1.PlanExecutionStatus{
 			State: v1alpha1.PhaseStateComplete,
-		}},
+		}, nil},
 		{"plan with one step to be executed, still in progress", &activePlan{
 			Name: "test",
 			State: &v1alpha1.PlanExecutionStatus{
@@ -63,6 +65,9 @@ func TestExecutePlan(t *testing.T) {
 			Name:     "test",
 			Strategy: "serial",
 			Phases:   []v1alpha1.PhaseStatus{{Strategy: "serial", Name: "phase", State: v1alpha1.PhaseStateInProgress, Steps: []v1alpha1.StepStatus{{State: v1alpha1.PhaseStateInProgress, Name: "step"}}}},
+		}, []string{
+			"Normal PhaseStarted Phase phase of plan test started",
+			"Normal StepStarted Step step of phase phase started",
 		}},
 		// this plan deploys pod, that is marked as healthy immediately because we cannot evaluate health
 		{"plan with one step, immediately healthy -> completed", &activePlan{
@@ -86,6 +91,12 @@ func TestExecutePlan(t *testing.T) {
 			Name:     "test",
 			Strategy: "serial",
 			Phases:   []v1alpha1.PhaseStatus{{Strategy: "serial", Name: "phase", State: v1alpha1.PhaseStateComplete, Steps: []v1alpha1.StepStatus{{State: v1alpha1.PhaseStateComplete, Name: "step"}}}},
+		}, []string{
+			"Normal PhaseStarted Phase phase of plan test started",
+			"Normal StepStarted Step step of phase phase started",
+			"Normal StepCompleted Step step of phase phase completed",
+			"Normal PhaseCompleted Phase phase of plan test completed",
+			"Normal PlanCompleted Plan test completed",
 		}},
 		{"plan in errored state will be retried and completed when no error happens", &activePlan{
 			Name: "test",
@@ -108,12 +119,17 @@ func TestExecutePlan(t *testing.T) {
 			Name:     "test",
 			Strategy: "serial",
 			Phases:   []v1alpha1.PhaseStatus{{Strategy: "serial", Name: "phase", State: v1alpha1.PhaseStateComplete, Steps: []v1alpha1.StepStatus{{State: v1alpha1.PhaseStateComplete, Name: "step"}}}},
+		}, []string{
+			"Normal StepCompleted Step step of phase phase completed",
+			"Normal PhaseCompleted Phase phase of plan test completed",
+			"Normal PlanCompleted Plan test completed",
 		}},
 	}
 
 	for _, tt := range tests {
 		testClient := fake.NewFakeClientWithScheme(scheme.Scheme)
-		newStatus, err := executePlan(tt.activePlan, tt.metadata, testClient, &testKubernetesObjectEnhancer{})
+		recorder := record.NewFakeRecorder(10)
+		newStatus, err := executePlan(tt.activePlan, tt.metadata, testClient, &testKubernetesObjectEnhancer{}, recorder)
 
 		if err != nil {
 			t.Errorf("%s: Expecting no error but got error %v", tt.name, err)
@@ -122,6 +138,15 @@ func TestExecutePlan(t *testing.T) {
 		if !reflect.DeepEqual(tt.expectedStatus, newStatus) {
 			t.Errorf("%s: Expecting status to be %v but got %v", tt.name, *tt.expectedStatus, *newStatus)
 		}
+
+		close(recorder.Events)
+		var events []string
+		for e := range recorder.Events {
+			events = append(events, e)
+		}
+		if !reflect.DeepEqual(tt.expectedEvents, events) {
+			t.Errorf("%s: Expecting events to be %v but got %v", tt.name, tt.expectedEvents, events)
+		}
 	}
 }
 
@@ -17,12 +17,12 @@ package planexecution
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"log"
 	"strings"
+	"time"
 
 	"github.com/kudobuilder/kudo/pkg/util/kudo"
+	"github.com/kudobuilder/kudo/pkg/util/metrics"
 
 	kudov1alpha1 "github.com/kudobuilder/kudo/pkg/apis/kudo/v1alpha1"
 	appsv1 "k8s.io/api/apps/v1"
@@ -35,23 +35,26 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
+var log = logf.Log.WithName("planexecution-controller")
+
 // Add creates a new PlanExecution Controller and adds it to the Manager with default RBAC.
 // The Manager will set fields on the Controller and Start it when the Manager is Started.
 func Add(mgr manager.Manager) error {
-	log.Printf("PlanExecutionController: Registering planexecution controller.")
+	log.Info("Registering controller")
 
 	return add(mgr, newReconciler(mgr))
 }
 
 // newReconciler returns a new reconcile.Reconciler
 func newReconciler(mgr manager.Manager) reconcile.Reconciler {
-	return &ReconcilePlanExecution{Client: mgr.GetClient(), scheme: mgr.GetScheme(), recorder: mgr.GetEventRecorderFor("planexecution-controller")}
+	return &ReconcilePlanExecution{Client: mgr.GetClient(), scheme: mgr.GetScheme(), recorder: mgr.GetEventRecorderFor("planexecution-controller"), impersonate: newImpersonator(mgr)}
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
@@ -81,9 +84,9 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 				}, inst)
 
 				if err != nil {
-					log.Printf("PlanExecutionController: Error getting instance object: %v", err)
+					log.Error(err, "Error getting owning instance", "instance", a.Meta.GetNamespace()+"/"+owner.Name)
 				} else {
-					log.Printf("PlanExecutionController: Adding \"%v\" to reconcile", inst.Status.ActivePlan.Name)
+					log.V(1).Info("Adding active plan of owning instance to reconcile", "instance", inst.Namespace+"/"+inst.Name, "planExecution", inst.Status.ActivePlan.Name)
 					requests = append(requests, reconcile.Request{
 						NamespacedName: types.NamespacedName{
 							Name:      inst.Status.ActivePlan.Name,
@@ -101,16 +104,16 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	// PlanExecutions should be mostly immutable.
 	p := predicate.Funcs{
 		UpdateFunc: func(e event.UpdateEvent) bool {
-			log.Printf("PlanExecutionController: Received update event for an instance named: %v", e.MetaNew.GetName())
+			log.V(1).Info("Received update event", "object", e.MetaNew.GetNamespace()+"/"+e.MetaNew.GetName())
 			return e.ObjectOld != e.ObjectNew
 		},
 		CreateFunc: func(e event.CreateEvent) bool {
-			log.Printf("PlanExecutionController: Received create event for an instance named: %v", e.Meta.GetName())
+			log.V(1).Info("Received create event", "object", e.Meta.GetNamespace()+"/"+e.Meta.GetName())
 			return true
 		},
 		DeleteFunc: func(e event.DeleteEvent) bool {
 			// TODO: send event for Instance that plan was deleted
-			log.Printf("PlanExecutionController: Received delete event for an instance named: %v", e.Meta.GetName())
+			log.V(1).Info("Received delete event", "object", e.Meta.GetNamespace()+"/"+e.Meta.GetName())
 			return true
 		},
 	}
@@ -176,7 +179,7 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 							},
 						})
 					} else {
-						log.Printf("PlanExecutionController: received event from Instance %s/%s but instance of that name does not exist", a.Meta.GetNamespace(), a.Meta.GetName())
+						log.Info("Received event from instance which does not exist", "instance", a.Meta.GetNamespace()+"/"+a.Meta.GetName())
 					}
 
 					return requests
@@ -195,8 +198,9 @@ var _ reconcile.Reconciler = &ReconcilePlanExecution{}
 // ReconcilePlanExecution reconciles a PlanExecution object
 type ReconcilePlanExecution struct {
 	client.Client
-	scheme   *runtime.Scheme
-	recorder record.EventRecorder
+	scheme      *runtime.Scheme
+	recorder    record.EventRecorder
+	impersonate impersonator
 }
 
 // Reconcile reads that state of the cluster for a PlanExecution object and makes changes based on the state read
@@ -210,11 +214,13 @@ type ReconcilePlanExecution struct {
 // +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets;poddisruptionbudgets.policy,verbs=get;list;watch;create;update;patch;delete
 func (r *ReconcilePlanExecution) Reconcile(request reconcile.Request) (reconcile.Result, error) {
 	// Fetch the PlanExecution instance
+	reqLog := log.WithValues("planExecution", request.NamespacedName)
 	planExecution := &kudov1alpha1.PlanExecution{}
 	err := r.Get(context.TODO(), request.NamespacedName, planExecution)
 	if err != nil {
 		if errors.IsNotFound(err) {
-			log.Printf("PlanExecutionController: Error finding planexecution \"%v\": %v", request.Name, err)
+			reqLog.Info("Plan execution not found")
+			clearTimers(request.Name)
 			// Object not found, return.  Created objects are automatically garbage collected.
 			// For additional cleanup logic use finalizers.
 			return reconcile.Result{}, nil
@@ -234,10 +240,7 @@ func (r *ReconcilePlanExecution) Reconcile(request reconcile.Request) (reconcile
 		// Can't find the instance.
 		r.recorder.Event(planExecution, "Warning", "InvalidInstance", fmt.Sprintf("Could not find required instance (%v)", planExecution.Spec.Instance.Name))
 		planExecution.Status.State = kudov1alpha1.PhaseStateError
-		log.Printf("PlanExecutionController: Error getting Instance %v in %v: %v",
-			planExecution.Spec.Instance.Name,
-			planExecution.Spec.Instance.Namespace,
-			err)
+		reqLog.Error(err, "Error getting instance", "instance", planExecution.Spec.Instance.Namespace+"/"+planExecution.Spec.Instance.Name)
 
 		if errors.IsNotFound(err) {
 			return reconcile.Result{}, nil
@@ -246,10 +249,12 @@ func (r *ReconcilePlanExecution) Reconcile(request reconcile.Request) (reconcile
 		return reconcile.Result{}, err
 	}
 
+	reqLog = reqLog.WithValues("instance", instance.Namespace+"/"+instance.Name, "plan", planExecution.Spec.PlanName)
+
 	if instance.Status.ActivePlan.Name != planExecution.Name || instance.Status.ActivePlan.Namespace != planExecution.Namespace {
 		// this can happen for newly created PlanExecution where ActivePlan was not yet set to point to this instance
 		// this will get retried thanks to a watch set up for instance updates
-		log.Printf("instance %s does not have ActivePlan pointing to PlanExecution %s, %s. Instead %s, %s", instance.Name, planExecution.Name, planExecution.Namespace, instance.Status.ActivePlan.Name, instance.Status.ActivePlan.Namespace)
+		reqLog.Info("Active plan of instance does not point to the plan execution", "activePlan", instance.Status.ActivePlan.Namespace+"/"+instance.Status.ActivePlan.Name)
 		return reconcile.Result{}, nil
 	}
 
@@ -263,12 +268,19 @@ func (r *ReconcilePlanExecution) Reconcile(request reconcile.Request) (reconcile
 
 	// See if this has already been processed
 	if planExecution.Status.State == kudov1alpha1.PhaseStateComplete {
-		log.Printf("PlanExecutionController: PlanExecution \"%v\" has already run to completion, not processing.", planExecution.Name)
+		reqLog.V(1).Info("Plan execution has already run to completion, not processing")
 		return reconcile.Result{}, nil
 	}
 
 	// Get associated OperatorVersion
 	operatorVersion := &kudov1alpha1.OperatorVersion{}
+	if err := kudo.CheckNamespaceWatched("OperatorVersion", instance.GetOperatorVersionNamespace(), instance.Spec.OperatorVersion.Name); err != nil {
+		// The OperatorVersion can never be read from the cache, retrying does not help.
+		planExecution.Status.State = kudov1alpha1.PhaseStateError
+		r.recorder.Event(planExecution, "Warning", "InvalidOperatorVersion", err.Error())
+		reqLog.Error(err, "Error getting operator version")
+		return reconcile.Result{}, nil
+	}
 	err = r.Get(context.TODO(),
 		types.NamespacedName{
 			Name:      instance.Spec.OperatorVersion.Name,
@@ -279,16 +291,13 @@ func (r *ReconcilePlanExecution) Reconcile(request reconcile.Request) (reconcile
 		// Can't find the OperatorVersion.
 		planExecution.Status.State = kudov1alpha1.PhaseStateError
 		r.recorder.Event(planExecution, "Warning", "InvalidOperatorVersion", fmt.Sprintf("Could not find OperatorVersion %v", instance.Spec.OperatorVersion.Name))
-		log.Printf("PlanExecutionController: Error getting OperatorVersion %v in %v: %v",
-			instance.Spec.OperatorVersion.Name,
-			instance.GetOperatorVersionNamespace(),
-			err)
+		reqLog.Error(err, "Error getting operator version", "operatorVersion", instance.GetOperatorVersionNamespace()+"/"+instance.Spec.OperatorVersion.Name)
 		return reconcile.Result{}, err
 	}
 
 	params, err := getParameters(instance, operatorVersion)
 	if err != nil {
-		log.Printf("PlanExecutionController: %v", err)
+		reqLog.Error(err, "Error getting parameters")
 		r.recorder.Event(planExecution, "Warning", "MissingParameter", err.Error())
 		return reconcile.Result{}, nil // do not retry this error
 	}
@@ -302,6 +311,7 @@ func (r *ReconcilePlanExecution) Reconcile(request reconcile.Request) (reconcile
 	}
 
 	planExecution = planExecution.DeepCopy()
+	previousState := planExecution.Status.State
 	activePlan := &activePlan{
 		Name:      planExecution.Spec.PlanName,
 		Spec:      &executedPlan,
@@ -312,7 +322,13 @@ func (r *ReconcilePlanExecution) Reconcile(request reconcile.Request) (reconcile
 	}
 	initializePlanStatus(&planExecution.Status, activePlan)
 
-	log.Printf("PlanExecutionController: Going to execute plan %s for instance %s", planExecution.Name, instance.Name)
+	c, err := clientFor(instance, r.Client, r.impersonate)
+	if err != nil {
+		reqLog.Error(err, "Error creating client impersonating the service account of the instance")
+		return reconcile.Result{}, err
+	}
+
+	reqLog.Info("Going to execute plan")
 	newState, err := executePlan(activePlan, &executionMetadata{
 		operatorVersionName: operatorVersion.Name,
 		operatorVersion:     operatorVersion.Spec.Version,
@@ -321,19 +337,21 @@ func (r *ReconcilePlanExecution) Reconcile(request reconcile.Request) (reconcile
 		instanceNamespace:   instance.Namespace,
 		instanceName:        instance.Name,
 		planExecutionID:     planExecution.Name,
-	}, r.Client, &kustomizeEnhancer{r.scheme})
+	}, c, &kustomizeEnhancer{scheme: r.scheme}, r.recorder)
 	if newState != nil {
 		planExecution.Status = *newState
 	}
 
 	if err != nil {
-		log.Printf("PlanExecutionController: error when executing plan for instance %s: %v", instance.Name, err)
+		reqLog.Error(err, "Error when executing plan")
 
 		err = r.Client.Update(context.TODO(), planExecution)
 		if err != nil {
-			log.Printf("PlanExecutionController: Error when updating planExecution state. %v", err)
+			reqLog.Error(err, "Error when updating plan execution state")
 			return reconcile.Result{}, err
 		}
+		observePlanResult(planExecution, previousState, operatorVersion.Spec.Operator.Name)
+		clearTimers(planExecution.Name)
 
 		if _, ok := err.(*fatalError); ok {
 			// do not retry
@@ -344,22 +362,49 @@ func (r *ReconcilePlanExecution) Reconcile(request reconcile.Request) (reconcile
 
 	err = r.Client.Update(context.TODO(), planExecution)
 	if err != nil {
-		log.Printf("PlanExecutionController: Error when updating planExecution state. %v", err)
+		reqLog.Error(err, "Error when updating plan execution state")
 		return reconcile.Result{}, err
 	}
 
+	observePlanResult(planExecution, previousState, operatorVersion.Spec.Operator.Name)
+	if state := planExecution.Status.State; state == kudov1alpha1.PhaseStateComplete || state == kudov1alpha1.PhaseStateError {
+		clearTimers(planExecution.Name)
+	}
+
 	// update instance state
 	// TODO this should not be done in this controller, we should address it in another iteration of refactoring
 	instance.Status.Status = planExecution.Status.State
 	err = r.Client.Update(context.TODO(), instance)
 	if err != nil {
-		log.Printf("Error updating instance status to %v: %v\n", instance.Status.Status, err)
+		reqLog.Error(err, "Error updating instance status", "status", instance.Status.Status)
 		return reconcile.Result{}, err
 	}
 
 	return reconcile.Result{}, nil
 }
 
+// observePlanResult counts a plan execution once, when its state first changes to ERROR or COMPLETE. Reconciling a
+// plan execution which already failed or completed does not count it again.
+func observePlanResult(planExecution *kudov1alpha1.PlanExecution, previousState kudov1alpha1.PhaseState, operatorName string) {
+	state := planExecution.Status.State
+	if state == previousState {
+		return
+	}
+	switch state {
+	case kudov1alpha1.PhaseStateError:
+		metrics.PlanExecutions.WithLabelValues(operatorName, planExecution.Spec.PlanName, "error").Inc()
+	case kudov1alpha1.PhaseStateComplete:
+		metrics.PlanExecutions.WithLabelValues(operatorName, planExecution.Spec.PlanName, "complete").Inc()
+		metrics.PlanDuration.WithLabelValues(operatorName, planExecution.Spec.PlanName).Observe(time.Since(planExecution.CreationTimestamp.Time).Seconds())
+	}
+}
+
+// clearTimers removes the timers of the phases and steps of a plan execution which failed, completed or was deleted.
+// A failed plan execution is retried, its phases and steps are timed again from the retry.
+func clearTimers(planExecutionID string) {
+	timers.Clear(planExecutionID + "/")
+}
+
 // initializePlanStatus constructs the current plan execution summary by consulting current state of PE CRD and selected plan from OV
 func initializePlanStatus(status *kudov1alpha1.PlanExecutionStatus, plan *activePlan) {
 	if plan.Name == status.Name && status.State != kudov1alpha1.PhaseStateComplete {
@@ -437,14 +482,14 @@ func (r *ReconcilePlanExecution) Cleanup(obj runtime.Object) error {
 	switch obj := obj.(type) {
 	case *batchv1.Job:
 		// We need to see if there's a current job on the system that matches this exactly (with labels)
-		log.Printf("PlanExecutionController.Cleanup: *batchv1.Job %v", obj.Name)
-
 		present := &batchv1.Job{}
 		key, _ := client.ObjectKeyFromObject(obj)
+		jobLog := log.WithValues("job", key)
+		jobLog.V(1).Info("Cleaning up job")
 		err := r.Get(context.TODO(), key, present)
 		if errors.IsNotFound(err) {
 			// This is fine, its good to go
-			log.Printf("PlanExecutionController: Could not find job \"%v\" in cluster. Good to make a new one.", key)
+			jobLog.V(1).Info("Could not find job in cluster, good to make a new one")
 			return nil
 		}
 		if err != nil {
@@ -455,7 +500,7 @@ func (r *ReconcilePlanExecution) Cleanup(obj runtime.Object) error {
 		for k, v := range obj.Labels {
 			if v != present.Labels[k] {
 				// Need to delete the present job since its got labels that aren't the same
-				log.Printf("PlanExecutionController: Different values for job key \"%v\": \"%v\" and \"%v\"", k, v, present.Labels[k])
+				jobLog.Info("Deleting job with different label value", "label", k, "value", present.Labels[k], "expected", v)
 				err = r.Delete(context.TODO(), present)
 				return err
 			}
@@ -463,7 +508,7 @@ func (r *ReconcilePlanExecution) Cleanup(obj runtime.Object) error {
 		for k, v := range present.Labels {
 			if v != obj.Labels[k] {
 				// Need to delete the present job since its got labels that aren't the same
-				log.Printf("PlanExecutionController: Different values for job key \"%v\": \"%v\" and \"%v\"", k, v, obj.Labels[k])
+				jobLog.Info("Deleting job with different label value", "label", k, "value", v, "expected", obj.Labels[k])
 				err = r.Delete(context.TODO(), present)
 				return err
 			}
@@ -473,8 +518,3 @@ func (r *ReconcilePlanExecution) Cleanup(obj runtime.Object) error {
 
 	return nil
 }
-
-func prettyPrint(i interface{}) string {
-	s, _ := json.MarshalIndent(i, "", "  ")
-	return string(s)
-}
@@ -0,0 +1,38 @@
+package planexecution
+
+import (
+	"testing"
+
+	"github.com/kudobuilder/kudo/pkg/apis/kudo/v1alpha1"
+	"github.com/kudobuilder/kudo/pkg/util/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObservePlanResult(t *testing.T) {
+	planExecution := &v1alpha1.PlanExecution{Spec: v1alpha1.PlanExecutionSpec{PlanName: "observed"}}
+	count := func(result string) float64 {
+		return testutil.ToFloat64(metrics.PlanExecutions.WithLabelValues("operator", "observed", result))
+	}
+
+	transitions := []struct {
+		previous v1alpha1.PhaseState
+		state    v1alpha1.PhaseState
+		errors   float64
+		complete float64
+	}{
+		{v1alpha1.PhaseStatePending, v1alpha1.PhaseStateInProgress, 0, 0},
+		{v1alpha1.PhaseStateInProgress, v1alpha1.PhaseStateError, 1, 0},
+		// failed reconciles of a plan execution which is already in ERROR are not counted again
+		{v1alpha1.PhaseStateError, v1alpha1.PhaseStateError, 1, 0},
+		{v1alpha1.PhaseStateError, v1alpha1.PhaseStateComplete, 1, 1},
+		{v1alpha1.PhaseStateComplete, v1alpha1.PhaseStateComplete, 1, 1},
+	}
+
+	for _, tt := range transitions {
+		planExecution.Status.State = tt.state
+		observePlanResult(planExecution, tt.previous, "operator")
+		assert.Equal(t, tt.errors, count("error"), "%s -> %s", tt.previous, tt.state)
+		assert.Equal(t, tt.complete, count("complete"), "%s -> %s", tt.previous, tt.state)
+	}
+}
@@ -0,0 +1,99 @@
+package planexecution
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/kudobuilder/kudo/pkg/util/kudo"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// pruneResources deletes the objects owned by the instance which were created by a prior operator version and were not
+// rendered by the plan anymore, e.g. a ConfigMap removed from the templates of a new version.
+// Objects are identified by the instance label and the operator version annotation set when applying the conventions,
+// objects annotated with kudo.dev/prune: "false" are kept. When impersonating the service account of the instance, only
+// the kinds rendered by the plan are pruned, the service account is not expected to delete any other kinds.
+func pruneResources(resources *planResources, metadata *executionMetadata, c client.Client, planLog logr.Logger) error {
+	rendered := map[string]bool{}
+	kinds := map[schema.GroupVersionKind]bool{}
+	if _, impersonating := c.(*impersonatingClient); !impersonating {
+		for _, gvk := range kudo.PruneKinds {
+			kinds[gvk] = true
+		}
+	}
+	for _, phase := range resources.PhaseResources {
+		for _, step := range phase.StepResources {
+			for _, r := range step {
+				gvk := r.GetObjectKind().GroupVersionKind()
+				key, _ := client.ObjectKeyFromObject(r)
+				rendered[gvk.GroupKind().String()+"/"+key.String()] = true
+				kinds[gvk] = true
+			}
+		}
+	}
+
+	for gvk := range kinds {
+		list := newList(gvk)
+		err := c.List(context.TODO(), list, client.InNamespace(metadata.instanceNamespace), client.MatchingLabels{kudo.InstanceLabel: metadata.instanceName})
+		if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+			// the kind is not served by this cluster
+			continue
+		} else if err != nil {
+			return err
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			obj, err := meta.Accessor(item)
+			if err != nil {
+				return err
+			}
+			key := client.ObjectKey{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+			if !isPrunable(obj, metadata) || rendered[gvk.GroupKind().String()+"/"+key.String()] {
+				continue
+			}
+
+			planLog.Info("Pruning object of prior operator version", "object", key, "kind", gvk.Kind, "operatorVersion", obj.GetAnnotations()[kudo.OperatorVersionAnnotation])
+			err = c.Delete(context.TODO(), item, client.PropagationPolicy(metav1.DeletePropagationForeground))
+			if err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// newList returns an empty list of the kind, typed for the built-in kinds
+func newList(gvk schema.GroupVersionKind) runtime.Object {
+	listGVK := gvk.GroupVersion().WithKind(gvk.Kind + "List")
+	if list, err := scheme.Scheme.New(listGVK); err == nil {
+		return list
+	}
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(listGVK)
+	return list
+}
+
+// isPrunable returns true if obj is controlled by the instance, was created by a prior operator version and did not opt
+// out of pruning
+func isPrunable(obj metav1.Object, metadata *executionMetadata) bool {
+	if metadata.resourcesOwner != nil && !metav1.IsControlledBy(obj, metadata.resourcesOwner) {
+		return false
+	}
+	annotations := obj.GetAnnotations()
+	version, ok := annotations[kudo.OperatorVersionAnnotation]
+	if !ok || version == metadata.operatorVersion {
+		return false
+	}
+	return annotations[kudo.PruneAnnotation] != "false"
+}
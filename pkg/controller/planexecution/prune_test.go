@@ -0,0 +1,148 @@
+package planexecution
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kudobuilder/kudo/pkg/apis/kudo/v1alpha1"
+	"github.com/kudobuilder/kudo/pkg/util/kudo"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPruneResources(t *testing.T) {
+	instance := &v1alpha1.Instance{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "kudo.dev/v1alpha1", Kind: "Instance"},
+		ObjectMeta: metav1.ObjectMeta{Name: "instance", Namespace: "default", UID: "1234"},
+	}
+
+	owned := func(obj metav1.Object, version string, annotations map[string]string) runtime.Object {
+		obj.SetLabels(map[string]string{kudo.InstanceLabel: "instance"})
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[kudo.OperatorVersionAnnotation] = version
+		obj.SetAnnotations(annotations)
+		controller := true
+		obj.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "kudo.dev/v1alpha1", Kind: "Instance", Name: "instance", UID: "1234", Controller: &controller}})
+		return obj.(runtime.Object)
+	}
+	configMap := func(name string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		}
+	}
+
+	foreign := configMap("foreign")
+	foreign.Labels = map[string]string{kudo.InstanceLabel: "instance"}
+	foreign.Annotations = map[string]string{kudo.OperatorVersionAnnotation: "1.0"}
+
+	// when impersonating the service account of the instance, only the kinds rendered by the plan are pruned
+	for _, impersonating := range []bool{false, true} {
+		var c client.Client = fake.NewFakeClientWithScheme(scheme.Scheme,
+			owned(configMap("removed"), "1.0", nil),
+			owned(configMap("opted-out"), "1.0", map[string]string{kudo.PruneAnnotation: "false"}),
+			owned(configMap("current"), "2.0", nil),
+			owned(configMap("rendered"), "1.0", nil),
+			owned(&corev1.Service{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+				ObjectMeta: metav1.ObjectMeta{Name: "removed-svc", Namespace: "default"},
+			}, "1.0", nil),
+			foreign.DeepCopy(),
+		)
+		if impersonating {
+			c = &impersonatingClient{Client: c, writer: c}
+		}
+
+		resources := &planResources{PhaseResources: map[string]phaseResources{
+			"phase": {StepResources: map[string][]runtime.Object{"step": {configMap("rendered")}}},
+		}}
+		err := pruneResources(resources, &executionMetadata{
+			instanceName:      "instance",
+			instanceNamespace: "default",
+			operatorVersion:   "2.0",
+			resourcesOwner:    instance,
+		}, c, log)
+		assert.NoError(t, err)
+
+		tests := []struct {
+			name    string
+			obj     runtime.Object
+			deleted bool
+		}{
+			{"removed", &corev1.ConfigMap{}, true},
+			{"removed-svc", &corev1.Service{}, !impersonating},
+			{"opted-out", &corev1.ConfigMap{}, false},
+			{"current", &corev1.ConfigMap{}, false},
+			{"rendered", &corev1.ConfigMap{}, false},
+			{"foreign", &corev1.ConfigMap{}, false},
+		}
+
+		for _, tt := range tests {
+			err := c.Get(context.TODO(), client.ObjectKey{Name: tt.name, Namespace: "default"}, tt.obj)
+			if tt.deleted {
+				assert.True(t, apierrors.IsNotFound(err), "%s was not pruned, impersonating: %v", tt.name, impersonating)
+			} else {
+				assert.NoError(t, err, "%s was pruned, impersonating: %v", tt.name, impersonating)
+			}
+		}
+	}
+}
+
+func TestExecutePlanPrunesAfterUpdateFallback(t *testing.T) {
+	instance := &v1alpha1.Instance{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "kudo.dev/v1alpha1", Kind: "Instance"},
+		ObjectMeta: metav1.ObjectMeta{Name: "instance", Namespace: "default", UID: "1234"},
+	}
+	controller := true
+	removed := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "removed",
+			Namespace:       "default",
+			Labels:          map[string]string{kudo.InstanceLabel: "instance"},
+			Annotations:     map[string]string{kudo.OperatorVersionAnnotation: "1.0"},
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "kudo.dev/v1alpha1", Kind: "Instance", Name: "instance", UID: "1234", Controller: &controller}},
+		},
+	}
+	c := fake.NewFakeClientWithScheme(scheme.Scheme, removed)
+
+	// the operator version has no upgrade plan, the instance controller upgrades it with the update plan
+	plan := &activePlan{
+		Name: "update",
+		State: &v1alpha1.PlanExecutionStatus{
+			State:    v1alpha1.PhaseStatePending,
+			Name:     "update",
+			Strategy: "serial",
+			Phases:   []v1alpha1.PhaseStatus{{Strategy: "serial", Name: "phase", State: v1alpha1.PhaseStatePending, Steps: []v1alpha1.StepStatus{{State: v1alpha1.PhaseStatePending, Name: "step"}}}},
+		},
+		Spec: &v1alpha1.Plan{
+			Strategy: "serial",
+			Phases: []v1alpha1.Phase{
+				{Name: "phase", Strategy: "serial", Steps: []v1alpha1.Step{{Name: "step", Tasks: []string{"task"}}}},
+			},
+		},
+		Tasks:     map[string]v1alpha1.TaskSpec{"task": {Resources: []string{"pod"}}},
+		Templates: map[string]string{"pod": getResourceAsString(getPod("pod1", "default"))},
+	}
+	status, err := executePlan(plan, &executionMetadata{
+		instanceName:      "instance",
+		instanceNamespace: "default",
+		operatorName:      "operator",
+		operatorVersion:   "2.0",
+		resourcesOwner:    instance,
+	}, c, &testKubernetesObjectEnhancer{}, record.NewFakeRecorder(10))
+	assert.NoError(t, err)
+	assert.Equal(t, v1alpha1.PhaseStateComplete, status.State)
+
+	err = c.Get(context.TODO(), client.ObjectKey{Name: "removed", Namespace: "default"}, &corev1.ConfigMap{})
+	assert.True(t, apierrors.IsNotFound(err), "removed was not pruned")
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/kudobuilder/kudo/pkg/kudoctl/files"
@@ -131,6 +132,11 @@ func packageVersionedName(pkg *PackageFiles) string {
 	return fmt.Sprintf("%v-%v", pkg.Operator.Name, pkg.Operator.Version)
 }
 
+// isTestsDir returns true if dir is the directory of the package in packagePath which contains its tests
+func isTestsDir(packagePath string, dir string) bool {
+	return filepath.Clean(dir) == filepath.Join(packagePath, TestsDirName)
+}
+
 // fromFolder walks the path provided and returns CRD package files or an error
 func fromFolder(fs afero.Fs, packagePath string) (*PackageFiles, error) {
 	result := newPackageFiles()
@@ -140,6 +146,10 @@ func fromFolder(fs afero.Fs, packagePath string) (*PackageFiles, error) {
 			return err
 		}
 		if file.IsDir() {
+			if isTestsDir(packagePath, path) {
+				// tests of the package are not part of the operator
+				return filepath.SkipDir
+			}
 			// skip directories
 			return nil
 		}
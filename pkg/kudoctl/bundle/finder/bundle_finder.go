@@ -1,11 +1,14 @@
 package finder
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 
 	"github.com/kudobuilder/kudo/pkg/kudoctl/bundle"
 	"github.com/kudobuilder/kudo/pkg/kudoctl/http"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/oci"
+
 	"github.com/spf13/afero"
 )
 
@@ -25,19 +28,28 @@ type URLFinder struct {
 	client http.Client
 }
 
+// OCIFinder will find an operator bundle in an OCI registry
+type OCIFinder struct {
+	client *oci.Client
+}
+
 // Manager is the source of finder of operator bundles.
 type Manager struct {
 	local *LocalFinder
 	uri   *URLFinder
+	oci   *OCIFinder
 }
 
 // New creates an operator bundle finder for non-repository bundles
 func New() *Manager {
 	lf := NewLocal()
 	uf := NewURL()
+	// the default options read no CA file and can not fail
+	of, _ := NewOCI(oci.Options{})
 	return &Manager{
 		local: lf,
 		uri:   uf,
+		oci:   of,
 	}
 }
 
@@ -53,6 +65,11 @@ func (f *Manager) GetBundle(name string, version string) (bundle.Bundle, error)
 		return b, nil
 	}
 
+	// if oci reference return that bundle, oci references are valid urls as well
+	if oci.IsReference(name) {
+		return f.oci.GetBundle(name, version)
+	}
+
 	// if url return that bundle
 	if http.IsValidURL(name) {
 		b, err := f.uri.GetBundle(name, version)
@@ -87,6 +104,29 @@ func (f *URLFinder) getBundleByURL(url string) (io.Reader, error) {
 	return resp, nil
 }
 
+// GetBundle provides a bundle for an OCI reference like oci://registry/operators/kafka:1.0.0. If the reference
+// has no tag, the version is used as tag.
+func (f *OCIFinder) GetBundle(name string, version string) (bundle.Bundle, error) {
+	ref, err := oci.ParseReference(name)
+	if err != nil {
+		return nil, fmt.Errorf("finder: %v", err)
+	}
+	if ref.Tag == "" {
+		if version == "" {
+			return nil, fmt.Errorf("finder: no version given for %v, add a tag or use --version", name)
+		}
+		ref = ref.WithTag(version)
+	} else if version != "" && version != ref.Tag {
+		return nil, fmt.Errorf("finder: version %v does not match tag of %v", version, name)
+	}
+
+	content, err := f.client.Pull(ref)
+	if err != nil {
+		return nil, fmt.Errorf("finder: unable to pull %v: %v", ref, err)
+	}
+	return bundle.NewBundleFromReader(bytes.NewReader(content)), nil
+}
+
 // GetBundle provides a bundle for the local folder or tarball provided
 func (f *LocalFinder) GetBundle(name string, version string) (bundle.Bundle, error) {
 	//	make sure file exists
@@ -105,6 +145,15 @@ func NewLocal() *LocalFinder {
 	return &LocalFinder{fs: afero.NewOsFs()}
 }
 
+// NewOCI creates an instance of an OCIFinder
+func NewOCI(options oci.Options) (*OCIFinder, error) {
+	client, err := oci.NewClient(options)
+	if err != nil {
+		return nil, err
+	}
+	return &OCIFinder{client: client}, nil
+}
+
 // NewURL creates an instance of a URLFinder
 func NewURL() *URLFinder {
 	client := http.NewClient()
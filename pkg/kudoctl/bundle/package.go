@@ -64,6 +64,8 @@ func parsePackageFile(filePath string, fileBytes []byte, currentPackage *Package
 	}
 
 	switch {
+	case strings.HasPrefix(filePath, TestsDirName+"/"):
+		// tests of the package are not part of the operator
 	case isOperatorFile(filePath):
 		if err := yaml.Unmarshal(fileBytes, &currentPackage.Operator); err != nil {
 			return errors.Wrap(err, "failed to unmarshal operator file")
@@ -132,6 +134,11 @@ func (p *PackageFiles) getCRDs() (*PackageCRDs, error) {
 			}
 		}
 	}
+	for _, d := range p.Operator.Dependencies {
+		if _, err := ParseVersionConstraint(d.Version); d.Version != "" && err != nil {
+			errs = append(errs, fmt.Sprintf("dependency %s: %v", d.Name, err))
+		}
+	}
 
 	if len(errs) != 0 {
 		return nil, errors.New(strings.Join(errs, "\n"))
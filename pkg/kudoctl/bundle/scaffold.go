@@ -0,0 +1,404 @@
+package bundle
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kudobuilder/kudo/pkg/apis/kudo/v1alpha1"
+	"github.com/kudobuilder/kudo/pkg/bundle"
+
+	"github.com/spf13/afero"
+	yamlv3 "gopkg.in/yaml.v3"
+	"sigs.k8s.io/yaml"
+)
+
+// TestsDirName is the directory of a package containing test cases for `kudo test`. It is not part of the
+// operator and ignored when the package is loaded.
+const TestsDirName = "tests"
+
+var operatorNameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+const (
+	// nameVariable is replaced with the operator name in the skeleton files
+	nameVariable = "__NAME__"
+	// versionVariable is replaced with the operator version in the skeleton files
+	versionVariable = "__VERSION__"
+	// skeletonVersion is the version of a new operator package
+	skeletonVersion = "0.1.0"
+)
+
+// skeletonFiles are the files of a new operator package
+var skeletonFiles = map[string]string{
+	operatorFileName: `name: "__NAME__"
+version: "__VERSION__"
+appVersion: "1.0.0"
+kudoVersion: 0.8.0
+kubernetesVersion: 1.15.0
+maintainers:
+  - name: Your Name
+    email: you@example.com
+url: https://example.com/__NAME__
+tasks:
+  app:
+    resources:
+      - service.yaml
+      - statefulset.yaml
+plans:
+  deploy:
+    strategy: serial
+    phases:
+      - name: main
+        strategy: parallel
+        steps:
+          - name: everything
+            tasks:
+              - app
+`,
+	paramsFileName: `REPLICAS:
+  description: Number of pods to run
+  default: "1"
+IMAGE:
+  description: Container image to run
+  default: "nginx:1.17"
+`,
+	filepath.Join("templates", "service.yaml"): `apiVersion: v1
+kind: Service
+metadata:
+  name: svc
+  namespace: {{ .Namespace }}
+  labels:
+    app: __NAME__
+    instance: {{ .Name }}
+spec:
+  ports:
+    - port: 80
+      name: http
+  clusterIP: None
+  selector:
+    app: __NAME__
+    instance: {{ .Name }}
+`,
+	filepath.Join("templates", "statefulset.yaml"): `apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: app
+  namespace: {{ .Namespace }}
+spec:
+  selector:
+    matchLabels:
+      app: __NAME__
+      instance: {{ .Name }}
+  serviceName: {{ .Name }}-svc
+  replicas: {{ .Params.REPLICAS }}
+  template:
+    metadata:
+      labels:
+        app: __NAME__
+        instance: {{ .Name }}
+    spec:
+      containers:
+        - name: __NAME__
+          image: {{ .Params.IMAGE }}
+          ports:
+            - containerPort: 80
+              name: http
+`,
+	filepath.Join(TestsDirName, "deploy", "00-install.yaml"): `apiVersion: kudo.dev/v1alpha1
+kind: Instance
+metadata:
+  name: __NAME__
+  labels:
+    kudo.dev/operator: __NAME__
+spec:
+  operatorVersion:
+    name: __NAME__-__VERSION__
+`,
+	filepath.Join(TestsDirName, "deploy", "00-assert.yaml"): `apiVersion: kudo.dev/v1alpha1
+kind: Instance
+metadata:
+  name: __NAME__
+status:
+  status: COMPLETE
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: __NAME__-app
+status:
+  readyReplicas: 1
+`,
+}
+
+// ValidateOperatorName returns an error if name can not be the name of an operator, which is also the directory of
+// its package
+func ValidateOperatorName(name string) error {
+	if !operatorNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid operator name %q: has to consist of lower case alphanumeric characters or '-'", name)
+	}
+	return nil
+}
+
+// NewSkeleton creates a new operator package for the operator name in the directory path/name. The package has a
+// deploy plan which runs a StatefulSet with a Service and a test case for `kudo test --package`.
+// Returns the path of the package.
+func NewSkeleton(fs afero.Fs, path string, name string) (string, error) {
+	if err := ValidateOperatorName(name); err != nil {
+		return "", err
+	}
+	dir := filepath.Join(path, name)
+	if exists, _ := afero.Exists(fs, dir); exists {
+		return "", fmt.Errorf("%s already exists", dir)
+	}
+
+	names := make([]string, 0, len(skeletonFiles))
+	for n := range skeletonFiles {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	replacer := strings.NewReplacer(nameVariable, name, versionVariable, skeletonVersion)
+	for _, n := range names {
+		file := filepath.Join(dir, n)
+		if err := fs.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return "", err
+		}
+		if err := afero.WriteFile(fs, file, []byte(replacer.Replace(skeletonFiles[n])), 0644); err != nil {
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+// AddParameter adds a parameter to the params.yaml of the package at path
+func AddParameter(fs afero.Fs, path string, p v1alpha1.Parameter) error {
+	file := filepath.Join(path, paramsFileName)
+	content, err := afero.ReadFile(fs, file)
+	if err != nil {
+		return err
+	}
+	params := map[string]map[string]string{}
+	if err := yaml.Unmarshal(content, &params); err != nil {
+		return fmt.Errorf("failed to unmarshal parameters file: %s: %v", file, err)
+	}
+	if params == nil {
+		params = map[string]map[string]string{}
+	}
+	if _, ok := params[p.Name]; ok {
+		return fmt.Errorf("parameter %s already exists", p.Name)
+	}
+
+	return addEntry(fs, file, "", p.Name, parameterToMap(p))
+}
+
+// AddTask adds a task to the operator.yaml of the package at path. Templates of the task which don't exist yet are
+// created as an empty ConfigMap to be filled in.
+func AddTask(fs afero.Fs, path string, name string, task v1alpha1.TaskSpec) error {
+	operator, err := readOperator(fs, path)
+	if err != nil {
+		return err
+	}
+	if _, ok := operator.Tasks[name]; ok {
+		return fmt.Errorf("task %s already exists", name)
+	}
+	if len(task.Resources) == 0 {
+		return fmt.Errorf("task %s needs at least one resource", name)
+	}
+
+	for _, res := range task.Resources {
+		template := filepath.Join(path, "templates", res)
+		if exists, _ := afero.Exists(fs, template); exists {
+			continue
+		}
+		if err := fs.MkdirAll(filepath.Dir(template), 0755); err != nil {
+			return err
+		}
+		placeholder := fmt.Sprintf("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: %s\n  namespace: {{ .Namespace }}\ndata: {}\n", trimExtension(res))
+		if err := afero.WriteFile(fs, template, []byte(placeholder), 0644); err != nil {
+			return err
+		}
+	}
+
+	return addEntry(fs, filepath.Join(path, operatorFileName), "tasks", name, task)
+}
+
+// AddPlan adds a plan with a single phase and step running the given tasks to the operator.yaml of the package at path
+func AddPlan(fs afero.Fs, path string, name string, strategy v1alpha1.Ordering, tasks []string) error {
+	operator, err := readOperator(fs, path)
+	if err != nil {
+		return err
+	}
+	if _, ok := operator.Plans[name]; ok {
+		return fmt.Errorf("plan %s already exists", name)
+	}
+	if len(tasks) == 0 {
+		return fmt.Errorf("plan %s needs at least one task", name)
+	}
+	for _, t := range tasks {
+		if _, ok := operator.Tasks[t]; !ok {
+			return fmt.Errorf("task %s does not exist, add it with 'kudo package add task'", t)
+		}
+	}
+
+	plan := v1alpha1.Plan{
+		Strategy: strategy,
+		Phases: []v1alpha1.Phase{{
+			Name:     "main",
+			Strategy: strategy,
+			Steps:    []v1alpha1.Step{{Name: "everything", Tasks: tasks}},
+		}},
+	}
+	return addEntry(fs, filepath.Join(path, operatorFileName), "plans", name, plan)
+}
+
+// WritePackage writes the operator.yaml, params.yaml and templates of the package files to the directory path
+func WritePackage(fs afero.Fs, path string, pf *PackageFiles) error {
+	if err := fs.MkdirAll(filepath.Join(path, "templates"), 0755); err != nil {
+		return err
+	}
+	if err := writeYAML(fs, filepath.Join(path, operatorFileName), pf.Operator); err != nil {
+		return err
+	}
+	params := map[string]map[string]string{}
+	for _, p := range pf.Params {
+		params[p.Name] = parameterToMap(p)
+	}
+	if err := writeYAML(fs, filepath.Join(path, paramsFileName), params); err != nil {
+		return err
+	}
+	for name, template := range pf.Templates {
+		if err := afero.WriteFile(fs, filepath.Join(path, "templates", name), []byte(template), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parameterToMap converts a parameter to its representation in params.yaml
+func parameterToMap(p v1alpha1.Parameter) map[string]string {
+	param := map[string]string{}
+	if p.Description != "" {
+		param["description"] = p.Description
+	}
+	if p.DisplayName != "" {
+		param["displayName"] = p.DisplayName
+	}
+	if p.Default != nil {
+		param["default"] = *p.Default
+	}
+	if p.Trigger != "" {
+		param["trigger"] = p.Trigger
+	}
+	if !p.Required {
+		param["required"] = strconv.FormatBool(p.Required)
+	}
+	return param
+}
+
+func readOperator(fs afero.Fs, path string) (*bundle.Operator, error) {
+	file := filepath.Join(path, operatorFileName)
+	content, err := afero.ReadFile(fs, file)
+	if err != nil {
+		return nil, err
+	}
+	operator := &bundle.Operator{}
+	if err := yaml.Unmarshal(content, operator); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal operator file: %s: %v", file, err)
+	}
+	return operator, nil
+}
+
+// addEntry adds key with value to the mapping of section in the YAML file, or to its top level mapping if section is
+// empty. The file is edited as a tree of YAML nodes, so the comments and the order of the keys people maintain in the
+// file are kept.
+func addEntry(fs afero.Fs, file string, section string, key string, value interface{}) error {
+	content, err := afero.ReadFile(fs, file)
+	if err != nil {
+		return err
+	}
+	doc := &yamlv3.Node{}
+	if err := yamlv3.Unmarshal(content, doc); err != nil {
+		return fmt.Errorf("failed to parse %s: %v", file, err)
+	}
+	if doc.Kind == 0 {
+		// the file is empty
+		doc = &yamlv3.Node{Kind: yamlv3.DocumentNode, Content: []*yamlv3.Node{{Kind: yamlv3.MappingNode}}}
+	}
+	mapping, err := asMapping(doc.Content[0])
+	if err != nil {
+		return fmt.Errorf("%s: %v", file, err)
+	}
+	if section != "" {
+		if mapping, err = mappingValue(mapping, section); err != nil {
+			return fmt.Errorf("%s: %v", file, err)
+		}
+	}
+
+	// the value is marshaled by its JSON field names, the same way package files are parsed
+	v, err := yaml.Marshal(value)
+	if err != nil {
+		return err
+	}
+	valueDoc := &yamlv3.Node{}
+	if err := yamlv3.Unmarshal(v, valueDoc); err != nil {
+		return err
+	}
+	mapping.Content = append(mapping.Content, &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: key}, valueDoc.Content[0])
+
+	buf := &bytes.Buffer{}
+	encoder := yamlv3.NewEncoder(buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+	return afero.WriteFile(fs, file, buf.Bytes(), os.FileMode(0644))
+}
+
+// mappingValue returns the mapping of key in mapping, an empty mapping is added if there is none
+func mappingValue(mapping *yamlv3.Node, key string) (*yamlv3.Node, error) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			value, err := asMapping(mapping.Content[i+1])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", key, err)
+			}
+			return value, nil
+		}
+	}
+	value := &yamlv3.Node{Kind: yamlv3.MappingNode}
+	mapping.Content = append(mapping.Content, &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: key}, value)
+	return value, nil
+}
+
+// asMapping returns node if it is a mapping, an empty value is turned into an empty mapping
+func asMapping(node *yamlv3.Node) (*yamlv3.Node, error) {
+	if node.Kind == yamlv3.ScalarNode && node.Tag == "!!null" {
+		node.Kind, node.Tag, node.Value, node.Style = yamlv3.MappingNode, "", "", 0
+	}
+	if node.Kind != yamlv3.MappingNode {
+		return nil, errors.New("expected a mapping")
+	}
+	// entries are added in block style even if the mapping was empty, e.g. 'tasks: {}'
+	node.Style = 0
+	return node, nil
+}
+
+func writeYAML(fs afero.Fs, file string, v interface{}) error {
+	content, err := yaml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return afero.WriteFile(fs, file, content, os.FileMode(0644))
+}
+
+func trimExtension(name string) string {
+	return filepath.Base(name)[:len(filepath.Base(name))-len(filepath.Ext(name))]
+}
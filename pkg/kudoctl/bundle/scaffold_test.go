@@ -0,0 +1,156 @@
+package bundle
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kudobuilder/kudo/pkg/apis/kudo/v1alpha1"
+	"github.com/kudobuilder/kudo/pkg/util/kudo"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSkeleton(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	dir, err := NewSkeleton(fs, "/opt", "my-operator")
+	assert.NoError(t, err)
+	assert.Equal(t, "/opt/my-operator", dir)
+
+	b, err := NewBundle(fs, dir)
+	assert.NoError(t, err)
+	crds, err := b.GetCRDs()
+	assert.NoError(t, err)
+	assert.Equal(t, "my-operator", crds.Operator.Name)
+	assert.Contains(t, crds.OperatorVersion.Spec.Plans, "deploy")
+	assert.Len(t, crds.OperatorVersion.Spec.Templates, 2)
+	assert.Len(t, crds.OperatorVersion.Spec.Parameters, 2)
+
+	exists, _ := afero.Exists(fs, "/opt/my-operator/tests/deploy/00-install.yaml")
+	assert.True(t, exists)
+
+	// the tests are packaged and ignored when the package is loaded
+	assert.NoError(t, fs.MkdirAll("/out", 0755))
+	tarball, err := ToTarBundle(fs, dir, "/out", false)
+	assert.NoError(t, err)
+	assert.NoError(t, Untar(fs, "/untar", mustOpen(t, fs, tarball)))
+	install, err := afero.ReadFile(fs, "/untar/tests/deploy/00-install.yaml")
+	assert.NoError(t, err)
+	assert.Contains(t, string(install), "name: my-operator-0.1.0")
+	b, err = NewBundle(fs, tarball)
+	assert.NoError(t, err)
+	_, err = b.GetCRDs()
+	assert.NoError(t, err)
+
+	_, err = NewSkeleton(fs, "/opt", "my-operator")
+	assert.EqualError(t, err, "/opt/my-operator already exists")
+	_, err = NewSkeleton(fs, "/opt", "My_Operator")
+	assert.EqualError(t, err, `invalid operator name "My_Operator": has to consist of lower case alphanumeric characters or '-'`)
+}
+
+func TestAddToSkeleton(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	dir, err := NewSkeleton(fs, "/opt", "my-operator")
+	assert.NoError(t, err)
+
+	assert.NoError(t, AddParameter(fs, dir, v1alpha1.Parameter{Name: "MEMORY", Description: "Memory of each pod", Default: kudo.String("256Mi")}))
+	assert.NoError(t, AddParameter(fs, dir, v1alpha1.Parameter{Name: "BACKUP_BUCKET"}))
+	assert.EqualError(t, AddParameter(fs, dir, v1alpha1.Parameter{Name: "MEMORY"}), "parameter MEMORY already exists")
+
+	assert.NoError(t, AddTask(fs, dir, "backup", v1alpha1.TaskSpec{Resources: []string{"backup-job.yaml", "service.yaml"}}))
+	assert.EqualError(t, AddTask(fs, dir, "backup", v1alpha1.TaskSpec{Resources: []string{"job.yaml"}}), "task backup already exists")
+	assert.EqualError(t, AddTask(fs, dir, "restore", v1alpha1.TaskSpec{}), "task restore needs at least one resource")
+
+	assert.NoError(t, AddPlan(fs, dir, "backup", v1alpha1.Serial, []string{"backup"}))
+	assert.EqualError(t, AddPlan(fs, dir, "backup", v1alpha1.Serial, []string{"backup"}), "plan backup already exists")
+	assert.EqualError(t, AddPlan(fs, dir, "restore", v1alpha1.Serial, []string{"restore"}), "task restore does not exist, add it with 'kudo package add task'")
+
+	b, err := NewBundle(fs, dir)
+	assert.NoError(t, err)
+	crds, err := b.GetCRDs()
+	assert.NoError(t, err)
+
+	spec := crds.OperatorVersion.Spec
+	assert.Len(t, spec.Templates, 3)
+	assert.Contains(t, spec.Templates["backup-job.yaml"], "name: backup-job")
+	assert.Equal(t, []string{"backup-job.yaml", "service.yaml"}, spec.Tasks["backup"].Resources)
+	assert.Equal(t, []string{"backup"}, spec.Plans["backup"].Phases[0].Steps[0].Tasks)
+	assert.Contains(t, spec.Plans, "deploy")
+
+	params := map[string]v1alpha1.Parameter{}
+	for _, p := range spec.Parameters {
+		params[p.Name] = p
+	}
+	assert.Equal(t, "256Mi", *params["MEMORY"].Default)
+	assert.Equal(t, "Memory of each pod", params["MEMORY"].Description)
+	assert.False(t, params["MEMORY"].Required)
+	assert.Contains(t, params, "IMAGE")
+}
+
+func mustOpen(t *testing.T, fs afero.Fs, path string) afero.File {
+	f, err := fs.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestAddKeepsComments(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	operator := `# maintained by hand
+name: "zk"
+version: "0.1.0"
+tasks:
+  # the application
+  app:
+    resources:
+      - app.yaml
+plans:
+  deploy:
+    strategy: serial # one step after the other
+    phases:
+      - name: main
+        strategy: serial
+        steps:
+          - name: everything
+            tasks:
+              - app
+`
+	params := `# sizing
+REPLICAS:
+  default: "3"
+`
+	if err := afero.WriteFile(fs, "/opt/zk/operator.yaml", []byte(operator), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := afero.WriteFile(fs, "/opt/zk/params.yaml", []byte(params), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NoError(t, AddParameter(fs, "/opt/zk", v1alpha1.Parameter{Name: "MEMORY", Default: kudo.String("1Gi"), Required: true}))
+	assert.NoError(t, AddTask(fs, "/opt/zk", "backup", v1alpha1.TaskSpec{Resources: []string{"app.yaml"}}))
+	assert.NoError(t, AddPlan(fs, "/opt/zk", "backup", v1alpha1.Serial, []string{"backup"}))
+
+	// entries are appended, comments and the order of the existing keys are kept
+	content, err := afero.ReadFile(fs, "/opt/zk/operator.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Replace(operator, "plans:\n", `  backup:
+    resources:
+      - app.yaml
+plans:
+`, 1)+`  backup:
+    phases:
+      - name: main
+        steps:
+          - name: everything
+            tasks:
+              - backup
+        strategy: serial
+    strategy: serial
+`, string(content))
+
+	content, err = afero.ReadFile(fs, "/opt/zk/params.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, params+"MEMORY:\n  default: 1Gi\n", string(content))
+}
@@ -0,0 +1,93 @@
+package bundle
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// Packages can be signed with an ed25519 key. The detached signature is stored base64 encoded next to the
+// package tarball, e.g. zookeeper-0.1.0.tgz.sig. Keys are PEM encoded the way `openssl genpkey -algorithm ed25519`
+// creates them: private keys in PKCS #8 and public keys in PKIX form.
+
+// SignatureExtension is appended to the name of a package tarball to get the name of its detached signature
+const SignatureExtension = ".sig"
+
+// SignTarBundle creates a detached signature for the package tarball at path using the PEM encoded private key.
+// Returns the path of the signature file.
+func SignTarBundle(fs afero.Fs, path string, privateKeyPEM []byte) (string, error) {
+	key, err := ParsePrivateKey(privateKeyPEM)
+	if err != nil {
+		return "", err
+	}
+	content, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return "", err
+	}
+
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(key, content))
+	sigPath := path + SignatureExtension
+	if err := afero.WriteFile(fs, sigPath, []byte(signature+"\n"), 0644); err != nil {
+		return "", err
+	}
+	return sigPath, nil
+}
+
+// VerifySignature verifies the base64 encoded detached signature of package content against the PEM encoded
+// trusted public keys. The signature is valid if any of the keys verifies it.
+func VerifySignature(content []byte, signature []byte, trustedKeys []string) error {
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+		return fmt.Errorf("invalid package signature: %v", err)
+	}
+	for _, k := range trustedKeys {
+		key, err := ParsePublicKey([]byte(k))
+		if err != nil {
+			return err
+		}
+		if ed25519.Verify(key, content, sig) {
+			return nil
+		}
+	}
+	return errors.New("package signature does not match any trusted key")
+}
+
+// ParsePrivateKey parses a PEM encoded PKCS #8 ed25519 private key
+func ParsePrivateKey(privateKeyPEM []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(privateKeyPEM)
+	if block == nil {
+		return nil, errors.New("no PEM encoded private key found")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %v", err)
+	}
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T, expecting an ed25519 key", key)
+	}
+	return edKey, nil
+}
+
+// ParsePublicKey parses a PEM encoded PKIX ed25519 public key
+func ParsePublicKey(publicKeyPEM []byte) (ed25519.PublicKey, error) {
+	block, _ := pem.Decode(publicKeyPEM)
+	if block == nil {
+		return nil, errors.New("no PEM encoded public key found")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %v", err)
+	}
+	edKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported public key type %T, expecting an ed25519 key", key)
+	}
+	return edKey, nil
+}
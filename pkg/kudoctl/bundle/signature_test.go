@@ -0,0 +1,75 @@
+package bundle
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestKeyPair(t *testing.T) (privateKeyPEM []byte, publicKeyPEM []byte) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+}
+
+func TestSignTarBundle(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	content := []byte("package content")
+	assert.NoError(t, afero.WriteFile(fs, "/zk-0.1.0.tgz", content, 0644))
+
+	privateKey, publicKey := newTestKeyPair(t)
+	_, otherKey := newTestKeyPair(t)
+
+	sigPath, err := SignTarBundle(fs, "/zk-0.1.0.tgz", privateKey)
+	assert.NoError(t, err)
+	assert.Equal(t, "/zk-0.1.0.tgz.sig", sigPath)
+	signature, err := afero.ReadFile(fs, sigPath)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		content []byte
+		keys    []string
+		err     string
+	}{
+		{"trusted key", content, []string{string(publicKey)}, ""},
+		{"one of several trusted keys", content, []string{string(otherKey), string(publicKey)}, ""},
+		{"untrusted key", content, []string{string(otherKey)}, "package signature does not match any trusted key"},
+		{"modified content", []byte("modified content"), []string{string(publicKey)}, "package signature does not match any trusted key"},
+		{"invalid key", content, []string{"not a key"}, "no PEM encoded public key found"},
+	}
+
+	for _, tt := range tests {
+		err := VerifySignature(tt.content, signature, tt.keys)
+		if tt.err == "" {
+			assert.NoError(t, err, tt.name)
+		} else {
+			assert.EqualError(t, err, tt.err, tt.name)
+		}
+	}
+}
+
+func TestSignTarBundle_InvalidKey(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	assert.NoError(t, afero.WriteFile(fs, "/zk-0.1.0.tgz", []byte("package content"), 0644))
+
+	_, publicKey := newTestKeyPair(t)
+	_, err := SignTarBundle(fs, "/zk-0.1.0.tgz", publicKey)
+	assert.Error(t, err)
+}
@@ -27,7 +27,7 @@ func tarballWriter(fs afero.Fs, path string, w io.Writer) error {
 		}
 
 		// return on non-regular files.  We don't add directories without files and symlinks
-		if !fi.Mode().IsRegular() {
+		if !fi.Mode().IsRegular() || fi.IsDir() {
 			return nil
 		}
 
@@ -120,6 +120,10 @@ func Untar(fs afero.Fs, path string, r io.Reader) error {
 
 		// if it's a file create it
 		case tar.TypeReg:
+			// directories are not part of tarballs created by tarballWriter
+			if err := fs.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
 			f, err := fs.OpenFile(target, os.O_CREATE|os.O_RDWR, os.FileMode(header.Mode))
 			if err != nil {
 				return err
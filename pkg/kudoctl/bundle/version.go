@@ -0,0 +1,23 @@
+package bundle
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/Masterminds/semver"
+)
+
+// constraintSeparator matches the whitespace between two comparisons like in `>=1.0 <2.0`, but not the whitespace
+// after an operator (`>= 1.0`) or around the dash of a range (`1.0 - 2.0`)
+var constraintSeparator = regexp.MustCompile(`([0-9A-Za-z*])\s+([<>=!~^])`)
+
+// ParseVersionConstraint parses a semver constraint as used for operator versions when installing and for the
+// versions of operator dependencies, e.g. `~2.3`, `^1.0`, `>=1.0 <2.0` or `>=1.0, <2.0 || 3.x`.
+// Comparisons separated by whitespace are combined the same way as comparisons separated by a comma.
+func ParseVersionConstraint(constraint string) (*semver.Constraints, error) {
+	c, err := semver.NewConstraint(constraintSeparator.ReplaceAllString(constraint, "$1,$2"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid version constraint %q: %v", constraint, err)
+	}
+	return c, nil
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/kudobuilder/kudo/pkg/kudoctl/kudohome"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/util/repo"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/xlab/treeprint"
+)
+
+const infoDesc = `
+Show the details of an operator in a repository: its description, versions, requirements, parameters and plans.
+`
+
+const infoExample = `  # Show the most recent version of kafka
+  kubectl kudo info kafka
+
+  # Show a specific version of kafka from a specific repository
+  kubectl kudo info kafka --version 0.2.0 --repo community`
+
+type infoCmd struct {
+	name     string
+	version  string
+	repoName string
+	home     kudohome.Home
+	out      io.Writer
+	fs       afero.Fs
+}
+
+func newInfoCmd(fs afero.Fs, out io.Writer) *cobra.Command {
+	info := &infoCmd{out: out, fs: fs}
+
+	cmd := &cobra.Command{
+		Use:     "info <name>",
+		Short:   "Show details of an operator in a repository",
+		Long:    infoDesc,
+		Example: infoExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("expecting exactly one argument - name of the operator")
+			}
+			info.name = args[0]
+			info.home = Settings.Home
+			return info.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&info.version, "version", "v", "", "A specific operator version. (default to the most recent)")
+	f.StringVar(&info.repoName, "repo", "", "Name of repository configuration to use. (default resolves the operator across all repositories by priority)")
+
+	return cmd
+}
+
+// resolve returns the client of the repository providing the operator and its package version. Without a repository
+// the operator is resolved by priority the same way install resolves it.
+func (i *infoCmd) resolve() (*repo.Client, *repo.PackageVersion, error) {
+	if i.repoName == "" {
+		resolver, err := repo.ResolverFromSettings(i.fs, i.home, false)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not build operator repository: %v", err)
+		}
+		return resolver.Resolve(i.name, i.version)
+	}
+
+	client, err := repo.ClientFromSettings(i.fs, i.home, i.repoName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not build operator repository: %v", err)
+	}
+	index, err := client.DownloadIndexFile()
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not download repository index file: %v", err)
+	}
+	pv, err := index.GetByNameAndVersion(i.name, i.version)
+	if err != nil {
+		return nil, nil, err
+	}
+	return client, pv, nil
+}
+
+func (i *infoCmd) run() error {
+	client, pv, err := i.resolve()
+	if err != nil {
+		return err
+	}
+	index, err := client.DownloadIndexFile()
+	if err != nil {
+		return fmt.Errorf("could not download repository index file: %v", err)
+	}
+	b, err := client.GetBundleForPackageVersion(pv)
+	if err != nil {
+		return err
+	}
+	pf, err := b.GetPkgFiles()
+	if err != nil {
+		return fmt.Errorf("could not read package %s-%s: %v", pv.Name, pv.Version, err)
+	}
+	o := pf.Operator
+
+	versions := []string{}
+	for _, v := range index.Entries[i.name] {
+		versions = append(versions, v.Version)
+	}
+	maintainers := []string{}
+	for _, m := range o.Maintainers {
+		maintainers = append(maintainers, fmt.Sprintf("%s <%s>", m.Name, m.Email))
+	}
+
+	details := uitable.New()
+	details.Wrap = true
+	details.AddRow("Name:", o.Name)
+	details.AddRow("Version:", o.Version)
+	details.AddRow("App Version:", o.AppVersion)
+	details.AddRow("Description:", o.Description)
+	details.AddRow("KUDO Version:", o.KUDOVersion)
+	details.AddRow("Kubernetes Version:", o.KubernetesVersion)
+	details.AddRow("URL:", o.URL)
+	details.AddRow("Maintainers:", strings.Join(maintainers, ", "))
+	details.AddRow("Repository:", client.Config.Name)
+	details.AddRow("Available Versions:", strings.Join(versions, ", "))
+	fmt.Fprintln(i.out, details)
+
+	params := pf.Params
+	sort.Slice(params, func(x, y int) bool {
+		return params[x].Name < params[y].Name
+	})
+	fmt.Fprintln(i.out, "\nParameters:")
+	paramTable := uitable.New()
+	paramTable.MaxColWidth = 80
+	paramTable.AddRow("NAME", "DEFAULT", "REQUIRED", "DESCRIPTION")
+	for _, p := range params {
+		def := ""
+		if p.Default != nil {
+			def = *p.Default
+		}
+		paramTable.AddRow(p.Name, def, p.Required && p.Default == nil, p.Description)
+	}
+	fmt.Fprintln(i.out, paramTable)
+
+	planNames := make([]string, 0, len(o.Plans))
+	for name := range o.Plans {
+		planNames = append(planNames, name)
+	}
+	sort.Strings(planNames)
+	fmt.Fprintln(i.out, "\nPlans:")
+	tree := treeprint.New()
+	for _, name := range planNames {
+		plan := o.Plans[name]
+		planBranch := tree.AddBranch(fmt.Sprintf("%s (%s strategy)", name, plan.Strategy))
+		for _, phase := range plan.Phases {
+			phaseBranch := planBranch.AddBranch(fmt.Sprintf("%s (%s strategy)", phase.Name, phase.Strategy))
+			for _, step := range phase.Steps {
+				phaseBranch.AddBranch(fmt.Sprintf("%s: %s", step.Name, strings.Join(step.Tasks, ", ")))
+			}
+		}
+	}
+	fmt.Fprint(i.out, tree.String())
+	return nil
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kudobuilder/kudo/pkg/kudoctl/bundle"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/files"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/util/repo"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInfo(t *testing.T) {
+	// package the zookeeper test operator and serve it together with an index
+	pkgFs := afero.NewMemMapFs()
+	files.CopyOperatorToFs(pkgFs, "../bundle/testdata/zk", "/opt")
+	tarfile, err := bundle.ToTarBundle(pkgFs, "/opt/zk", "/opt", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tgz, err := afero.ReadFile(pkgFs, tarfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest, _ := files.Sha256Sum(bytes.NewReader(tgz))
+	index := `apiVersion: v1
+entries:
+  zookeeper:
+  - name: zookeeper
+    version: 0.1.0
+    digest: ` + digest + `
+  - name: zookeeper
+    version: 0.0.1
+`
+	mux := http.NewServeMux()
+	mux.HandleFunc("/index.yaml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, index)
+	})
+	mux.HandleFunc("/zookeeper-0.1.0.tgz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(tgz)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	fs := afero.NewMemMapFs()
+	out := &bytes.Buffer{}
+	info := &infoCmd{name: "zookeeper", home: newTestRepoHome(t, fs, server.URL), out: out, fs: fs}
+	err = info.run()
+	assert.NoError(t, err)
+
+	for _, s := range []string{
+		"3.4.10",       // app version
+		"1.15.0",       // kubernetes version
+		"0.1.0, 0.0.1", // available versions
+		"Ken Sipe",     // maintainer
+		"memory",       // parameter
+		"1Gi",          // parameter default
+		"deploy (serial strategy)",
+		"everything: infra, app",
+	} {
+		assert.Contains(t, out.String(), s)
+	}
+
+	info = &infoCmd{name: "kafka", home: newTestRepoHome(t, fs, server.URL), out: out, fs: fs}
+	assert.EqualError(t, info.run(), "operator kafka not found in any repository")
+
+	info = &infoCmd{name: "kafka", repoName: "test", home: newTestRepoHome(t, fs, server.URL), out: out, fs: fs}
+	assert.EqualError(t, info.run(), "no operator found for: kafka")
+
+	// the operator is resolved by priority like install does, not by the context
+	home := newTestRepoHome(t, fs, server.URL)
+	repos := &repo.Repositories{
+		RepoVersion:  repo.Version,
+		Context:      "test",
+		Repositories: []*repo.Configuration{{Name: "test", URL: server.URL}, {Name: "internal", URL: server.URL, Priority: 10}},
+	}
+	if err := repos.WriteFile(fs, home.RepositoryFile(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	info = &infoCmd{name: "zookeeper", home: home, out: out, fs: fs}
+	assert.NoError(t, info.run())
+	assert.Regexp(t, `Repository:\s+internal`, out.String())
+}
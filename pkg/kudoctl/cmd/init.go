@@ -29,21 +29,33 @@ When installing KUDO, 'kudo init' will attempt to install the latest released
 version. You can specify an alternative image with '--kudo-image' which is the fully qualified image name replacement 
 or '--version' which will replace the version designation on the standard image.
 
+The KUDO manager runs as a Deployment whose replicas elect a leader which executes the plans. Use '--replicas'
+to run more than one replica.
+
+To install KUDO for a tenant, use '--namespace-scoped' with '--watch-namespaces'. The KUDO manager then only watches
+the given namespaces and is granted access to its own and those namespaces by Roles instead of a ClusterRoleBinding.
+Use '--kudo-namespace' to install the KUDO manager of each tenant into its own namespace, which also holds its leader
+election lock. The CRDs of KUDO are cluster wide and still require a cluster administrator to install them.
+
 To dump a manifest containing the KUDO deployment YAML, combine the '--dry-run' and '--output=yaml' flags.
 `
 
 type initCmd struct {
-	out        io.Writer
-	fs         afero.Fs
-	image      string
-	dryRun     bool
-	output     string
-	version    string
-	wait       bool
-	timeout    int64
-	clientOnly bool
-	home       kudohome.Home
-	client     *kube.Client
+	out             io.Writer
+	fs              afero.Fs
+	image           string
+	dryRun          bool
+	output          string
+	version         string
+	wait            bool
+	timeout         int64
+	clientOnly      bool
+	replicas        int32
+	kudoNamespace   string
+	namespaceScoped bool
+	watchNamespaces []string
+	home            kudohome.Home
+	client          *kube.Client
 }
 
 func newInitCmd(fs afero.Fs, out io.Writer) *cobra.Command {
@@ -73,6 +85,10 @@ func newInitCmd(fs afero.Fs, out io.Writer) *cobra.Command {
 	f.BoolVar(&i.dryRun, "dry-run", false, "Do not install local or remote")
 	f.BoolVarP(&i.wait, "wait", "w", false, "Block until KUDO manager is running and ready to receive requests")
 	f.Int64Var(&i.timeout, "wait-timeout", 300, "Wait timeout to be used")
+	f.Int32Var(&i.replicas, "replicas", 1, "Number of replicas of the KUDO manager, they elect a leader which executes the plans")
+	f.StringVar(&i.kudoNamespace, "kudo-namespace", "kudo-system", "Namespace the KUDO manager is installed into")
+	f.BoolVar(&i.namespaceScoped, "namespace-scoped", false, "Grant the KUDO manager access to its own and the watched namespaces only instead of the cluster")
+	f.StringSliceVar(&i.watchNamespaces, "watch-namespaces", nil, "Namespaces the KUDO manager watches, defaults to all namespaces")
 
 	return cmd
 }
@@ -82,6 +98,15 @@ func (initCmd *initCmd) validate() error {
 	if initCmd.image != "" && initCmd.version != "" {
 		return errors.New("specify either 'kudo-image' or 'version', not both")
 	}
+	if initCmd.replicas < 1 {
+		return errors.New("'replicas' has to be at least 1")
+	}
+	if initCmd.kudoNamespace == "" {
+		return errors.New("'kudo-namespace' can not be empty")
+	}
+	if initCmd.namespaceScoped && len(initCmd.watchNamespaces) == 0 {
+		return errors.New("'namespace-scoped' requires 'watch-namespaces'")
+	}
 	return nil
 }
 
@@ -92,6 +117,12 @@ func (initCmd *initCmd) run() error {
 	if initCmd.image != "" {
 		opts.Image = initCmd.image
 	}
+	opts.Replicas = initCmd.replicas
+	if initCmd.kudoNamespace != "" {
+		opts.Namespace = initCmd.kudoNamespace
+	}
+	opts.NamespaceScoped = initCmd.namespaceScoped
+	opts.WatchNamespaces = initCmd.watchNamespaces
 
 	//TODO: implement output=yaml|json (define a type for output to constrain)
 	//define an Encoder to replace YAMLWriter
@@ -204,6 +235,7 @@ func ensureDirectories(fs afero.Fs, home kudohome.Home, out io.Writer) error {
 	dirs := []string{
 		home.String(),
 		home.Repository(),
+		home.Cache(),
 	}
 	for _, dir := range dirs {
 		exists, err := afero.Exists(fs, dir)
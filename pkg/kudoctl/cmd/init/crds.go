@@ -204,6 +204,21 @@ func generateInstance() *apiextv1beta1.CustomResourceDefinition {
 	statusProps := map[string]apiextv1beta1.JSONSchemaProps{
 		"activePlan": apiextv1beta1.JSONSchemaProps{Type: "object"},
 		"status":     apiextv1beta1.JSONSchemaProps{Type: "string"},
+		"conditions": apiextv1beta1.JSONSchemaProps{
+			Type:        "array",
+			Description: "Conditions are the latest observations of the instance",
+			Items: &apiextv1beta1.JSONSchemaPropsOrArray{Schema: &apiextv1beta1.JSONSchemaProps{
+				Type:     "object",
+				Required: []string{"type", "status"},
+				Properties: map[string]apiextv1beta1.JSONSchemaProps{
+					"type":               apiextv1beta1.JSONSchemaProps{Type: "string"},
+					"status":             apiextv1beta1.JSONSchemaProps{Type: "string"},
+					"lastTransitionTime": apiextv1beta1.JSONSchemaProps{Type: "string", Format: "date-time"},
+					"reason":             apiextv1beta1.JSONSchemaProps{Type: "string"},
+					"message":            apiextv1beta1.JSONSchemaProps{Type: "string"},
+				},
+			}, JSONSchemas: []apiextv1beta1.JSONSchemaProps{}},
+		},
 	}
 
 	validationProps := map[string]apiextv1beta1.JSONSchemaProps{
@@ -2,19 +2,21 @@ package init
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/kudobuilder/kudo/pkg/kudoctl/kube"
 	"github.com/kudobuilder/kudo/pkg/version"
 
-	"k8s.io/api/apps/v1beta2"
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes"
-	clientv1beta2 "k8s.io/client-go/kubernetes/typed/apps/v1beta2"
+	clientappsv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"sigs.k8s.io/yaml"
 )
@@ -26,6 +28,8 @@ const (
 	crdVersion         = "v1alpha1"
 	defaultns          = "kudo-system"
 	defaultGracePeriod = 10
+	defaultReplicas    = 1
+	healthPort         = 8081
 )
 
 // Options is the configurable options to init
@@ -38,6 +42,12 @@ type Options struct {
 	TerminationGracePeriodSeconds int64
 	// Image defines the image to be used
 	Image string
+	// Replicas is the number of replicas of the manager, they elect a leader which executes the plans
+	Replicas int32
+	// WatchNamespaces restricts the manager to watch objects in these namespaces, empty for all namespaces
+	WatchNamespaces []string
+	// NamespaceScoped grants the manager access to its own and the watched namespaces only, instead of the cluster
+	NamespaceScoped bool
 }
 
 // NewOptions provides an option struct with defaults
@@ -52,6 +62,7 @@ func NewOptions(v string) Options {
 		Namespace:                     defaultns,
 		TerminationGracePeriodSeconds: defaultGracePeriod,
 		Image:                         fmt.Sprintf("kudobuilder/controller:v%v", v),
+		Replicas:                      defaultReplicas,
 	}
 }
 
@@ -61,7 +72,10 @@ func Install(client *kube.Client, opts Options) error {
 		return err
 	}
 	if err := installCrds(client.ExtClient); err != nil {
-		return err
+		// several namespace scoped installations share the CRDs
+		if !opts.NamespaceScoped || !apierrors.IsAlreadyExists(err) {
+			return err
+		}
 	}
 	if err := installManager(client.KubeClient, opts); err != nil {
 		return err
@@ -71,7 +85,7 @@ func Install(client *kube.Client, opts Options) error {
 
 // Install uses Kubernetes client to install KUDO.
 func installManager(client kubernetes.Interface, opts Options) error {
-	if err := installStatefulSet(client.AppsV1beta2(), opts); err != nil {
+	if err := installDeployment(client.AppsV1(), opts); err != nil {
 		return err
 	}
 
@@ -81,9 +95,9 @@ func installManager(client kubernetes.Interface, opts Options) error {
 	return nil
 }
 
-func installStatefulSet(client clientv1beta2.StatefulSetsGetter, opts Options) error {
-	ss := generateDeployment(opts)
-	_, err := client.StatefulSets(opts.Namespace).Create(ss)
+func installDeployment(client clientappsv1.DeploymentsGetter, opts Options) error {
+	d := generateDeployment(opts)
+	_, err := client.Deployments(opts.Namespace).Create(d)
 	return err
 }
 
@@ -113,11 +127,11 @@ func ManagerManifests(opts Options) ([]string, error) {
 }
 
 // managerDeployment provides the KUDO manager deployment manifest for printing
-func managerDeployment(opts Options) *v1beta2.StatefulSet {
+func managerDeployment(opts Options) *appsv1.Deployment {
 	dep := generateDeployment(opts)
 
 	dep.TypeMeta = metav1.TypeMeta{
-		Kind:       "StatefulSet",
+		Kind:       "Deployment",
 		APIVersion: "apps/v1",
 	}
 	return dep
@@ -133,21 +147,23 @@ func managerService(opts Options) *v1.Service {
 	return svc
 }
 
-func generateDeployment(opts Options) *v1beta2.StatefulSet {
+// generateDeployment generates the deployment of the manager, the replicas always elect a leader as a rolling update
+// runs an old and a new replica side by side
+func generateDeployment(opts Options) *appsv1.Deployment {
 
 	labels := managerLabels()
 
 	secretDefaultMode := int32(420)
 	image := opts.Image
-	d := &v1beta2.StatefulSet{
+	d := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: opts.Namespace,
 			Name:      "kudo-controller-manager",
 			Labels:    labels,
 		},
-		Spec: v1beta2.StatefulSetSpec{
-			Selector:    &metav1.LabelSelector{MatchLabels: labels},
-			ServiceName: "kudo-controller-manager-service",
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &opts.Replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
 			Template: v1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels: labels,
@@ -157,6 +173,7 @@ func generateDeployment(opts Options) *v1beta2.StatefulSet {
 					Containers: []v1.Container{
 						{
 							Command: []string{"/root/manager"},
+							Args:    managerArgs(opts),
 							Env: []v1.EnvVar{
 								{Name: "POD_NAMESPACE", ValueFrom: &v1.EnvVarSource{FieldRef: &v1.ObjectFieldSelector{FieldPath: "metadata.namespace"}}},
 								{Name: "SECRET_NAME", Value: "kudo-webhook-server-secret"},
@@ -167,6 +184,14 @@ func generateDeployment(opts Options) *v1beta2.StatefulSet {
 							Ports: []v1.ContainerPort{
 								// name matters for service
 								{ContainerPort: 9876, Name: "webhook-server", Protocol: "TCP"},
+								{ContainerPort: 8080, Name: "metrics", Protocol: "TCP"},
+								{ContainerPort: healthPort, Name: "healthz", Protocol: "TCP"},
+							},
+							LivenessProbe: &v1.Probe{
+								Handler: v1.Handler{HTTPGet: &v1.HTTPGetAction{Path: "/healthz", Port: intstr.FromString("healthz")}},
+							},
+							ReadinessProbe: &v1.Probe{
+								Handler: v1.Handler{HTTPGet: &v1.HTTPGetAction{Path: "/readyz", Port: intstr.FromString("healthz")}},
 							},
 							Resources: v1.ResourceRequirements{
 								Requests: v1.ResourceList{
@@ -190,6 +215,19 @@ func generateDeployment(opts Options) *v1beta2.StatefulSet {
 	return d
 }
 
+// managerArgs returns the arguments of the manager
+func managerArgs(opts Options) []string {
+	args := []string{"--enable-leader-election"}
+	if opts.NamespaceScoped {
+		// the role of a namespace scoped manager only grants access to the lock in its own namespace
+		args = append(args, "--leader-election-namespace="+opts.Namespace)
+	}
+	if len(opts.WatchNamespaces) > 0 {
+		args = append(args, "--watch-namespaces="+strings.Join(opts.WatchNamespaces, ","))
+	}
+	return args
+}
+
 func managerLabels() labels.Set {
 	labels := generateLabels(map[string]string{"control-plane": "controller-manager", "controller-tools.k8s.io": "1.0"})
 	return labels
@@ -209,6 +247,10 @@ func generateService(opts Options) *v1.Service {
 					Name:       "kudo",
 					Port:       443,
 					TargetPort: intstr.FromString("webhook-server")},
+				{
+					Name:       "metrics",
+					Port:       8080,
+					TargetPort: intstr.FromString("metrics")},
 			},
 			Selector: labels,
 		},
@@ -0,0 +1,49 @@
+package init
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestInstallNamespaceScopedSideBySide(t *testing.T) {
+	client := fake.NewSimpleClientset()
+
+	tenants := map[string][]string{
+		"kudo-tenant-a": {"a"},
+		"kudo-tenant-b": {"b", "c"},
+	}
+	for ns, watched := range tenants {
+		opts := NewOptions("0.8.0")
+		opts.Namespace = ns
+		opts.NamespaceScoped = true
+		opts.WatchNamespaces = watched
+
+		assert.NoError(t, installPrereqs(client, opts), ns)
+		assert.NoError(t, installManager(client, opts), ns)
+	}
+
+	for ns, watched := range tenants {
+		d, err := client.AppsV1().Deployments(ns).Get("kudo-controller-manager", metav1.GetOptions{})
+		if !assert.NoError(t, err, ns) {
+			continue
+		}
+		assert.Contains(t, d.Spec.Template.Spec.Containers[0].Args, "--leader-election-namespace="+ns)
+		assert.Equal(t, "kudo-manager", d.Spec.Template.Spec.ServiceAccountName)
+
+		// the roles of each tenant are bound to the service account in its own namespace
+		for _, namespace := range append([]string{ns}, watched...) {
+			rb, err := client.RbacV1().RoleBindings(namespace).Get("kudo-manager-rolebinding", metav1.GetOptions{})
+			if !assert.NoError(t, err, namespace) {
+				continue
+			}
+			assert.Equal(t, ns, rb.Subjects[0].Namespace)
+		}
+	}
+
+	crbs, err := client.RbacV1().ClusterRoleBindings().List(metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, crbs.Items)
+}
@@ -37,6 +37,17 @@ func installSecret(client corev1.SecretsGetter, opts Options) error {
 }
 
 func installRoleBindings(client kubernetes.Interface, opts Options) error {
+	if opts.NamespaceScoped {
+		for _, ns := range scopedNamespaces(opts) {
+			if _, err := client.RbacV1().Roles(ns).Create(generateNamespacedRole(ns)); err != nil {
+				return err
+			}
+			if _, err := client.RbacV1().RoleBindings(ns).Create(generateNamespacedRoleBinding(opts, ns)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
 	rbac := generateRoleBinding(opts)
 	_, err := client.RbacV1().ClusterRoleBindings().Create(rbac)
 	return err
@@ -101,6 +112,57 @@ func generateRoleBinding(opts Options) *rbacv1.ClusterRoleBinding {
 	return sa
 }
 
+// scopedNamespaces returns the namespaces a namespace scoped manager needs access to: its own namespace for leader
+// election and the watched namespaces
+func scopedNamespaces(opts Options) []string {
+	namespaces := []string{opts.Namespace}
+	for _, ns := range opts.WatchNamespaces {
+		if ns != opts.Namespace {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces
+}
+
+// generateNamespacedRole builds the role granting a namespace scoped manager full access to a namespace
+func generateNamespacedRole(namespace string) *rbacv1.Role {
+	role := &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kudo-manager-role",
+			Namespace: namespace,
+			Labels:    generateLabels(map[string]string{}),
+		},
+		Rules: []rbacv1.PolicyRule{{
+			APIGroups: []string{"*"},
+			Resources: []string{"*"},
+			Verbs:     []string{"*"},
+		}},
+	}
+	return role
+}
+
+// generateNamespacedRoleBinding builds the role binding of the role of a namespace scoped manager
+func generateNamespacedRoleBinding(opts Options, namespace string) *rbacv1.RoleBinding {
+	rb := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kudo-manager-rolebinding",
+			Namespace: namespace,
+			Labels:    generateLabels(map[string]string{}),
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: "rbac.authorization.k8s.io",
+			Kind:     "Role",
+			Name:     "kudo-manager-role",
+		},
+		Subjects: []rbacv1.Subject{{
+			Kind:      "ServiceAccount",
+			Name:      "kudo-manager",
+			Namespace: opts.Namespace,
+		}},
+	}
+	return rb
+}
+
 // generateWebHookSecret builds the secret object used for webhooks
 func generateWebHookSecret(opts Options) *v1.Secret {
 	secret := &v1.Secret{
@@ -138,12 +200,40 @@ func PrereqManifests(opts Options) ([]string, error) {
 func Prereq(opts Options) []runtime.Object {
 	ns := namespace(opts.Namespace)
 	svc := serviceAccount(opts)
-	rbac := roleBinding(opts)
 	secret := webhookSecret(opts)
 
+	if opts.NamespaceScoped {
+		objs := []runtime.Object{ns, svc}
+		for _, namespace := range scopedNamespaces(opts) {
+			objs = append(objs, namespacedRole(namespace), namespacedRoleBinding(opts, namespace))
+		}
+		return append(objs, secret)
+	}
+
+	rbac := roleBinding(opts)
 	return []runtime.Object{ns, svc, rbac, secret}
 }
 
+// namespacedRole provides the role rbac manifest of a namespace scoped manager for printing
+func namespacedRole(namespace string) *rbacv1.Role {
+	role := generateNamespacedRole(namespace)
+	role.TypeMeta = metav1.TypeMeta{
+		Kind:       "Role",
+		APIVersion: "rbac.authorization.k8s.io/v1",
+	}
+	return role
+}
+
+// namespacedRoleBinding provides the role binding rbac manifest of a namespace scoped manager for printing
+func namespacedRoleBinding(opts Options, namespace string) *rbacv1.RoleBinding {
+	rb := generateNamespacedRoleBinding(opts, namespace)
+	rb.TypeMeta = metav1.TypeMeta{
+		Kind:       "RoleBinding",
+		APIVersion: "rbac.authorization.k8s.io/v1",
+	}
+	return rb
+}
+
 // roleBinding provides the roleBinding rbac manifest for printing
 func roleBinding(opts Options) *rbacv1.ClusterRoleBinding {
 	rbac := generateRoleBinding(opts)
@@ -143,6 +143,26 @@ func TestInitCmd_YAMLWriter(t *testing.T) {
 	}
 }
 
+func TestInitCmd_namespaceScoped(t *testing.T) {
+	out := &bytes.Buffer{}
+	initCmd := newInitCmd(afero.NewMemMapFs(), out)
+	flags := map[string]string{"dry-run": "true", "output": "yaml", "namespace-scoped": "true", "watch-namespaces": "a,b", "kudo-namespace": "kudo-tenant"}
+	for flag, value := range flags {
+		initCmd.Flags().Set(flag, value)
+	}
+	assert.NoError(t, initCmd.RunE(initCmd, []string{}))
+
+	manifests := out.String()
+	assert.NotContains(t, manifests, "kind: ClusterRoleBinding")
+	for _, ns := range []string{"kudo-tenant", "a", "b"} {
+		assert.Contains(t, manifests, "kind: Role\nmetadata:\n  creationTimestamp: null\n  labels:\n    app: kudo-manager\n  name: kudo-manager-role\n  namespace: "+ns+"\n")
+		assert.Contains(t, manifests, "kind: RoleBinding\nmetadata:\n  creationTimestamp: null\n  labels:\n    app: kudo-manager\n  name: kudo-manager-rolebinding\n  namespace: "+ns+"\n")
+	}
+	assert.Contains(t, manifests, "- --watch-namespaces=a,b\n")
+	assert.Contains(t, manifests, "- --leader-election-namespace=kudo-tenant\n")
+	assert.NotContains(t, manifests, "kudo-system")
+}
+
 func TestNewInitCmd(t *testing.T) {
 	fs := afero.NewMemMapFs()
 	var tests = []struct {
@@ -153,6 +173,9 @@ func TestNewInitCmd(t *testing.T) {
 	}{
 		{name: "arguments invalid", parameters: []string{"foo"}, errorMessage: "this command does not accept arguments"},
 		{name: "name and version together invalid", flags: map[string]string{"kudo-image": "foo", "version": "bar"}, errorMessage: "specify either 'kudo-image' or 'version', not both"},
+		{name: "no replicas invalid", flags: map[string]string{"replicas": "0"}, errorMessage: "'replicas' has to be at least 1"},
+		{name: "empty kudo namespace invalid", flags: map[string]string{"kudo-namespace": ""}, errorMessage: "'kudo-namespace' can not be empty"},
+		{name: "namespace scoped without namespaces invalid", flags: map[string]string{"namespace-scoped": "true"}, errorMessage: "'namespace-scoped' requires 'watch-namespaces'"},
 	}
 
 	for _, tt := range tests {
@@ -185,7 +208,7 @@ func TestClientInitialize(t *testing.T) {
 		t.Error(err)
 	}
 
-	expectedDirs := []string{hh.String(), hh.Repository()}
+	expectedDirs := []string{hh.String(), hh.Repository(), hh.Cache()}
 	for _, dir := range expectedDirs {
 		if fi, err := fs.Stat(dir); err != nil {
 			t.Errorf("%s", err)
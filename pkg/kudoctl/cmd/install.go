@@ -11,7 +11,7 @@ import (
 var (
 	installExample = `
 		The install argument must be a name of the package in the repository, a path to package in *.tgz format,
-		or a path to an unpacked package directory.
+		a path to an unpacked package directory or a reference to a package in an OCI registry.
 
 		# Install the most recent Flink package to your cluster.
 		kubectl kudo install flink
@@ -27,14 +27,37 @@ var (
 		# Install operator from tarball at URL
 		kubectl kudo install http://kudo.dev/zk.tgz
 
+		# Install operator from an OCI registry, the package was pushed with 'kudo package push'
+		kubectl kudo install oci://registry.example.com/operators/zookeeper:0.1.0
+
 		# Specify a package version of Kafka to install to your cluster.
-		kubectl kudo install kafka --version=1.1.1`
+		kubectl kudo install kafka --version=1.1.1
+
+		# Install the most recent Kafka package matching a semver constraint
+		kubectl kudo install kafka --version='>=1.0 <2.0'
+
+		# Install Kafka from the local cache without contacting the repository
+		kubectl kudo install kafka --offline
+
+		# Install Kafka with parameters from a file and a parameter value read from another file
+		kubectl kudo install kafka --parameter-file values.yaml -p SERVER_PROPERTIES=@server.properties
+
+		# Install Kafka from an OCI registry which requires authentication
+		cat password.txt | kubectl kudo install oci://registry.example.com/operators/kafka:1.0.0 --username admin --password-stdin
+
+		# Install Kafka with parameters read from stdin
+		cat values.yaml | kubectl kudo install kafka --parameter-file -
+
+		# Install Kafka and block until its deploy plan is complete
+		kubectl kudo install kafka --wait --wait-timeout 600`
 )
 
 // newInstallCmd creates the install command for the CLI
 func newInstallCmd(fs afero.Fs) *cobra.Command {
 	options := install.DefaultOptions
 	var parameters []string
+	var parameterFiles []string
+	var registry registryFlags
 	installCmd := &cobra.Command{
 		Use:     "install <name>",
 		Short:   "Install an official KUDO package.",
@@ -43,19 +66,31 @@ func newInstallCmd(fs afero.Fs) *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Prior to command execution we parse and validate passed arguments
 			var err error
-			options.Parameters, err = install.GetParameterMap(parameters)
+			options.Parameters, err = install.GetParameterMap(fs, cmd.InOrStdin(), parameters, parameterFiles)
 			if err != nil {
 				return errors.WithMessage(err, "could not parse arguments")
 			}
+			if registry.passwordStdin && containsStdin(parameterFiles) {
+				return errors.New("--password-stdin and --parameter-file - can not be used together")
+			}
+			options.Registry, err = registry.options(cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
 
 			return install.Run(args, options, fs, &Settings)
 		},
 	}
 
 	installCmd.Flags().StringVar(&options.InstanceName, "instance", "", "The instance name. (default to Operator name)")
-	installCmd.Flags().StringArrayVarP(&parameters, "parameter", "p", nil, "The parameter name and value separated by '='")
-	installCmd.Flags().StringVarP(&options.PackageVersion, "version", "v", "", "A specific package version on the official GitHub repo. (default to the most recent)")
-	installCmd.Flags().StringVar(&options.RepoName, "repo", "", "Name of repository configuration to use. (default defined by context)")
+	installCmd.Flags().StringArrayVarP(&parameters, "parameter", "p", nil, "The parameter name and value separated by '='. Use '@' to read the value from a file, e.g. key=@path/to/file")
+	installCmd.Flags().StringArrayVar(&parameterFiles, "parameter-file", nil, "A YAML file with parameter names and values, - reads the file from stdin. Later files override earlier ones, -p overrides all files")
+	installCmd.Flags().StringVarP(&options.PackageVersion, "version", "v", "", "A specific package version or a semver constraint like '~2.3' or '>=1.0 <2.0' on the official GitHub repo. (default to the most recent)")
+	installCmd.Flags().StringVar(&options.RepoName, "repo", "", "Name of repository configuration to use. (default resolves the operator across all repositories by priority)")
+	installCmd.Flags().BoolVar(&options.Offline, "offline", false, "Only use the locally cached repository index files and packages. Run 'kudo repo update' to refresh the cache.")
+	registry.addFlags(installCmd.Flags())
 	installCmd.Flags().BoolVar(&options.SkipInstance, "skip-instance", false, "If set, install will install the Operator and OperatorVersion, but not an instance. (default \"false\")")
+	installCmd.Flags().BoolVar(&options.Wait, "wait", false, "Block until the deploy plan of the instance is complete. Fails if the plan ends in ERROR.")
+	installCmd.Flags().Int64Var(&options.WaitTimeout, "wait-timeout", install.DefaultWaitTimeout, "Wait timeout in seconds to be used with --wait")
 	return installCmd
 }
@@ -10,6 +10,8 @@ import (
 	"github.com/kudobuilder/kudo/pkg/kudoctl/bundle/finder"
 	"github.com/kudobuilder/kudo/pkg/kudoctl/env"
 	"github.com/kudobuilder/kudo/pkg/kudoctl/http"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/kudohome"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/oci"
 	"github.com/kudobuilder/kudo/pkg/kudoctl/util/kudo"
 	"github.com/kudobuilder/kudo/pkg/kudoctl/util/repo"
 
@@ -20,11 +22,30 @@ import (
 // RepositoryOptions defines the options necessary for any cmd working with repository
 type RepositoryOptions struct {
 	RepoName string
+	// Offline restricts the repository to the locally cached index files and packages
+	Offline bool
+	// Registry configures the access to OCI registries for packages referenced as oci://...
+	Registry oci.Options
+}
+
+// NewRepository returns the repository named in the options or, if none is named, a repository resolving
+// operators across all configured repositories
+func NewRepository(fs afero.Fs, home kudohome.Home, options RepositoryOptions) (repo.Repository, error) {
+	if options.RepoName == "" {
+		return repo.ResolverFromSettings(fs, home, options.Offline)
+	}
+	repository, err := repo.ClientFromSettings(fs, home, options.RepoName)
+	if err != nil {
+		return nil, err
+	}
+	repository.Offline = options.Offline
+	return repository, nil
 }
 
 // Options defines configuration options for the install command
 type Options struct {
 	RepositoryOptions
+	WaitOptions
 	InstanceName   string
 	Parameters     map[string]string
 	PackageVersion string
@@ -50,6 +71,9 @@ func validate(args []string, options *Options) error {
 	if len(args) != 1 {
 		return fmt.Errorf("expecting exactly one argument - name of the package or path to install")
 	}
+	if options.Wait && options.SkipInstance {
+		return fmt.Errorf("--wait can not be used together with --skip-instance as no plan is executed")
+	}
 
 	return nil
 }
@@ -57,11 +81,12 @@ func validate(args []string, options *Options) error {
 // GetPackageCRDs tries to look for package files resolving the operator name to:
 // - a local tgz file
 // - a local directory
+// - a package in an OCI registry, e.g. oci://registry.example.com/operators/kafka:1.0.0
 // - a url to a tgz
 // - an operator name in the remote repository
 // in that order. Should there exist a local folder e.g. `cassandra` it will take precedence
 // over the remote repository package with the same name.
-func GetPackageCRDs(name string, version string, repository repo.Repository) (*bundle.PackageCRDs, error) {
+func GetPackageCRDs(name string, version string, repository repo.Repository, registry oci.Options) (*bundle.PackageCRDs, error) {
 
 	// Local files/folder have priority
 	if _, err := os.Stat(name); err == nil {
@@ -73,6 +98,19 @@ func GetPackageCRDs(name string, version string, repository repo.Repository) (*b
 		return b.GetCRDs()
 	}
 
+	// OCI references are valid URLs too and have to be checked first
+	if oci.IsReference(name) {
+		f, err := finder.NewOCI(registry)
+		if err != nil {
+			return nil, err
+		}
+		b, err := f.GetBundle(name, version)
+		if err != nil {
+			return nil, err
+		}
+		return b.GetCRDs()
+	}
+
 	if http.IsValidURL(name) {
 		f := finder.NewURL()
 		b, err := f.GetBundle(name, version)
@@ -92,7 +130,7 @@ func GetPackageCRDs(name string, version string, repository repo.Repository) (*b
 // installOperator is installing single operator into cluster and returns error in case of error
 func installOperator(operatorArgument string, options *Options, fs afero.Fs, settings *env.Settings) error {
 
-	repository, err := repo.ClientFromSettings(fs, settings.Home, options.RepoName)
+	repository, err := NewRepository(fs, settings.Home, options.RepositoryOptions)
 	if err != nil {
 		return errors.WithMessage(err, "could not build operator repository")
 	}
@@ -102,7 +140,7 @@ func installOperator(operatorArgument string, options *Options, fs afero.Fs, set
 		return errors.Wrap(err, "creating kudo client")
 	}
 
-	crds, err := GetPackageCRDs(operatorArgument, options.PackageVersion, repository)
+	crds, err := GetPackageCRDs(operatorArgument, options.PackageVersion, repository, options.Registry)
 	if err != nil {
 		return errors.Wrapf(err, "failed to resolve package CRDs for operator: %s", operatorArgument)
 	}
@@ -171,6 +209,10 @@ func installCrds(crds *bundle.PackageCRDs, kc *kudo.Client, options *Options, se
 		return fmt.Errorf("can not install instance '%s' of operator '%s-%s' because instance of that name already exists in namespace %s",
 			instanceName, operatorName, crds.OperatorVersion.Spec.Version, settings.Namespace)
 	}
+
+	if options.Wait {
+		return WaitForPlan(kc, instanceName, settings.Namespace, "", options.WaitOptions)
+	}
 	return nil
 }
 
@@ -193,7 +235,7 @@ func validateCrds(crds *bundle.PackageCRDs, skipInstance bool) error {
 	if len(missingParameters) > 0 {
 		return fmt.Errorf("missing required parameters during installation: %s", strings.Join(missingParameters, ","))
 	}
-	return nil
+	return ValidateParameters(crds.Instance.Spec.Parameters, crds.OperatorVersion)
 }
 
 // VersionExists looks for string version inside collection of versions
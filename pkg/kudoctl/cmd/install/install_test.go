@@ -17,21 +17,23 @@ import (
 
 func TestValidate(t *testing.T) {
 
+	waitWithoutInstance := &Options{SkipInstance: true, WaitOptions: WaitOptions{Wait: true}}
+
 	tests := []struct {
-		arg []string
-		err string
+		arg     []string
+		options *Options
+		err     string
 	}{
-		{nil, "expecting exactly one argument - name of the package or path to install"},                     // 1
-		{[]string{"arg", "arg2"}, "expecting exactly one argument - name of the package or path to install"}, // 2
-		{[]string{}, "expecting exactly one argument - name of the package or path to install"},              // 3
+		{nil, DefaultOptions, "expecting exactly one argument - name of the package or path to install"},                      // 1
+		{[]string{"arg", "arg2"}, DefaultOptions, "expecting exactly one argument - name of the package or path to install"},  // 2
+		{[]string{}, DefaultOptions, "expecting exactly one argument - name of the package or path to install"},               // 3
+		{[]string{"arg"}, waitWithoutInstance, "--wait can not be used together with --skip-instance as no plan is executed"}, // 4
 	}
 
 	for _, tt := range tests {
-		err := validate(tt.arg, DefaultOptions)
-		if err != nil {
-			if err.Error() != tt.err {
-				t.Errorf("Expecting error message '%s' but got '%s'", tt.err, err)
-			}
+		err := validate(tt.arg, tt.options)
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("Expecting error message '%s' but got '%v'", tt.err, err)
 		}
 	}
 }
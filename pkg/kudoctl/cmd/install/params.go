@@ -3,20 +3,53 @@ package install
 import (
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
 	"strings"
+
+	"github.com/kudobuilder/kudo/pkg/apis/kudo/v1alpha1"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v2"
 )
 
-// GetParameterMap takes a slice of parameter strings, parses parameters into a map of keys and values
-func GetParameterMap(raw []string) (map[string]string, error) {
+// stdinPath is the parameter file path which reads the parameters from stdin
+const stdinPath = "-"
+
+// GetParameterMap takes a list of parameter files and a slice of parameter strings and parses them into a map of keys
+// and values. Files are applied in the given order, so later files override earlier ones, and parameters passed as
+// strings override all files. The file path `-` reads parameters from in. A parameter value of the form `@path` is
+// replaced by the content of the file at path.
+func GetParameterMap(fs afero.Fs, in io.Reader, raw []string, filePaths []string) (map[string]string, error) {
 	var errs []string
 	parameters := make(map[string]string)
 
+	for _, path := range filePaths {
+		fileParameters, err := readParameterFile(fs, in, path)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		for key, value := range fileParameters {
+			parameters[key] = value
+		}
+	}
+
 	for _, a := range raw {
 		key, value, err := parseParameter(a)
 		if err != nil {
 			errs = append(errs, *err)
 			continue
 		}
+		if strings.HasPrefix(value, "@") {
+			content, err := afero.ReadFile(fs, strings.TrimPrefix(value, "@"))
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("reading value of parameter %s: %v", key, err))
+				continue
+			}
+			value = string(content)
+		}
 		parameters[key] = value
 	}
 
@@ -27,6 +60,65 @@ func GetParameterMap(raw []string) (map[string]string, error) {
 	return parameters, nil
 }
 
+// readParameterFile reads a YAML file mapping parameter names to values. Values have to be scalars and are kept as
+// written in the file, e.g. `1.10` stays `1.10`. Multi-line values can be provided as YAML block scalars.
+func readParameterFile(fs afero.Fs, in io.Reader, path string) (map[string]string, error) {
+	var content []byte
+	var err error
+	if path == stdinPath {
+		path = "stdin"
+		content, err = ioutil.ReadAll(in)
+	} else {
+		content, err = afero.ReadFile(fs, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading parameter file %s: %v", path, err)
+	}
+
+	// decode once to reject nested values with a clear message, as decoding a map or list into a string fails with a
+	// YAML type error
+	raw := map[string]interface{}{}
+	if err := yaml.Unmarshal(content, &raw); err != nil {
+		return nil, fmt.Errorf("parsing parameter file %s: %v", path, err)
+	}
+	for key, value := range raw {
+		switch value.(type) {
+		case map[interface{}]interface{}, []interface{}:
+			return nil, fmt.Errorf("parameter %s in parameter file %s has to be a string, number or boolean, not a map or list", key, path)
+		case nil:
+			return nil, fmt.Errorf("parameter value can not be empty: %s in parameter file %s", key, path)
+		}
+	}
+
+	// yaml.v2 decodes scalars into strings with their literal text
+	parameters := make(map[string]string, len(raw))
+	if err := yaml.Unmarshal(content, &parameters); err != nil {
+		return nil, fmt.Errorf("parsing parameter file %s: %v", path, err)
+	}
+	return parameters, nil
+}
+
+// ValidateParameters makes sure that every given parameter is defined by the OperatorVersion
+func ValidateParameters(parameters map[string]string, ov *v1alpha1.OperatorVersion) error {
+	defined := make(map[string]bool, len(ov.Spec.Parameters))
+	for _, p := range ov.Spec.Parameters {
+		defined[p.Name] = true
+	}
+
+	unknownParameters := []string{}
+	for name := range parameters {
+		if !defined[name] {
+			unknownParameters = append(unknownParameters, name)
+		}
+	}
+
+	if len(unknownParameters) > 0 {
+		sort.Strings(unknownParameters)
+		return fmt.Errorf("parameters not defined by operatorversion %s: %s", ov.Name, strings.Join(unknownParameters, ","))
+	}
+	return nil
+}
+
 // parseParameter does all the parsing logic for an instance of a parameter provided to the command line
 // it expects `=` as a delimiter as in key=value.  It separates keys from values as a return.   Any unexpected param will result in a
 // detailed error message.
@@ -1,9 +1,14 @@
 package install
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/kudobuilder/kudo/pkg/apis/kudo/v1alpha1"
+
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 var parameterParsingTests = []struct {
@@ -28,3 +33,64 @@ func TestTableParameterParsing(t *testing.T) {
 		}
 	}
 }
+
+func TestGetParameterMap(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	afero.WriteFile(fs, "base.yaml", []byte("replicas: 3\nname: base\nenabled: true\nconfig: |\n  a=1\n  b=2\n"), 0644)
+	afero.WriteFile(fs, "override.yaml", []byte("name: override\n"), 0644)
+	afero.WriteFile(fs, "invalid.yaml", []byte("nested:\n  key: value\n"), 0644)
+	afero.WriteFile(fs, "list.yaml", []byte("list:\n  - value\n"), 0644)
+	afero.WriteFile(fs, "empty.yaml", []byte("empty:\n"), 0644)
+	afero.WriteFile(fs, "literal.yaml", []byte("version: 1.10\nmemory: 1e3\nhex: 0x10\nenabled: yes\n"), 0644)
+	afero.WriteFile(fs, "server.properties", []byte("broker.id=1\n"), 0644)
+
+	tests := []struct {
+		name     string
+		raw      []string
+		files    []string
+		expected map[string]string
+		err      string
+	}{
+		{"parameter file", nil, []string{"base.yaml"}, map[string]string{"replicas": "3", "name": "base", "enabled": "true", "config": "a=1\nb=2\n"}, ""},
+		{"later files override earlier", nil, []string{"base.yaml", "override.yaml"}, map[string]string{"replicas": "3", "name": "override", "enabled": "true", "config": "a=1\nb=2\n"}, ""},
+		{"parameters override files", []string{"name=flag"}, []string{"base.yaml", "override.yaml"}, map[string]string{"replicas": "3", "name": "flag", "enabled": "true", "config": "a=1\nb=2\n"}, ""},
+		{"value from file", []string{"properties=@server.properties"}, nil, map[string]string{"properties": "broker.id=1\n"}, ""},
+		{"value from missing file", []string{"properties=@missing.properties"}, nil, nil, "reading value of parameter properties"},
+		{"missing parameter file", nil, []string{"missing.yaml"}, nil, "reading parameter file missing.yaml"},
+		{"literal values", nil, []string{"literal.yaml"}, map[string]string{"version": "1.10", "memory": "1e3", "hex": "0x10", "enabled": "yes"}, ""},
+		{"parameter file from stdin", nil, []string{"-"}, map[string]string{"name": "stdin", "replicas": "05"}, ""},
+		{"nested value in parameter file", nil, []string{"invalid.yaml"}, nil, "parameter nested in parameter file invalid.yaml has to be a string, number or boolean, not a map or list"},
+		{"list value in parameter file", nil, []string{"list.yaml"}, nil, "parameter list in parameter file list.yaml has to be a string, number or boolean, not a map or list"},
+		{"empty value in parameter file", nil, []string{"empty.yaml"}, nil, "parameter value can not be empty: empty in parameter file empty.yaml"},
+	}
+
+	for _, tt := range tests {
+		params, err := GetParameterMap(fs, strings.NewReader("name: stdin\nreplicas: 05\n"), tt.raw, tt.files)
+		if tt.err != "" {
+			assert.Error(t, err, tt.name)
+			if err != nil {
+				assert.Contains(t, err.Error(), tt.err, tt.name)
+			}
+			continue
+		}
+		assert.NoError(t, err, tt.name)
+		assert.Equal(t, tt.expected, params, tt.name)
+	}
+}
+
+func TestValidateParameters(t *testing.T) {
+	ov := &v1alpha1.OperatorVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-1.0"},
+		Spec: v1alpha1.OperatorVersionSpec{
+			Parameters: []v1alpha1.Parameter{{Name: "replicas"}, {Name: "name"}},
+		},
+	}
+
+	assert.NoError(t, ValidateParameters(map[string]string{"replicas": "3"}, ov))
+	assert.NoError(t, ValidateParameters(nil, ov))
+
+	err := ValidateParameters(map[string]string{"replicas": "3", "zzz": "1", "aaa": "2"}, ov)
+	if assert.Error(t, err) {
+		assert.Equal(t, "parameters not defined by operatorversion test-1.0: aaa,zzz", err.Error())
+	}
+}
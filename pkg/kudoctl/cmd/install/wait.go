@@ -0,0 +1,29 @@
+package install
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kudobuilder/kudo/pkg/kudoctl/util/kudo"
+)
+
+// WaitOptions defines the options necessary for any cmd waiting for a plan to finish
+type WaitOptions struct {
+	Wait        bool
+	WaitTimeout int64
+}
+
+// DefaultWaitTimeout is the default of the wait-timeout flag in seconds
+const DefaultWaitTimeout = 300
+
+// WaitForPlan blocks until the plan started on the instance after previousPlan is finished.
+// Returns an error naming the failing phase and step if the plan ends in ERROR or does not finish within the timeout.
+func WaitForPlan(kc *kudo.Client, instanceName, namespace, previousPlan string, options WaitOptions) error {
+	fmt.Printf("Waiting for the active plan of instance %s to finish...\n", instanceName)
+	pe, err := kc.WaitForPlan(instanceName, namespace, previousPlan, time.Duration(options.WaitTimeout)*time.Second)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("planexecution.%s/%s %s\n", pe.APIVersion, pe.Name, pe.Status.State)
+	return nil
+}
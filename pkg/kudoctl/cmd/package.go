@@ -17,13 +17,18 @@ const (
 		kubectl kudo package zookeeper
 
 		# Specify a destination folder other than current working directory
-		kubectl kudo package ../operators/repository/zookeeper/operator/ --destination=out-folder`
+		kubectl kudo package ../operators/repository/zookeeper/operator/ --destination=out-folder
+
+		# Sign the package with an ed25519 private key, e.g. created by 'openssl genpkey -algorithm ed25519'.
+		# The detached signature is written next to the package as <package>.tgz.sig
+		kubectl kudo package zookeeper --sign private-key.pem`
 )
 
 type packageCmd struct {
 	path        string
 	destination string
 	overwrite   bool
+	signingKey  string
 	out         io.Writer
 	fs          afero.Fs
 }
@@ -52,6 +57,13 @@ func newPackageCmd(fs afero.Fs, out io.Writer) *cobra.Command {
 	f := cmd.Flags()
 	f.StringVarP(&pkg.destination, "destination", "d", ".", "Location to write the package.")
 	f.BoolVarP(&pkg.overwrite, "overwrite", "o", false, "Overwrite existing package.")
+	f.StringVar(&pkg.signingKey, "sign", "", "Path to a PEM encoded ed25519 private key to create a detached signature of the package with.")
+
+	cmd.AddCommand(newPackagePushCmd(fs, out))
+	cmd.AddCommand(newPackageNewCmd(fs, out))
+	cmd.AddCommand(newPackageAddCmd(fs, out))
+	cmd.AddCommand(newPackageConvertDCOSCmd(fs, out))
+	cmd.AddCommand(newPackageRBACCmd(fs, out))
 	return cmd
 }
 
@@ -65,8 +77,22 @@ func validate(args []string) error {
 // run returns the errors associated with cmd env
 func (pkg *packageCmd) run() error {
 	tarfile, err := bundle.ToTarBundle(pkg.fs, pkg.path, pkg.destination, pkg.overwrite)
-	if err == nil {
-		fmt.Fprintf(pkg.out, "Package created: %v\n", tarfile)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(pkg.out, "Package created: %v\n", tarfile)
+
+	if pkg.signingKey == "" {
+		return nil
 	}
-	return err
+	key, err := afero.ReadFile(pkg.fs, pkg.signingKey)
+	if err != nil {
+		return fmt.Errorf("reading signing key: %v", err)
+	}
+	sigfile, err := bundle.SignTarBundle(pkg.fs, tarfile, key)
+	if err != nil {
+		return fmt.Errorf("signing package: %v", err)
+	}
+	fmt.Fprintf(pkg.out, "Signature created: %v\n", sigfile)
+	return nil
 }
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/kudobuilder/kudo/pkg/apis/kudo/v1alpha1"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/bundle"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+const packageAddExample = `  # Add a parameter to the operator package in the current directory
+  kubectl kudo package add param MEMORY --default 256Mi --description "Memory of each pod"
+
+  # Add a task with its templates, templates which don't exist yet are created
+  kubectl kudo package add task backup --resource backup-job.yaml --path my-operator
+
+  # Add a plan running the task
+  kubectl kudo package add plan backup --task backup --path my-operator`
+
+// packageAddCmd holds the options shared by the package add commands
+type packageAddCmd struct {
+	path string
+	out  io.Writer
+	fs   afero.Fs
+}
+
+// newPackageAddCmd adds parameters, tasks and plans to an operator package
+func newPackageAddCmd(fs afero.Fs, out io.Writer) *cobra.Command {
+	add := &packageAddCmd{out: out, fs: fs}
+	cmd := &cobra.Command{
+		Use:     "add",
+		Short:   "Add a parameter, task or plan to a KUDO operator package.",
+		Long:    `Add a parameter, task or plan to the operator.yaml and params.yaml of a KUDO operator package.`,
+		Example: packageAddExample,
+	}
+
+	cmd.PersistentFlags().StringVar(&add.path, "path", ".", "Directory of the operator package.")
+	cmd.AddCommand(newPackageAddParamCmd(add))
+	cmd.AddCommand(newPackageAddTaskCmd(add))
+	cmd.AddCommand(newPackageAddPlanCmd(add))
+	return cmd
+}
+
+func newPackageAddParamCmd(add *packageAddCmd) *cobra.Command {
+	param := v1alpha1.Parameter{}
+	var defaultValue string
+	var optional bool
+	cmd := &cobra.Command{
+		Use:   "param <name>",
+		Short: "Add a parameter to params.yaml.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("expecting exactly one argument - name of the parameter")
+			}
+			param.Name = args[0]
+			param.Required = !optional
+			if cmd.Flags().Changed("default") {
+				param.Default = &defaultValue
+			}
+			return add.run(param.Name, "parameter", func() error {
+				return bundle.AddParameter(add.fs, add.path, param)
+			})
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&param.Description, "description", "", "Description of the parameter.")
+	f.StringVar(&param.DisplayName, "display-name", "", "Name of the parameter shown to users.")
+	f.StringVar(&defaultValue, "default", "", "Default value of the parameter.")
+	f.StringVar(&param.Trigger, "trigger", "", "Plan to run when the parameter changes.")
+	f.BoolVar(&optional, "optional", false, "The parameter does not need to be set.")
+	return cmd
+}
+
+func newPackageAddTaskCmd(add *packageAddCmd) *cobra.Command {
+	task := v1alpha1.TaskSpec{}
+	cmd := &cobra.Command{
+		Use:   "task <name>",
+		Short: "Add a task to operator.yaml.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("expecting exactly one argument - name of the task")
+			}
+			return add.run(args[0], "task", func() error {
+				return bundle.AddTask(add.fs, add.path, args[0], task)
+			})
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&task.Resources, "resource", nil, "Template of the task, relative to the templates directory. Can be repeated.")
+	return cmd
+}
+
+func newPackageAddPlanCmd(add *packageAddCmd) *cobra.Command {
+	var tasks []string
+	var strategy string
+	cmd := &cobra.Command{
+		Use:   "plan <name>",
+		Short: "Add a plan to operator.yaml.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("expecting exactly one argument - name of the plan")
+			}
+			ordering := v1alpha1.Ordering(strategy)
+			if ordering != v1alpha1.Serial && ordering != v1alpha1.Parallel {
+				return fmt.Errorf("invalid strategy %q, has to be %s or %s", strategy, v1alpha1.Serial, v1alpha1.Parallel)
+			}
+			return add.run(args[0], "plan", func() error {
+				return bundle.AddPlan(add.fs, add.path, args[0], ordering, tasks)
+			})
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringArrayVar(&tasks, "task", nil, "Task run by the plan. Can be repeated.")
+	f.StringVar(&strategy, "strategy", string(v1alpha1.Serial), "Strategy of the plan, serial or parallel.")
+	return cmd
+}
+
+// run applies the change to the package and verifies that it can still be loaded
+func (add *packageAddCmd) run(name string, kind string, change func() error) error {
+	if err := change(); err != nil {
+		return err
+	}
+	b, err := bundle.NewBundle(add.fs, add.path)
+	if err != nil {
+		return err
+	}
+	if _, err := b.GetCRDs(); err != nil {
+		return fmt.Errorf("%s %s added but the package is invalid: %v", kind, name, err)
+	}
+	fmt.Fprintf(add.out, "Added %s %s to %v\n", kind, name, add.path)
+	return nil
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/kudobuilder/kudo/pkg/kudoctl/bundle"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/convert"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+const (
+	packageConvertDCOSDesc = `
+Convert a DC/OS SDK service specification (svc.yml) into a KUDO operator package.
+
+Pods become StatefulSets with a headless Service, resource sets become resource requests, health and readiness
+checks become probes and volumes become persistent volume claims. The plans of the specification deploy the pods.
+The specification has to be rendered, mustache templates are not supported.
+Fields which can not be converted are reported and have to be migrated manually.`
+
+	packageConvertDCOSExample = `  # Convert the service specification into the operator package ./hello-world
+  kubectl kudo package convert-dcos svc.yml
+
+  # Convert it into another directory
+  kubectl kudo package convert-dcos svc.yml --destination operators`
+)
+
+type packageConvertDCOSCmd struct {
+	path        string
+	destination string
+	out         io.Writer
+	fs          afero.Fs
+}
+
+// newPackageConvertDCOSCmd converts a DC/OS service specification into an operator package
+func newPackageConvertDCOSCmd(fs afero.Fs, out io.Writer) *cobra.Command {
+	pkg := &packageConvertDCOSCmd{out: out, fs: fs}
+	cmd := &cobra.Command{
+		Use:     "convert-dcos <svc.yml>",
+		Short:   "Convert a DC/OS SDK service specification into a KUDO operator package.",
+		Long:    packageConvertDCOSDesc,
+		Example: packageConvertDCOSExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("expecting exactly one argument - the service specification to convert")
+			}
+			pkg.path = args[0]
+			return pkg.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&pkg.destination, "destination", "d", ".", "Location to create the package in.")
+	return cmd
+}
+
+func (pkg *packageConvertDCOSCmd) run() error {
+	svc, err := afero.ReadFile(pkg.fs, pkg.path)
+	if err != nil {
+		return err
+	}
+	pf, report, err := convert.DCOSPackage(svc)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Join(pkg.destination, pf.Operator.Name)
+	if exists, _ := afero.Exists(pkg.fs, dir); exists {
+		return fmt.Errorf("%s already exists", dir)
+	}
+	if err := bundle.WritePackage(pkg.fs, dir, pf); err != nil {
+		return err
+	}
+	fmt.Fprintf(pkg.out, "Operator package created: %v\n", dir)
+
+	if len(report) > 0 {
+		fmt.Fprintf(pkg.out, "The following fields could not be converted:\n")
+		for _, r := range report {
+			fmt.Fprintf(pkg.out, "  %s\n", r)
+		}
+	}
+	return nil
+}
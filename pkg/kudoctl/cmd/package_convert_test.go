@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/kudobuilder/kudo/pkg/kudoctl/bundle"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageConvertDCOS(t *testing.T) {
+	svc, err := ioutil.ReadFile("../convert/testdata/svc.yml")
+	assert.NoError(t, err)
+	fs := afero.NewMemMapFs()
+	assert.NoError(t, afero.WriteFile(fs, "/svc.yml", svc, 0644))
+
+	out := &bytes.Buffer{}
+	cmd := newPackageCmd(fs, out)
+	cmd.SetArgs([]string{"convert-dcos", "/svc.yml", "--destination", "/opt"})
+	assert.NoError(t, cmd.Execute())
+	assert.Contains(t, out.String(), "Operator package created: /opt/hello-world\nThe following fields could not be converted:\n")
+	assert.Contains(t, out.String(), "  scheduler: scheduler settings are not needed on Kubernetes\n")
+
+	b, err := bundle.NewBundle(fs, "/opt/hello-world")
+	assert.NoError(t, err)
+	_, err = b.GetCRDs()
+	assert.NoError(t, err)
+
+	cmd = newPackageCmd(fs, out)
+	cmd.SetArgs([]string{"convert-dcos", "/svc.yml", "--destination", "/opt"})
+	cmd.SetOutput(&bytes.Buffer{})
+	assert.EqualError(t, cmd.Execute(), "/opt/hello-world already exists")
+}
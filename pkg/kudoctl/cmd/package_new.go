@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/kudobuilder/kudo/pkg/kudoctl/bundle"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+const packageNewExample = `  # Create the operator package my-operator in the current directory
+  kubectl kudo package new my-operator
+
+  # Create it in another directory, test it and package it
+  kubectl kudo package new my-operator --destination operators
+  kubectl kudo test --package operators/my-operator
+  kubectl kudo package operators/my-operator`
+
+type packageNewCmd struct {
+	name        string
+	destination string
+	out         io.Writer
+	fs          afero.Fs
+}
+
+// newPackageNewCmd creates the skeleton of a new operator package
+func newPackageNewCmd(fs afero.Fs, out io.Writer) *cobra.Command {
+	pkg := &packageNewCmd{out: out, fs: fs}
+	cmd := &cobra.Command{
+		Use:   "new <operator_name>",
+		Short: "Create a new KUDO operator package.",
+		Long: `Create the skeleton of a new KUDO operator package in a directory named after the operator.
+The package has a deploy plan running a StatefulSet with a Service, its parameters and a test case for 'kudo test --package' in the tests directory.`,
+		Example: packageNewExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("expecting exactly one argument - name of the operator")
+			}
+			pkg.name = args[0]
+			return pkg.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&pkg.destination, "destination", "d", ".", "Location to create the package in.")
+	return cmd
+}
+
+func (pkg *packageNewCmd) run() error {
+	dir, err := bundle.NewSkeleton(pkg.fs, pkg.destination, pkg.name)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(pkg.out, "Operator package created: %v\n", dir)
+	return nil
+}
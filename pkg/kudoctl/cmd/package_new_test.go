@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageNewAndAdd(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	out := &bytes.Buffer{}
+
+	cmd := newPackageCmd(fs, out)
+	cmd.SetArgs([]string{"new", "my-operator", "--destination", "/opt"})
+	assert.NoError(t, cmd.Execute())
+	assert.Equal(t, "Operator package created: /opt/my-operator\n", out.String())
+
+	tests := []struct {
+		name         string
+		args         []string
+		errorMessage string
+	}{
+		{"param", []string{"add", "param", "MEMORY", "--default", "256Mi", "--path", "/opt/my-operator"}, ""},
+		{"duplicate param", []string{"add", "param", "MEMORY", "--path", "/opt/my-operator"}, "parameter MEMORY already exists"},
+		{"task", []string{"add", "task", "backup", "--resource", "backup.yaml", "--path", "/opt/my-operator"}, ""},
+		{"plan", []string{"add", "plan", "backup", "--task", "backup", "--path", "/opt/my-operator"}, ""},
+		{"invalid strategy", []string{"add", "plan", "restore", "--task", "backup", "--strategy", "random", "--path", "/opt/my-operator"}, `invalid strategy "random", has to be serial or parallel`},
+		{"unknown task", []string{"add", "plan", "restore", "--task", "restore", "--path", "/opt/my-operator"}, "task restore does not exist, add it with 'kudo package add task'"},
+		{"no path shorthand", []string{"add", "param", "FOO", "-p", "/opt/my-operator"}, "unknown shorthand flag: 'p' in -p"},
+	}
+	for _, tt := range tests {
+		out.Reset()
+		cmd := newPackageCmd(fs, out)
+		cmd.SetArgs(tt.args)
+		cmd.SetOutput(&bytes.Buffer{})
+		err := cmd.Execute()
+		if tt.errorMessage != "" {
+			assert.EqualError(t, err, tt.errorMessage, tt.name)
+			continue
+		}
+		assert.NoError(t, err, tt.name)
+		assert.Contains(t, out.String(), "Added ", tt.name)
+	}
+}
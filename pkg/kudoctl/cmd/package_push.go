@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kudobuilder/kudo/pkg/kudoctl/bundle"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/oci"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+const packagePushExample = `  # Push a package to an OCI registry, the version of the operator is used as tag
+  kubectl kudo package push zookeeper-0.1.0.tgz oci://registry.example.com/operators/zookeeper
+
+  # Push a package with an explicit tag to a registry which requires authentication
+  cat password.txt | kubectl kudo package push zookeeper-0.1.0.tgz oci://registry.example.com/operators/zookeeper:0.1.0 --username admin --password-stdin
+
+  # Install the pushed package
+  kubectl kudo install oci://registry.example.com/operators/zookeeper:0.1.0`
+
+type packagePushCmd struct {
+	path      string
+	reference string
+	registry  registryFlags
+	in        io.Reader
+	out       io.Writer
+	fs        afero.Fs
+}
+
+// newPackagePushCmd uploads an operator tarball to an OCI registry
+func newPackagePushCmd(fs afero.Fs, out io.Writer) *cobra.Command {
+	push := &packagePushCmd{out: out, fs: fs}
+	cmd := &cobra.Command{
+		Use:     "push <package.tgz> <oci://registry/repository[:tag]>",
+		Short:   "Push a KUDO package tarball to an OCI registry.",
+		Long:    `Push a KUDO package tarball created by 'kudo package' to an OCI registry, from where it can be installed with 'kudo install oci://...'.`,
+		Example: packagePushExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return errors.New("expecting exactly two arguments - package tarball and OCI reference")
+			}
+			push.path = args[0]
+			push.reference = args[1]
+			push.in = cmd.InOrStdin()
+			return push.run()
+		},
+	}
+
+	push.registry.addFlags(cmd.Flags())
+	return cmd
+}
+
+func (push *packagePushCmd) run() error {
+	ref, err := oci.ParseReference(push.reference)
+	if err != nil {
+		return err
+	}
+	if !strings.HasSuffix(push.path, ".tgz") {
+		return fmt.Errorf("%s is not a package tarball, create one with 'kudo package'", push.path)
+	}
+
+	content, err := afero.ReadFile(push.fs, push.path)
+	if err != nil {
+		return err
+	}
+	b, err := bundle.NewBundle(push.fs, push.path)
+	if err != nil {
+		return err
+	}
+	pf, err := b.GetPkgFiles()
+	if err != nil {
+		return fmt.Errorf("invalid package %s: %v", push.path, err)
+	}
+	if ref.Tag == "" {
+		ref = ref.WithTag(pf.Operator.Version)
+	}
+
+	options, err := push.registry.options(push.in)
+	if err != nil {
+		return err
+	}
+	client, err := oci.NewClient(options)
+	if err != nil {
+		return err
+	}
+	digest, err := client.Push(ref, content, oci.Config{
+		Name:       pf.Operator.Name,
+		Version:    pf.Operator.Version,
+		AppVersion: pf.Operator.AppVersion,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(push.out, "Package pushed: %v (%v)\n", ref, digest)
+	return nil
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kudobuilder/kudo/pkg/kudoctl/bundle"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/bundle/finder"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/files"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/oci"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/oci/ocitest"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackagePush(t *testing.T) {
+	registry := ocitest.NewRegistry()
+	defer registry.Close()
+
+	fs := afero.NewMemMapFs()
+	files.CopyOperatorToFs(fs, "../bundle/testdata/zk", "/opt")
+	tarfile, err := bundle.ToTarBundle(fs, "/opt/zk", "/opt", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := &bytes.Buffer{}
+	push := &packagePushCmd{path: tarfile, reference: "oci://" + registry.Host() + "/operators/zookeeper", fs: fs, out: out}
+	assert.NoError(t, push.run())
+	assert.True(t, strings.HasPrefix(out.String(), "Package pushed: oci://"+registry.Host()+"/operators/zookeeper:0.1.0"), out.String())
+
+	// the pushed package can be installed like a repository package
+	f, err := finder.NewOCI(oci.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := f.GetBundle("oci://"+registry.Host()+"/operators/zookeeper", "0.1.0")
+	assert.NoError(t, err)
+	crds, err := b.GetCRDs()
+	assert.NoError(t, err)
+	assert.Equal(t, "zookeeper", crds.Operator.Name)
+
+	push = &packagePushCmd{path: "/opt/zk", reference: "oci://" + registry.Host() + "/operators/zookeeper", fs: fs, out: out}
+	assert.EqualError(t, push.run(), "/opt/zk is not a package tarball, create one with 'kudo package'")
+}
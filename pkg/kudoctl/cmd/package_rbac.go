@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/kudobuilder/kudo/pkg/kudoctl/bundle"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/rbac"
+	"github.com/kudobuilder/kudo/pkg/util/kudo"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	packageRBACDesc = `
+Print a ServiceAccount with a Role and RoleBinding granting the permissions the resources of an operator package need.
+
+The permissions are computed from the templates of all plans of the package, rendered with the default values of the
+parameters. An instance which is annotated with '` + kudo.ServiceAccountAnnotation + `: <service account>' is applied by
+the KUDO manager impersonating that service account, with only these permissions. Objects of prior operator versions
+are then only pruned if their kind is rendered by the plan which prunes them.
+Resources which are cluster scoped need a ClusterRole instead of the printed Role.`
+
+	packageRBACExample = `  # Print the RBAC manifests for the package in the directory zookeeper
+  kubectl kudo package rbac zookeeper
+
+  # Print them for a service account zk in the namespace kudo and apply them
+  kubectl kudo package rbac zookeeper-0.1.0.tgz --service-account zk --namespace kudo | kubectl apply -f -`
+)
+
+type packageRBACCmd struct {
+	path           string
+	serviceAccount string
+	namespace      string
+	out            io.Writer
+	fs             afero.Fs
+}
+
+// newPackageRBACCmd prints the RBAC manifests the resources of an operator package need
+func newPackageRBACCmd(fs afero.Fs, out io.Writer) *cobra.Command {
+	pkg := &packageRBACCmd{out: out, fs: fs}
+	cmd := &cobra.Command{
+		Use:     "rbac <package>",
+		Short:   "Print the RBAC manifests the resources of a KUDO operator package need.",
+		Long:    packageRBACDesc,
+		Example: packageRBACExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("expecting exactly one argument - the operator package")
+			}
+			pkg.path = args[0]
+			return pkg.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&pkg.serviceAccount, "service-account", "", "Name of the service account, defaults to the name of the operator.")
+	f.StringVarP(&pkg.namespace, "namespace", "n", "default", "Namespace of the service account and the instances.")
+	return cmd
+}
+
+func (pkg *packageRBACCmd) run() error {
+	b, err := bundle.NewBundle(pkg.fs, pkg.path)
+	if err != nil {
+		return err
+	}
+	crds, err := b.GetCRDs()
+	if err != nil {
+		return err
+	}
+	rules, err := rbac.Rules(crds.OperatorVersion)
+	if err != nil {
+		return err
+	}
+
+	name := pkg.serviceAccount
+	if name == "" {
+		name = crds.Operator.Name
+	}
+	meta := metav1.ObjectMeta{Name: name, Namespace: pkg.namespace}
+	objs := []runtime.Object{
+		&corev1.ServiceAccount{
+			TypeMeta:   metav1.TypeMeta{Kind: "ServiceAccount", APIVersion: "v1"},
+			ObjectMeta: meta,
+		},
+		&rbacv1.Role{
+			TypeMeta:   metav1.TypeMeta{Kind: "Role", APIVersion: "rbac.authorization.k8s.io/v1"},
+			ObjectMeta: meta,
+			Rules:      rules,
+		},
+		&rbacv1.RoleBinding{
+			TypeMeta:   metav1.TypeMeta{Kind: "RoleBinding", APIVersion: "rbac.authorization.k8s.io/v1"},
+			ObjectMeta: meta,
+			RoleRef:    rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "Role", Name: name},
+			Subjects:   []rbacv1.Subject{{Kind: "ServiceAccount", Name: name, Namespace: pkg.namespace}},
+		},
+	}
+
+	for _, obj := range objs {
+		o, err := yaml.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(pkg.out, "---\n%s", o)
+	}
+	return nil
+}
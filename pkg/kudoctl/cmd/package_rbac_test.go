@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageRBAC(t *testing.T) {
+	out := &bytes.Buffer{}
+	cmd := newPackageCmd(afero.NewOsFs(), out)
+	cmd.SetArgs([]string{"rbac", "../bundle/testdata/zk", "--service-account", "zk", "--namespace", "kudo"})
+	assert.NoError(t, cmd.Execute())
+
+	assert.Contains(t, out.String(), "---\napiVersion: v1\nkind: ServiceAccount\nmetadata:\n  creationTimestamp: null\n  name: zk\n  namespace: kudo\n")
+	assert.Contains(t, out.String(), `rules:
+- apiGroups:
+  - ""
+  resources:
+  - services
+  verbs:
+  - create
+  - delete
+  - patch
+- apiGroups:
+  - apps
+  resources:
+  - statefulsets
+`)
+	assert.Contains(t, out.String(), "- apiGroups:\n  - kudo.dev\n  resources:\n  - instances/finalizers\n  verbs:\n  - update\n")
+	assert.Contains(t, out.String(), "roleRef:\n  apiGroup: rbac.authorization.k8s.io\n  kind: Role\n  name: zk\nsubjects:\n- kind: ServiceAccount\n  name: zk\n  namespace: kudo\n")
+}
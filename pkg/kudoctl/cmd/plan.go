@@ -47,7 +47,13 @@ func NewPlanStatusCmd() *cobra.Command {
 		Short: "Shows the status of all plans to an particular instance.",
 		Long: `
 	# View plan status
-	kudoctl plan status --instance=<instanceName>`,
+	kudoctl plan status --instance=<instanceName>
+
+	# Watch plan status until the active plan is complete
+	kudoctl plan status --instance=<instanceName> --watch
+
+	# View plan status as JSON
+	kudoctl plan status --instance=<instanceName> -o json`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return plan.RunStatus(cmd, args, options, &Settings)
 		},
@@ -55,6 +61,8 @@ func NewPlanStatusCmd() *cobra.Command {
 
 	statusCmd.Flags().StringVar(&options.Instance, "instance", "", "The instance name available from 'kubectl get instances'")
 	statusCmd.Flags().StringVar(&options.Namespace, "namespace", "default", "The namespace where the instance is running.")
+	statusCmd.Flags().BoolVarP(&options.Watch, "watch", "w", false, "Keep printing the status as it changes until the active plan is complete. Fails if the plan ends in ERROR.")
+	statusCmd.Flags().StringVarP(&options.Output, "output", "o", "", "Output format. Only \"json\" is supported. (default to a tree)")
 
 	return statusCmd
 }
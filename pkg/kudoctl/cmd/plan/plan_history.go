@@ -19,6 +19,10 @@ import (
 type Options struct {
 	Instance  string
 	Namespace string
+	// Watch keeps printing the status until the active plan is finished
+	Watch bool
+	// Output is the output format, empty for a tree
+	Output string
 }
 
 var (
@@ -3,21 +3,58 @@ package plan
 import (
 	"encoding/json"
 	"fmt"
-	"log"
+	"io"
+	"sort"
 
 	kudov1alpha1 "github.com/kudobuilder/kudo/pkg/apis/kudo/v1alpha1"
 	"github.com/kudobuilder/kudo/pkg/kudoctl/env"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/util/kudo"
+
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/xlab/treeprint"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/client-go/dynamic"
-	"k8s.io/client-go/tools/clientcmd"
 )
 
 // DefaultStatusOptions provides the default options for plan status
 var DefaultStatusOptions = &Options{}
 
+// notActive is displayed as the state of plans, phases and steps of plans that are not currently active
+const notActive = "NOT ACTIVE"
+
+// Status is the printable status of all plans of an instance
+type Status struct {
+	Instance        string       `json:"instance"`
+	Namespace       string       `json:"namespace"`
+	OperatorVersion string       `json:"operatorVersion"`
+	ActivePlan      string       `json:"activePlan,omitempty"`
+	Plans           []PlanStatus `json:"plans"`
+
+	// activePlanName is the name of the plan the active plan execution runs
+	activePlanName string
+}
+
+// PlanStatus is the printable status of a single plan
+type PlanStatus struct {
+	Name     string        `json:"name"`
+	Strategy string        `json:"strategy"`
+	State    string        `json:"state"`
+	Phases   []PhaseStatus `json:"phases"`
+}
+
+// PhaseStatus is the printable status of a single phase
+type PhaseStatus struct {
+	Name     string       `json:"name"`
+	Strategy string       `json:"strategy"`
+	State    string       `json:"state"`
+	Steps    []StepStatus `json:"steps"`
+}
+
+// StepStatus is the printable status of a single step
+type StepStatus struct {
+	Name  string `json:"name"`
+	State string `json:"state"`
+}
+
 // RunStatus runs the plan status command
 func RunStatus(cmd *cobra.Command, args []string, options *Options, settings *env.Settings) error {
 
@@ -25,140 +62,216 @@ func RunStatus(cmd *cobra.Command, args []string, options *Options, settings *en
 	if err != nil || instanceFlag == "" {
 		return fmt.Errorf("flag Error: Please set instance flag, e.g. \"--instance=<instanceName>\"")
 	}
+	if options.Output != "" && options.Output != "json" {
+		return fmt.Errorf("flag Error: unsupported output format %q, only \"json\" is supported", options.Output)
+	}
 
-	err = planStatus(options, settings)
+	kc, err := kudo.NewClient(options.Namespace, settings.KubeConfig)
 	if err != nil {
-		return fmt.Errorf("client Error: %v", err)
+		return errors.Wrap(err, "creating kudo client")
 	}
-	return nil
-}
 
-func planStatus(options *Options, settings *env.Settings) error {
-
-	tree := treeprint.New()
+	if options.Watch {
+		return watchStatus(kc, options, cmd.OutOrStdout())
+	}
 
-	config, err := clientcmd.BuildConfigFromFlags("", settings.KubeConfig)
+	err = planStatus(kc, options, cmd.OutOrStdout())
 	if err != nil {
-		return err
+		return fmt.Errorf("client Error: %v", err)
 	}
+	return nil
+}
 
-	//  Create a Dynamic Client to interface with CRDs.
-	dynamicClient, err := dynamic.NewForConfig(config)
+func planStatus(kc *kudo.Client, options *Options, out io.Writer) error {
+	status, err := getStatus(kc, options)
 	if err != nil {
 		return err
 	}
-
-	instancesGVR := schema.GroupVersionResource{
-		Group:    "kudo.dev",
-		Version:  "v1alpha1",
-		Resource: "instances",
+	if status.ActivePlan == "" && options.Output == "" {
+		fmt.Fprintf(out, "No active plan exists for instance %s\n", status.Instance)
+		return nil
 	}
 
-	instObj, err := dynamicClient.Resource(instancesGVR).Namespace(options.Namespace).Get(options.Instance, metav1.GetOptions{})
+	rendered, err := status.render(options.Output)
 	if err != nil {
 		return err
 	}
+	fmt.Fprint(out, rendered)
+	return nil
+}
 
-	mInstObj, err := instObj.MarshalJSON()
+// watchStatus prints the status of the plans every time the instance or its plan executions change.
+// It returns once the active plan is COMPLETE, or with an error once it is in ERROR.
+func watchStatus(kc *kudo.Client, options *Options, out io.Writer) error {
+	instanceWatch, err := kc.WatchInstance(options.Instance, options.Namespace)
 	if err != nil {
-		return err
+		return errors.Wrapf(err, "watching instance %s", options.Instance)
 	}
+	defer instanceWatch.Stop()
 
-	instance := kudov1alpha1.Instance{}
-
-	err = json.Unmarshal(mInstObj, &instance)
+	planWatch, err := kc.WatchPlanExecutions(options.Instance, options.Namespace)
 	if err != nil {
-		return err
+		return errors.Wrapf(err, "watching plan executions of instance %s", options.Instance)
 	}
+	defer planWatch.Stop()
 
-	operatorVersionNameOfInstance := instance.Spec.OperatorVersion.Name
+	var lastRendered string
+	for {
+		status, err := getStatus(kc, options)
+		if err != nil {
+			return err
+		}
 
-	operatorGVR := schema.GroupVersionResource{
-		Group:    "kudo.dev",
-		Version:  "v1alpha1",
-		Resource: "operatorversions",
+		rendered, err := status.render(options.Output)
+		if err != nil {
+			return err
+		}
+		if rendered != lastRendered {
+			fmt.Fprint(out, rendered)
+			lastRendered = rendered
+		}
+
+		if plan := status.activePlan(); plan != nil {
+			switch kudov1alpha1.PhaseState(plan.State) {
+			case kudov1alpha1.PhaseStateComplete:
+				return nil
+			case kudov1alpha1.PhaseStateError:
+				return fmt.Errorf("plan %s of instance %s failed", plan.Name, status.Instance)
+			}
+		}
+
+		select {
+		case _, ok := <-instanceWatch.ResultChan():
+			if !ok {
+				return fmt.Errorf("watch of instance %s was closed", options.Instance)
+			}
+		case _, ok := <-planWatch.ResultChan():
+			if !ok {
+				return fmt.Errorf("watch of plan executions of instance %s was closed", options.Instance)
+			}
+		}
 	}
+}
 
-	//  List all of the Virtual Services.
-	operatorObj, err := dynamicClient.Resource(operatorGVR).Namespace(options.Namespace).Get(operatorVersionNameOfInstance, metav1.GetOptions{})
+// getStatus fetches the instance, its operator version and its active plan execution and builds their status
+func getStatus(kc *kudo.Client, options *Options) (*Status, error) {
+	instance, err := kc.GetInstance(options.Instance, options.Namespace)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if instance == nil {
+		return nil, fmt.Errorf("instance %s in namespace %s does not exist in the cluster", options.Instance, options.Namespace)
 	}
 
-	mOperatorObj, err := operatorObj.MarshalJSON()
+	ov, err := kc.GetOperatorVersion(instance.Spec.OperatorVersion.Name, instance.GetOperatorVersionNamespace())
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if ov == nil {
+		return nil, fmt.Errorf("operatorversion %s of instance %s does not exist in the cluster", instance.Spec.OperatorVersion.Name, instance.Name)
 	}
 
-	operator := kudov1alpha1.OperatorVersion{}
-
-	err = json.Unmarshal(mOperatorObj, &operator)
-	if err != nil {
-		return err
+	var activePlan *kudov1alpha1.PlanExecution
+	if instance.Status.ActivePlan.Name != "" {
+		namespace := instance.Status.ActivePlan.Namespace
+		if namespace == "" {
+			namespace = instance.Namespace
+		}
+		activePlan, err = kc.GetPlanExecution(instance.Status.ActivePlan.Name, namespace)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	planExecutionsGVR := schema.GroupVersionResource{
-		Group:    "kudo.dev",
-		Version:  "v1alpha1",
-		Resource: "planexecutions",
+	return newStatus(instance, ov, activePlan), nil
+}
+
+// newStatus builds the status of all plans of the operator version. Plans are sorted by name and only the
+// active plan carries the state of its plan execution.
+func newStatus(instance *kudov1alpha1.Instance, ov *kudov1alpha1.OperatorVersion, activePlan *kudov1alpha1.PlanExecution) *Status {
+	status := &Status{
+		Instance:        instance.Name,
+		Namespace:       instance.Namespace,
+		OperatorVersion: instance.Spec.OperatorVersion.Name,
+		Plans:           []PlanStatus{},
 	}
 
-	if instance.Status.ActivePlan.Name == "" {
-		log.Printf("No active plan exists for instance %s", instance.Name)
-		return nil
+	names := make([]string, 0, len(ov.Spec.Plans))
+	for name := range ov.Spec.Plans {
+		names = append(names, name)
 	}
-	activePlanObj, err := dynamicClient.Resource(planExecutionsGVR).Namespace(options.Namespace).Get(instance.Status.ActivePlan.Name, metav1.GetOptions{})
-	if err != nil {
-		return err
+	sort.Strings(names)
+
+	for _, name := range names {
+		plan := ov.Spec.Plans[name]
+		if activePlan != nil && name == activePlan.Spec.PlanName {
+			status.ActivePlan = activePlan.Name
+			status.activePlanName = name
+			status.Plans = append(status.Plans, activePlanStatus(name, plan, activePlan))
+			continue
+		}
+
+		planStatus := PlanStatus{Name: name, Strategy: string(plan.Strategy), State: notActive, Phases: []PhaseStatus{}}
+		for _, phase := range plan.Phases {
+			phaseStatus := PhaseStatus{Name: phase.Name, Strategy: string(phase.Strategy), State: notActive, Steps: []StepStatus{}}
+			for _, step := range phase.Steps {
+				phaseStatus.Steps = append(phaseStatus.Steps, StepStatus{Name: step.Name, State: notActive})
+			}
+			planStatus.Phases = append(planStatus.Phases, phaseStatus)
+		}
+		status.Plans = append(status.Plans, planStatus)
 	}
 
-	mPlanObj, err := activePlanObj.MarshalJSON()
-	if err != nil {
-		return err
+	return status
+}
+
+func activePlanStatus(name string, plan kudov1alpha1.Plan, pe *kudov1alpha1.PlanExecution) PlanStatus {
+	planStatus := PlanStatus{Name: name, Strategy: string(plan.Strategy), State: string(pe.Status.State), Phases: []PhaseStatus{}}
+	for _, phase := range pe.Status.Phases {
+		phaseStatus := PhaseStatus{Name: phase.Name, Strategy: string(phase.Strategy), State: string(phase.State), Steps: []StepStatus{}}
+		for _, step := range phase.Steps {
+			phaseStatus.Steps = append(phaseStatus.Steps, StepStatus{Name: step.Name, State: string(step.State)})
+		}
+		planStatus.Phases = append(planStatus.Phases, phaseStatus)
 	}
+	return planStatus
+}
 
-	activePlanType := kudov1alpha1.PlanExecution{}
+// activePlan returns the status of the active plan or nil if there is none
+func (s *Status) activePlan() *PlanStatus {
+	for i, p := range s.Plans {
+		if s.activePlanName != "" && p.Name == s.activePlanName {
+			return &s.Plans[i]
+		}
+	}
+	return nil
+}
 
-	err = json.Unmarshal(mPlanObj, &activePlanType)
-	if err != nil {
-		return err
+// render returns the status either as a tree or, if output is "json", as a JSON document
+func (s *Status) render(output string) (string, error) {
+	if output == "json" {
+		b, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(b) + "\n", nil
 	}
 
-	rootDisplay := fmt.Sprintf("%s (Operator-Version: \"%s\" Active-Plan: \"%s\")", instance.Name, instance.Spec.OperatorVersion.Name, instance.Status.ActivePlan.Name)
+	tree := treeprint.New()
+	rootDisplay := fmt.Sprintf("%s (Operator-Version: \"%s\" Active-Plan: \"%s\")", s.Instance, s.OperatorVersion, s.ActivePlan)
 	rootBranchName := tree.AddBranch(rootDisplay)
-
-	for name, plan := range operator.Spec.Plans {
-		if name == activePlanType.Spec.PlanName {
-			planDisplay := fmt.Sprintf("Plan %s (%s strategy) [%s]", name, plan.Strategy, activePlanType.Status.State)
-			planBranchName := rootBranchName.AddBranch(planDisplay)
-			for _, phase := range activePlanType.Status.Phases {
-				phaseDisplay := fmt.Sprintf("Phase %s (%s strategy) [%s]", phase.Name, phase.Strategy, phase.State)
-				phaseBranchName := planBranchName.AddBranch(phaseDisplay)
-				for _, steps := range phase.Steps {
-					stepsDisplay := fmt.Sprintf("Step %s (%s)", steps.Name, steps.State)
-					phaseBranchName.AddBranch(stepsDisplay)
-				}
-			}
-		} else {
-			planDisplay := fmt.Sprintf("Plan %s (%s strategy) [NOT ACTIVE]", name, plan.Strategy)
-			planBranchName := rootBranchName.AddBranch(planDisplay)
-			for _, phase := range plan.Phases {
-				phaseDisplay := fmt.Sprintf("Phase %s (%s strategy) [NOT ACTIVE]", phase.Name, phase.Strategy)
-				phaseBranchName := planBranchName.AddBranch(phaseDisplay)
-				for _, steps := range plan.Phases {
-					stepsDisplay := fmt.Sprintf("Step %s (%s strategy) [NOT ACTIVE]", steps.Name, steps.Strategy)
-					stepBranchName := phaseBranchName.AddBranch(stepsDisplay)
-					for _, step := range steps.Steps {
-						stepDisplay := fmt.Sprintf("%s [NOT ACTIVE]", step.Name)
-						stepBranchName.AddBranch(stepDisplay)
-					}
-				}
+	for _, plan := range s.Plans {
+		planDisplay := fmt.Sprintf("Plan %s (%s strategy) [%s]", plan.Name, plan.Strategy, plan.State)
+		planBranchName := rootBranchName.AddBranch(planDisplay)
+		for _, phase := range plan.Phases {
+			phaseDisplay := fmt.Sprintf("Phase %s (%s strategy) [%s]", phase.Name, phase.Strategy, phase.State)
+			phaseBranchName := planBranchName.AddBranch(phaseDisplay)
+			for _, step := range phase.Steps {
+				stepDisplay := fmt.Sprintf("Step %s (%s)", step.Name, step.State)
+				phaseBranchName.AddBranch(stepDisplay)
 			}
 		}
 	}
 
-	fmt.Printf("Plan(s) for \"%s\" in namespace \"%s\":\n", instance.Name, options.Namespace)
-	fmt.Println(tree.String())
-
-	return nil
+	return fmt.Sprintf("Plan(s) for \"%s\" in namespace \"%s\":\n%s\n", s.Instance, s.Namespace, tree.String()), nil
 }
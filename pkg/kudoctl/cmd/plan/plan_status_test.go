@@ -0,0 +1,163 @@
+package plan
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/kudobuilder/kudo/pkg/apis/kudo/v1alpha1"
+	"github.com/kudobuilder/kudo/pkg/client/clientset/versioned/fake"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/util/kudo"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testObjects(state v1alpha1.PhaseState) (*v1alpha1.Instance, *v1alpha1.OperatorVersion, *v1alpha1.PlanExecution) {
+	instance := &v1alpha1.Instance{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: v1alpha1.InstanceSpec{
+			OperatorVersion: v1.ObjectReference{Name: "test-1.0"},
+		},
+		Status: v1alpha1.InstanceStatus{
+			ActivePlan: v1.ObjectReference{Name: "test-deploy-1"},
+		},
+	}
+	phases := []v1alpha1.Phase{{
+		Name:     "main",
+		Strategy: v1alpha1.Serial,
+		Steps:    []v1alpha1.Step{{Name: "everything", Tasks: []string{"app"}}},
+	}}
+	ov := &v1alpha1.OperatorVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-1.0", Namespace: "default"},
+		Spec: v1alpha1.OperatorVersionSpec{
+			Plans: map[string]v1alpha1.Plan{
+				"upgrade": {Strategy: v1alpha1.Serial, Phases: phases},
+				"deploy":  {Strategy: v1alpha1.Serial, Phases: phases},
+				"backup":  {Strategy: v1alpha1.Parallel, Phases: phases},
+			},
+		},
+	}
+	pe := &v1alpha1.PlanExecution{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-deploy-1", Namespace: "default"},
+		Spec:       v1alpha1.PlanExecutionSpec{PlanName: "deploy"},
+		Status: v1alpha1.PlanExecutionStatus{
+			State: state,
+			Phases: []v1alpha1.PhaseStatus{{
+				Name:     "main",
+				Strategy: v1alpha1.Serial,
+				State:    state,
+				Steps:    []v1alpha1.StepStatus{{Name: "everything", State: state}},
+			}},
+		},
+	}
+	return instance, ov, pe
+}
+
+func TestNewStatus(t *testing.T) {
+	instance, ov, pe := testObjects(v1alpha1.PhaseStateInProgress)
+
+	// map iteration order is random, run it a few times to make sure the order is stable
+	for i := 0; i < 10; i++ {
+		status := newStatus(instance, ov, pe)
+
+		var names []string
+		for _, p := range status.Plans {
+			names = append(names, p.Name)
+		}
+		if strings.Join(names, ",") != "backup,deploy,upgrade" {
+			t.Fatalf("expected plans to be sorted by name but got %v", names)
+		}
+	}
+
+	status := newStatus(instance, ov, pe)
+	if status.ActivePlan != "test-deploy-1" {
+		t.Errorf("expected active plan test-deploy-1 but got %s", status.ActivePlan)
+	}
+	active := status.activePlan()
+	if active == nil || active.Name != "deploy" || active.State != string(v1alpha1.PhaseStateInProgress) {
+		t.Errorf("expected deploy plan to be active and in progress but got %+v", active)
+	}
+	for _, p := range status.Plans {
+		if p.Name != "deploy" && p.State != notActive {
+			t.Errorf("expected plan %s to be not active but got %s", p.Name, p.State)
+		}
+		if len(p.Phases) != 1 || len(p.Phases[0].Steps) != 1 {
+			t.Errorf("expected plan %s to have one phase with one step but got %+v", p.Name, p.Phases)
+		}
+	}
+}
+
+func TestStatus_Render(t *testing.T) {
+	instance, ov, pe := testObjects(v1alpha1.PhaseStateComplete)
+	status := newStatus(instance, ov, pe)
+
+	tree, err := status.render("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(tree, "Plan deploy (serial strategy) [COMPLETE]") {
+		t.Errorf("expected active plan to be rendered as complete but got:\n%s", tree)
+	}
+	if !strings.Contains(tree, "Step everything (NOT ACTIVE)") {
+		t.Errorf("expected steps of inactive plans to be rendered but got:\n%s", tree)
+	}
+
+	out, err := status.render("json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Status
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("expected valid json but got %v:\n%s", err, out)
+	}
+	if decoded.Instance != "test" || len(decoded.Plans) != 3 || decoded.Plans[1].State != "COMPLETE" {
+		t.Errorf("unexpected json output:\n%s", out)
+	}
+}
+
+func TestWatchStatus(t *testing.T) {
+	tests := []struct {
+		name  string
+		state v1alpha1.PhaseState
+		err   string
+	}{
+		{"complete plan", v1alpha1.PhaseStateComplete, ""},
+		{"failed plan", v1alpha1.PhaseStateError, "plan deploy of instance test failed"},
+	}
+
+	for _, tt := range tests {
+		instance, ov, pe := testObjects(tt.state)
+		kc := kudo.NewClientFromK8s(fake.NewSimpleClientset(instance, ov, pe))
+
+		var out bytes.Buffer
+		err := watchStatus(kc, &Options{Instance: "test", Namespace: "default", Watch: true}, &out)
+		if tt.err == "" && err != nil {
+			t.Errorf("%s: expected no error but got %v", tt.name, err)
+		}
+		if tt.err != "" && (err == nil || err.Error() != tt.err) {
+			t.Errorf("%s: expected error %q but got %v", tt.name, tt.err, err)
+		}
+		if !strings.Contains(out.String(), "Plan(s) for \"test\"") {
+			t.Errorf("%s: expected the status to be printed but got:\n%s", tt.name, out.String())
+		}
+	}
+}
+
+func TestGetStatusUsesActivePlanNamespace(t *testing.T) {
+	instance, ov, pe := testObjects(v1alpha1.PhaseStateComplete)
+	instance.Status.ActivePlan.Namespace = "plans"
+	pe.Namespace = "plans"
+	kc := kudo.NewClientFromK8s(fake.NewSimpleClientset(instance, ov, pe))
+
+	status, err := getStatus(kc, &Options{Instance: "test", Namespace: "default"})
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	for _, plan := range status.Plans {
+		if plan.Name == "deploy" && plan.State != string(v1alpha1.PhaseStateComplete) {
+			t.Errorf("expected the state of the plan execution in namespace plans but got %q", plan.State)
+		}
+	}
+}
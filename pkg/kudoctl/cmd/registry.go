@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/kudobuilder/kudo/pkg/kudoctl/oci"
+
+	"github.com/spf13/pflag"
+)
+
+// registryFlags are the flags of commands accessing OCI registries
+type registryFlags struct {
+	username              string
+	passwordStdin         bool
+	plainHTTP             bool
+	caFile                string
+	insecureSkipTLSVerify bool
+}
+
+func (r *registryFlags) addFlags(f *pflag.FlagSet) {
+	f.StringVar(&r.username, "username", "", "Username for the registry.")
+	f.BoolVar(&r.passwordStdin, "password-stdin", false, "Read the password for the registry from stdin.")
+	f.BoolVar(&r.plainHTTP, "plain-http", false, "Use http instead of https to connect to the registry.")
+	f.StringVar(&r.caFile, "ca-file", "", "Path to a PEM encoded CA bundle to verify the certificate of the registry.")
+	f.BoolVar(&r.insecureSkipTLSVerify, "insecure-skip-tls-verify", false, "Skip verification of the certificate of the registry.")
+}
+
+// options returns the registry options of the flags, the password is read from in
+func (r *registryFlags) options(in io.Reader) (oci.Options, error) {
+	options := oci.Options{
+		Username:              r.username,
+		PlainHTTP:             r.plainHTTP,
+		CAFile:                r.caFile,
+		InsecureSkipTLSVerify: r.insecureSkipTLSVerify,
+	}
+	if r.passwordStdin {
+		if r.username == "" {
+			return options, errors.New("--password-stdin requires --username")
+		}
+		password, err := ioutil.ReadAll(in)
+		if err != nil {
+			return options, fmt.Errorf("reading stdin: %v", err)
+		}
+		options.Password = strings.TrimRight(string(password), "\r\n")
+	}
+	return options, nil
+}
+
+// containsStdin returns whether one of the parameter files is read from stdin
+func containsStdin(parameterFiles []string) bool {
+	for _, f := range parameterFiles {
+		if f == "-" {
+			return true
+		}
+	}
+	return false
+}
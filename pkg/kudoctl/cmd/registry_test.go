@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kudobuilder/kudo/pkg/kudoctl/cmd/install"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/oci"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryFlags(t *testing.T) {
+	tests := []struct {
+		name     string
+		flags    registryFlags
+		expected oci.Options
+		err      string
+	}{
+		{"no flags", registryFlags{}, oci.Options{}, ""},
+		{"password from stdin", registryFlags{username: "admin", passwordStdin: true}, oci.Options{Username: "admin", Password: "secret"}, ""},
+		{"password without username", registryFlags{passwordStdin: true}, oci.Options{}, "--password-stdin requires --username"},
+		{"tls", registryFlags{plainHTTP: true, caFile: "ca.pem", insecureSkipTLSVerify: true}, oci.Options{PlainHTTP: true, CAFile: "ca.pem", InsecureSkipTLSVerify: true}, ""},
+	}
+
+	for _, tt := range tests {
+		options, err := tt.flags.options(strings.NewReader("secret\n"))
+		if tt.err != "" {
+			assert.EqualError(t, err, tt.err, tt.name)
+			continue
+		}
+		assert.NoError(t, err, tt.name)
+		assert.Equal(t, tt.expected, options, tt.name)
+	}
+}
+
+func TestInstallUsesRegistryOptions(t *testing.T) {
+	_, err := install.GetPackageCRDs("oci://registry.example.com/operators/zookeeper:0.1.0", "", nil, oci.Options{CAFile: "/missing/ca.pem"})
+	assert.EqualError(t, err, "reading CA file: open /missing/ca.pem: no such file or directory")
+}
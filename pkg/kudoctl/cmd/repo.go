@@ -11,20 +11,28 @@ import (
 const repoDesc = `
 This command consists of multiple sub-commands to interact with KUDO repositories.
 
-It can be used to add, remove, list, and index kudo repositories.
+It can be used to add, remove, list, update, index and serve kudo repositories.
+
+Operators are installed from the repository with the highest priority that provides them. An operator
+can be pinned to a repository to always install it from there.
 `
 
 const examples = `  kubectl kudo repo add [NAME] [REPO_URL]
   kubectl kudo repo remmove
   kubectl kudo repo list
-  kubectl kudo repo context [NAME]
+  kubectl kudo repo update [NAME...]
+  kubectl kudo repo pin [OPERATOR] [NAME]
+  kubectl kudo repo unpin [OPERATOR]
+  kubectl kudo repo deprecate [OPERATOR] [VERSION]
+  kubectl kudo repo yank [OPERATOR] [VERSION]
+  kubectl kudo repo serve [DIR]
 `
 
 // newRepoCmd for repo commands such as building a repo index
 func newRepoCmd(fs afero.Fs, out io.Writer) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "repo [FLAGS] add|remove|list|index [ARGS]",
-		Short:   "Add, list, remove, and index kudo repositories.",
+		Use:     "repo [FLAGS] add|remove|list|update|index|pin|unpin|deprecate|yank|serve [ARGS]",
+		Short:   "Add, list, remove, update and index kudo repositories.",
 		Long:    repoDesc,
 		Example: examples,
 	}
@@ -33,7 +41,13 @@ func newRepoCmd(fs afero.Fs, out io.Writer) *cobra.Command {
 	cmd.AddCommand(newRepoListCmd(fs, out))
 	cmd.AddCommand(newRepoAddCmd(fs, out))
 	cmd.AddCommand(newRepoRemoveCmd(fs, out))
+	cmd.AddCommand(newRepoUpdateCmd(fs, out))
 	cmd.AddCommand(newRepoContextCmd(fs))
+	cmd.AddCommand(newRepoPinCmd(fs, out))
+	cmd.AddCommand(newRepoUnpinCmd(fs, out))
+	cmd.AddCommand(newRepoDeprecateCmd(fs, out))
+	cmd.AddCommand(newRepoYankCmd(fs, out))
+	cmd.AddCommand(newRepoServeCmd(fs, out))
 
 	return cmd
 }
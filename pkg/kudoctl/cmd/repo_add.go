@@ -4,7 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
 
+	"github.com/kudobuilder/kudo/pkg/kudoctl/bundle"
 	"github.com/kudobuilder/kudo/pkg/kudoctl/kudohome"
 	"github.com/kudobuilder/kudo/pkg/kudoctl/util/repo"
 
@@ -12,18 +16,62 @@ import (
 	"github.com/spf13/cobra"
 )
 
+const repoAddExample = `  # Add a public repository
+  kubectl kudo repo add community https://kudo-repository.storage.googleapis.com/0.7.0
+
+  # Add a repository which takes precedence over the community repository
+  kubectl kudo repo add internal https://operators.example.com --priority 10
+
+  # Add a private repository with basic auth, the password is read from stdin
+  cat password.txt | kubectl kudo repo add private https://operators.example.com --username admin --password-stdin
+
+  # Add a private repository with a bearer token and a custom CA
+  kubectl kudo repo add private https://operators.example.com --token-stdin --ca-file ca.pem < token.txt
+
+  # Add a repository that requires a client certificate
+  kubectl kudo repo add private https://operators.example.com --cert-file client.pem --key-file client-key.pem`
+
 type repoAddCmd struct {
-	name      string
-	url       string
-	home      kudohome.Home
-	skipCheck bool
+	name        string
+	url         string
+	home        kudohome.Home
+	skipCheck   bool
+	priority    int
+	trustedKeys []string
 
+	username              string
+	passwordStdin         bool
+	tokenStdin            bool
+	caFile                string
+	certFile              string
+	keyFile               string
+	insecureSkipTLSVerify bool
+
+	in  io.Reader
 	out io.Writer
 	fs  afero.Fs
 }
 
 func (addCmd repoAddCmd) run() error {
-	if err := addRepository(addCmd.fs, addCmd.name, addCmd.url, addCmd.home, addCmd.skipCheck); err != nil {
+	config := &repo.Configuration{
+		URL:      addCmd.url,
+		Name:     addCmd.name,
+		Priority: addCmd.priority,
+	}
+	for _, path := range addCmd.trustedKeys {
+		key, err := afero.ReadFile(addCmd.fs, path)
+		if err != nil {
+			return fmt.Errorf("reading trusted key: %v", err)
+		}
+		if _, err := bundle.ParsePublicKey(key); err != nil {
+			return fmt.Errorf("trusted key %s: %v", path, err)
+		}
+		config.TrustedKeys = append(config.TrustedKeys, string(key))
+	}
+	if err := addCmd.configureAuth(config); err != nil {
+		return err
+	}
+	if err := addRepository(addCmd.fs, config, addCmd.home, addCmd.skipCheck); err != nil {
 		return err
 	}
 	fmt.Fprintf(addCmd.out, "%q has been added to your repositories\n", addCmd.name)
@@ -31,17 +79,65 @@ func (addCmd repoAddCmd) run() error {
 
 }
 
-func addRepository(fs afero.Fs, name string, url string, home kudohome.Home, force bool) error {
+// configureAuth sets the credentials and TLS settings of the repository. Secrets are read from stdin so they
+// don't end up in the shell history.
+func (addCmd repoAddCmd) configureAuth(config *repo.Configuration) error {
+	if addCmd.passwordStdin && addCmd.tokenStdin {
+		return errors.New("--password-stdin and --token-stdin can not be used together")
+	}
+	if (addCmd.username != "") != addCmd.passwordStdin {
+		return errors.New("--username and --password-stdin have to be used together")
+	}
+
+	if addCmd.passwordStdin || addCmd.tokenStdin {
+		secret, err := ioutil.ReadAll(addCmd.in)
+		if err != nil {
+			return fmt.Errorf("reading stdin: %v", err)
+		}
+		s := strings.TrimRight(string(secret), "\r\n")
+		if s == "" {
+			return errors.New("no password or token provided on stdin")
+		}
+		if addCmd.passwordStdin {
+			config.Username = addCmd.username
+			config.Password = s
+		} else {
+			config.BearerToken = s
+		}
+	}
+
+	// files are referenced from the repositories file and need to be found from any working directory
+	for _, f := range []struct {
+		path   string
+		target *string
+	}{
+		{addCmd.caFile, &config.CAFile},
+		{addCmd.certFile, &config.CertFile},
+		{addCmd.keyFile, &config.KeyFile},
+	} {
+		if f.path == "" {
+			continue
+		}
+		abs, err := filepath.Abs(f.path)
+		if err != nil {
+			return err
+		}
+		*f.target = abs
+	}
+	config.InsecureSkipTLSVerify = addCmd.insecureSkipTLSVerify
+	return nil
+}
+
+func addRepository(fs afero.Fs, config *repo.Configuration, home kudohome.Home, force bool) error {
+	if config.Name == "" || config.Name == "." || config.Name == ".." {
+		return fmt.Errorf("repository name (%s) is invalid, please specify a different name", config.Name)
+	}
 	repos, err := repo.LoadRepositories(fs, home.RepositoryFile())
 	if err != nil {
 		return err
 	}
-	if repos.GetConfiguration(name) != nil {
-		return fmt.Errorf("repository name (%s) already exists, please specify a different name", name)
-	}
-	config := &repo.Configuration{
-		URL:  url,
-		Name: name,
+	if repos.GetConfiguration(config.Name) != nil {
+		return fmt.Errorf("repository name (%s) already exists, please specify a different name", config.Name)
 	}
 	client, err := repo.NewClient(config)
 	if err != nil {
@@ -52,20 +148,28 @@ func addRepository(fs afero.Fs, name string, url string, home kudohome.Home, for
 		// valid the url and that we can pull and index is valid
 		_, err = client.DownloadIndexFile()
 		if err != nil {
-			return fmt.Errorf("looks like %q is not a valid operator repository or cannot be reached: %s", url, err.Error())
+			return fmt.Errorf("looks like %q is not a valid operator repository or cannot be reached: %s", config.URL, err.Error())
 		}
 	}
 	repos.Add(config)
 
-	return repos.WriteFile(fs, home.RepositoryFile(), 0644)
+	if !config.HasCredentials() {
+		return repos.WriteFile(fs, home.RepositoryFile(), 0644)
+	}
+	// the repositories file now contains secrets
+	if err := repos.WriteFile(fs, home.RepositoryFile(), 0600); err != nil {
+		return err
+	}
+	return fs.Chmod(home.RepositoryFile(), 0600)
 }
 
 func newRepoAddCmd(fs afero.Fs, out io.Writer) *cobra.Command {
 	add := &repoAddCmd{out: out}
 
 	cmd := &cobra.Command{
-		Use:   "add [flags] [NAME] [URL]",
-		Short: "Add an operator repository",
+		Use:     "add [flags] [NAME] [URL]",
+		Short:   "Add an operator repository",
+		Example: repoAddExample,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) < 2 {
 				return errors.New("this command needs 2. name and url of the operator repository")
@@ -75,12 +179,22 @@ func newRepoAddCmd(fs afero.Fs, out io.Writer) *cobra.Command {
 			add.url = args[1]
 			add.home = Settings.Home
 			add.fs = fs
+			add.in = cmd.InOrStdin()
 
 			return add.run()
 		},
 	}
 	f := cmd.Flags()
 	f.BoolVarP(&add.skipCheck, "skip-check", "f", false, "Skip URL and index file validation.")
+	f.IntVar(&add.priority, "priority", 0, "Priority of the repository. Operators provided by several repositories are installed from the one with the highest priority.")
+	f.StringVar(&add.username, "username", "", "Username for basic auth with the repository.")
+	f.BoolVar(&add.passwordStdin, "password-stdin", false, "Read the password for basic auth from stdin.")
+	f.BoolVar(&add.tokenStdin, "token-stdin", false, "Read a bearer token for the repository from stdin.")
+	f.StringVar(&add.caFile, "ca-file", "", "Path to a PEM encoded CA bundle to verify the certificate of the repository.")
+	f.StringVar(&add.certFile, "cert-file", "", "Path to a PEM encoded client certificate for the repository.")
+	f.StringVar(&add.keyFile, "key-file", "", "Path to the PEM encoded key of the client certificate.")
+	f.BoolVar(&add.insecureSkipTLSVerify, "insecure-skip-tls-verify", false, "Skip verification of the certificate of the repository.")
+	f.StringArrayVar(&add.trustedKeys, "trusted-key", nil, "Path to a PEM encoded ed25519 public key. Packages of the repository have to be signed by one of the trusted keys.")
 
 	return cmd
 }
@@ -3,11 +3,13 @@ package cmd
 import (
 	"bytes"
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/kudobuilder/kudo/pkg/kudoctl/kudohome"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/util/repo"
 
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
@@ -34,6 +36,21 @@ func TestAddDupRepo(t *testing.T) {
 	assert.EqualError(t, err, "repository name (community) already exists, please specify a different name")
 }
 
+func TestAddInvalidRepoName(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	out := &bytes.Buffer{}
+	home := kudohome.Home("kudo_home")
+	i := &initCmd{fs: fs, out: out, home: home}
+	if err := i.initialize(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{".", ".."} {
+		cmd := &repoAddCmd{fs: fs, out: out, home: home, name: name, url: "doesn't matter", skipCheck: true}
+		assert.EqualError(t, cmd.run(), "repository name ("+name+") is invalid, please specify a different name")
+	}
+}
+
 func TestAddBadURLRepo(t *testing.T) {
 	//	setup
 	fs := afero.NewMemMapFs()
@@ -129,3 +146,57 @@ func TestAddArgValidation(t *testing.T) {
 		assert.EqualError(t, err, test.errorMessage)
 	}
 }
+
+func TestAddWithCredentials(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	out := &bytes.Buffer{}
+
+	home := kudohome.Home("kudo_home")
+	if err := fs.Mkdir(home.String(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	i := &initCmd{fs: fs, out: out, home: home}
+	if err := i.initialize(); err != nil {
+		t.Error(err)
+	}
+
+	var tests = []struct {
+		name         string
+		cmd          repoAddCmd
+		stdin        string
+		username     string
+		password     string
+		token        string
+		errorMessage string
+	}{
+		{name: "basic auth", cmd: repoAddCmd{name: "basic", username: "admin", passwordStdin: true}, stdin: "secret\n", username: "admin", password: "secret"},
+		{name: "bearer token", cmd: repoAddCmd{name: "token", tokenStdin: true}, stdin: "token\n", token: "token"},
+		{name: "username without password", cmd: repoAddCmd{name: "nopassword", username: "admin"}, errorMessage: "--username and --password-stdin have to be used together"},
+		{name: "password and token", cmd: repoAddCmd{name: "both", username: "admin", passwordStdin: true, tokenStdin: true}, errorMessage: "--password-stdin and --token-stdin can not be used together"},
+		{name: "empty stdin", cmd: repoAddCmd{name: "empty", tokenStdin: true}, errorMessage: "no password or token provided on stdin"},
+	}
+
+	for _, tt := range tests {
+		cmd := tt.cmd
+		cmd.fs, cmd.out, cmd.home, cmd.url, cmd.skipCheck = fs, out, home, "https://operators.example.com", true
+		cmd.in = strings.NewReader(tt.stdin)
+
+		err := cmd.run()
+		if tt.errorMessage != "" {
+			assert.EqualError(t, err, tt.errorMessage, tt.name)
+			continue
+		}
+		assert.NoError(t, err, tt.name)
+
+		repos, err := repo.LoadRepositories(fs, home.RepositoryFile())
+		assert.NoError(t, err, tt.name)
+		config := repos.GetConfiguration(tt.cmd.name)
+		assert.Equal(t, tt.username, config.Username, tt.name)
+		assert.Equal(t, tt.password, config.Password, tt.name)
+		assert.Equal(t, tt.token, config.BearerToken, tt.name)
+
+		info, err := fs.Stat(home.RepositoryFile())
+		assert.NoError(t, err, tt.name)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), tt.name)
+	}
+}
@@ -24,6 +24,8 @@ func newRepoContextCmd(fs afero.Fs) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "context [flags] [NAME]",
 		Short: "Set default for operator repository context",
+		// operators are resolved across all repositories by priority and pins since repositories have priorities
+		Deprecated: "the context is not used to resolve operators, use repository priorities or 'kudo repo pin' instead",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
 				return fmt.Errorf("need at least one argument, name of operator repository")
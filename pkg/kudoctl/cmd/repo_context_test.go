@@ -36,3 +36,12 @@ func TestRepoContextInvalidArg(t *testing.T) {
 	err = cmd.run()
 	assert.EqualError(t, err, `no repo named "foo" found`)
 }
+
+func TestRepoContextIsDeprecated(t *testing.T) {
+	out := &bytes.Buffer{}
+	cmd := newRepoCmd(afero.NewMemMapFs(), out)
+	cmd.SetOutput(out)
+	cmd.SetArgs([]string{"context"})
+	assert.Error(t, cmd.Execute())
+	assert.Contains(t, out.String(), `Command "context" is deprecated, the context is not used to resolve operators`)
+}
@@ -21,6 +21,12 @@ This tool is used for creating an 'index.yaml' file for a KUDO package repositor
 set an absolute URL to the operators, use '--url' or '--url-repo' flag. The '--url-repo'
 will look up the the url of the named repo and will provide the absolute URL by repo name.
 
+If the directory already contains an 'index.yaml', it is updated: new packages are added and
+existing entries are preserved together with their digests and their deprecated and removed
+flags. Published versions are expected to be immutable, a package that changed since it was
+indexed is an error unless '--overwrite' is used. Use 'kudo repo deprecate' and 'kudo repo yank'
+to mark published versions.
+
 To merge the generated index with an existing index file, use the '--merge' flag. In this 
 case, the operator packages found in the current directory will be merged into the existing
 index, with local operators taking priority over existing operators. No content of the existing
@@ -81,7 +87,7 @@ func newRepoIndexCmd(fs afero.Fs, out io.Writer, time *time.Time) *cobra.Command
 	f.StringVar(&index.urlRepoName, "url-repo", "", "Name of the repo to use URL for operator urls")
 	f.StringVar(&index.mergePath, "merge", "", "URL or path location of index file to merge with")
 	f.StringVar(&index.mergeRepoName, "merge-repo", "", "Name of the repo to use as merge URL")
-	f.BoolVarP(&index.overwrite, "overwrite", "w", false, "Overwrite existing package")
+	f.BoolVarP(&index.overwrite, "overwrite", "w", false, "Replace entries of an existing index file whose package changed since it was indexed")
 
 	return cmd
 }
@@ -113,7 +119,8 @@ func (ri *repoIndexCmd) run() error {
 		ri.url = config.URL
 	}
 
-	target, err := files.FullPathToTarget(ri.fs, ri.path, "index.yaml", ri.overwrite)
+	// an existing index file is updated instead of replaced
+	target, err := files.FullPathToTarget(ri.fs, ri.path, "index.yaml", true)
 	if err != nil {
 		return err
 	}
@@ -122,6 +129,19 @@ func (ri *repoIndexCmd) run() error {
 	if err != nil {
 		return err
 	}
+	action := "created"
+	if exists, _ := afero.Exists(ri.fs, target); exists {
+		existing, err := readIndexFile(ri.fs, target)
+		if err != nil {
+			return err
+		}
+		if err := existing.Update(index, ri.overwrite); err != nil {
+			return fmt.Errorf("updating index %v: %v", target, err)
+		}
+		existing.Generated = ri.time
+		index = existing
+		action = "updated"
+	}
 	// if we have a merge path... lets get it
 	if ri.mergePath != "" || ri.mergeRepoName != "" {
 
@@ -141,11 +161,25 @@ func (ri *repoIndexCmd) run() error {
 		merge(index, mergeIndex)
 	}
 
-	index.WriteFile(ri.fs, target)
-	fmt.Fprintf(ri.out, "index %v created.\n", target)
+	if err := index.WriteFile(ri.fs, target); err != nil {
+		return err
+	}
+	fmt.Fprintf(ri.out, "index %v %s.\n", target, action)
 	return nil
 }
 
+func readIndexFile(fs afero.Fs, path string) (*repo.IndexFile, error) {
+	b, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, err
+	}
+	index, err := repo.ParseIndexFile(b)
+	if err != nil {
+		return nil, fmt.Errorf("parsing index %v: %v", path, err)
+	}
+	return index, nil
+}
+
 func merge(index *repo.IndexFile, mergeIndex *repo.IndexFile) {
 	// index is the master, any dups in the merged in index will have what is local replace those entries
 	for _, pvs := range mergeIndex.Entries {
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sort"
 
 	"github.com/kudobuilder/kudo/pkg/kudoctl/kudohome"
 	"github.com/kudobuilder/kudo/pkg/kudoctl/util/repo"
@@ -42,14 +43,30 @@ func (a *repoListCmd) run(fs afero.Fs) error {
 		return errors.New("no repositories to show")
 	}
 	table := uitable.New()
-	table.AddRow("NAME", "URL")
+	table.AddRow("NAME", "URL", "PRIORITY")
 	for _, re := range repos.Repositories {
 		if re.Name == repos.Context {
-			table.AddRow(fmt.Sprintf("*%s", re.Name), re.URL)
+			table.AddRow(fmt.Sprintf("*%s", re.Name), re.URL, re.Priority)
 		} else {
-			table.AddRow(re.Name, re.URL)
+			table.AddRow(re.Name, re.URL, re.Priority)
 		}
 	}
 	fmt.Fprintln(a.out, table)
+
+	if len(repos.Pins) == 0 {
+		return nil
+	}
+	operators := make([]string, 0, len(repos.Pins))
+	for operator := range repos.Pins {
+		operators = append(operators, operator)
+	}
+	sort.Strings(operators)
+	pins := uitable.New()
+	pins.AddRow("OPERATOR", "PINNED TO")
+	for _, operator := range operators {
+		pins.AddRow(operator, repos.Pins[operator])
+	}
+	fmt.Fprintln(a.out)
+	fmt.Fprintln(a.out, pins)
 	return nil
 }
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/kudobuilder/kudo/pkg/kudoctl/kudohome"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/util/repo"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+type repoPinCmd struct {
+	operator string
+	name     string
+	home     kudohome.Home
+
+	out io.Writer
+	fs  afero.Fs
+}
+
+func newRepoPinCmd(fs afero.Fs, out io.Writer) *cobra.Command {
+	pin := &repoPinCmd{out: out}
+
+	cmd := &cobra.Command{
+		Use:   "pin [flags] [OPERATOR] [NAME]",
+		Short: "Always install an operator from the given operator repository",
+		Example: `  # Install kafka from the internal repository, even if other repositories provide it as well
+  kubectl kudo repo pin kafka internal`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return errors.New("this command needs 2. name of the operator and name of the operator repository")
+			}
+
+			pin.operator = args[0]
+			pin.name = args[1]
+			pin.home = Settings.Home
+			pin.fs = fs
+			return pin.run()
+		},
+	}
+
+	return cmd
+}
+
+func (p *repoPinCmd) run() error {
+	repos, err := repo.LoadRepositories(p.fs, p.home.RepositoryFile())
+	if err != nil {
+		return err
+	}
+	if repos.GetConfiguration(p.name) == nil {
+		return fmt.Errorf("no repo named %q found", p.name)
+	}
+	if err := repos.Pin(p.operator, p.name); err != nil {
+		return err
+	}
+	if err := repos.WriteFile(p.fs, p.home.RepositoryFile(), 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(p.out, "%q has been pinned to repository %q\n", p.operator, p.name)
+	return nil
+}
+
+type repoUnpinCmd struct {
+	operator string
+	home     kudohome.Home
+
+	out io.Writer
+	fs  afero.Fs
+}
+
+func newRepoUnpinCmd(fs afero.Fs, out io.Writer) *cobra.Command {
+	unpin := &repoUnpinCmd{out: out}
+
+	cmd := &cobra.Command{
+		Use:   "unpin [flags] [OPERATOR]",
+		Short: "Remove the pin of an operator to an operator repository",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("this command needs 1. name of the operator")
+			}
+
+			unpin.operator = args[0]
+			unpin.home = Settings.Home
+			unpin.fs = fs
+			return unpin.run()
+		},
+	}
+
+	return cmd
+}
+
+func (u *repoUnpinCmd) run() error {
+	repos, err := repo.LoadRepositories(u.fs, u.home.RepositoryFile())
+	if err != nil {
+		return err
+	}
+	if !repos.Unpin(u.operator) {
+		return fmt.Errorf("operator %q is not pinned", u.operator)
+	}
+	if err := repos.WriteFile(u.fs, u.home.RepositoryFile(), 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(u.out, "%q has been unpinned\n", u.operator)
+	return nil
+}
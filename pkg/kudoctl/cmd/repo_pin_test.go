@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kudobuilder/kudo/pkg/kudoctl/kudohome"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/util/repo"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepoPin(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	out := &bytes.Buffer{}
+
+	home := kudohome.Home("kudo_home")
+	if err := fs.Mkdir(home.String(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	i := &initCmd{fs: fs, out: out, home: home}
+	if err := i.initialize(); err != nil {
+		t.Error(err)
+	}
+
+	pin := &repoPinCmd{fs: fs, out: out, home: home, operator: "kafka", name: "internal"}
+	assert.EqualError(t, pin.run(), `no repo named "internal" found`)
+
+	pin.name = "community"
+	assert.NoError(t, pin.run())
+	repos, _ := repo.LoadRepositories(fs, home.RepositoryFile())
+	assert.Equal(t, map[string]string{"kafka": "community"}, repos.Pins)
+
+	unpin := &repoUnpinCmd{fs: fs, out: out, home: home, operator: "kafka"}
+	assert.NoError(t, unpin.run())
+	assert.EqualError(t, unpin.run(), `operator "kafka" is not pinned`)
+	repos, _ = repo.LoadRepositories(fs, home.RepositoryFile())
+	assert.Empty(t, repos.Pins)
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/kudobuilder/kudo/pkg/kudoctl/util/repo"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+const (
+	repoServeDesc = `
+Serve a directory of KUDO operator packages as an operator repository over HTTP.
+
+The index file is generated from the *.tgz packages in the directory and generated again whenever packages are
+added, changed or removed, so packages can be created with 'kudo package' into the served directory while the
+server is running. An index.yaml in the directory is updated with the packages instead, versions removed or
+deprecated in it with 'kudo repo yank' or 'kudo repo deprecate' are served as such.`
+
+	repoServeExample = `  # Serve the packages in /opt/repo and add the repository
+  kubectl kudo repo serve /opt/repo --address :8080
+  kubectl kudo repo add local http://localhost:8080`
+)
+
+type repoServeCmd struct {
+	path    string
+	address string
+	url     string
+	out     io.Writer
+	fs      afero.Fs
+}
+
+// newRepoServeCmd serves a directory of operator packages as a repository
+func newRepoServeCmd(fs afero.Fs, out io.Writer) *cobra.Command {
+	serve := &repoServeCmd{out: out, fs: fs}
+	cmd := &cobra.Command{
+		Use:     "serve [flags] <DIR>",
+		Short:   "Serve a directory of KUDO operator packages as a repository",
+		Long:    repoServeDesc,
+		Example: repoServeExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("expecting exactly one argument - directory containing the operator packages to serve")
+			}
+			serve.path = args[0]
+			return serve.run()
+		},
+		SilenceUsage: true,
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&serve.address, "address", ":8080", "Address to listen on.")
+	f.StringVar(&serve.url, "url", "", "URL of the operators to reference in the index file. (default is the URL the repository is reached with)")
+	return cmd
+}
+
+func (s *repoServeCmd) run() error {
+	fi, err := s.fs.Stat(s.path)
+	if err != nil || !fi.IsDir() {
+		return fmt.Errorf("%q is not a directory", s.path)
+	}
+
+	fmt.Fprintf(s.out, "Serving operator repository %s on %s\n", s.path, s.address)
+	return http.ListenAndServe(s.address, repo.NewDirectoryServer(s.fs, s.path, s.url, s.out))
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/kudobuilder/kudo/pkg/kudoctl/kudohome"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/util/repo"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+const repoUpdateDesc = `
+Update the locally cached index files of all operator repositories, or only of the named ones.
+Cached index files and packages are used by 'kudo install --offline'.
+`
+
+type repoUpdateCmd struct {
+	names []string
+	home  kudohome.Home
+	out   io.Writer
+	fs    afero.Fs
+}
+
+func newRepoUpdateCmd(fs afero.Fs, out io.Writer) *cobra.Command {
+	update := &repoUpdateCmd{out: out, fs: fs}
+
+	cmd := &cobra.Command{
+		Use:     "update [flags] [NAME...]",
+		Aliases: []string{"up"},
+		Short:   "Update the cached index files of operator repositories",
+		Long:    repoUpdateDesc,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			update.names = args
+			update.home = Settings.Home
+			return update.run()
+		},
+	}
+
+	return cmd
+}
+
+func (u *repoUpdateCmd) run() error {
+	repos, err := repo.LoadRepositories(u.fs, u.home.RepositoryFile())
+	if err != nil {
+		return err
+	}
+
+	configs := repos.Repositories
+	if len(u.names) > 0 {
+		configs = []*repo.Configuration{}
+		for _, name := range u.names {
+			config := repos.GetConfiguration(name)
+			if config == nil {
+				return fmt.Errorf("no repo named %q found", name)
+			}
+			configs = append(configs, config)
+		}
+	}
+
+	failed := 0
+	for _, config := range configs {
+		client, err := repo.NewClient(config)
+		if err == nil {
+			client.Cache = repo.NewCache(u.fs, u.home)
+			_, err = client.DownloadIndexFile()
+		}
+		if err != nil {
+			fmt.Fprintf(u.out, "Unable to get an update from the %q repository (%s): %v\n", config.Name, config.URL, err)
+			failed++
+			continue
+		}
+		fmt.Fprintf(u.out, "Successfully got an update from the %q repository\n", config.Name)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d repositories could not be updated", failed, len(configs))
+	}
+	return nil
+}
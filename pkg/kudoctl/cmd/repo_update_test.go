@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kudobuilder/kudo/pkg/kudoctl/util/repo"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepoUpdate(t *testing.T) {
+	server := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	defer server.Close()
+
+	fs := afero.NewMemMapFs()
+	out := &bytes.Buffer{}
+	home := newTestRepoHome(t, fs, server.URL)
+
+	update := &repoUpdateCmd{home: home, out: out, fs: fs}
+	assert.NoError(t, update.run())
+	assert.Contains(t, out.String(), `Successfully got an update from the "test" repository`)
+
+	content, _, err := repo.NewCache(fs, home).Index("test")
+	assert.NoError(t, err)
+	assert.NotNil(t, content, "index file is cached")
+
+	update = &repoUpdateCmd{names: []string{"missing"}, home: home, out: out, fs: fs}
+	assert.EqualError(t, update.run(), `no repo named "missing" found`)
+}
+
+func TestRepoUpdateUnreachable(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	out := &bytes.Buffer{}
+	home := newTestRepoHome(t, fs, "http://127.0.0.1:0")
+
+	update := &repoUpdateCmd{home: home, out: out, fs: fs}
+	assert.EqualError(t, update.run(), "1 of 1 repositories could not be updated")
+	assert.Contains(t, out.String(), `Unable to get an update from the "test" repository`)
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+const (
+	repoDeprecateExample = `  # Mark version 0.1.0 of kafka as deprecated in the index file of the current directory
+  kubectl kudo repo deprecate kafka 0.1.0
+
+  # Undo the deprecation
+  kubectl kudo repo deprecate kafka 0.1.0 --undo`
+
+	repoYankExample = `  # Yank version 0.1.0 of kafka from the repository in /opt/repo, it can not be installed anymore
+  kubectl kudo repo yank kafka 0.1.0 --index-file /opt/repo/index.yaml`
+)
+
+// repoMarkCmd sets the deprecated or removed flag of a package version in an index file
+type repoMarkCmd struct {
+	name      string
+	version   string
+	indexFile string
+	undo      bool
+	yank      bool
+
+	out io.Writer
+	fs  afero.Fs
+}
+
+func newRepoDeprecateCmd(fs afero.Fs, out io.Writer) *cobra.Command {
+	return newRepoMarkCmd(fs, out, false, &cobra.Command{
+		Use:     "deprecate [flags] [NAME] [VERSION]",
+		Short:   "Mark a version of an operator in an index file as deprecated",
+		Long:    "Mark a version of an operator in an index file as deprecated. Deprecated versions can still be installed but show a warning.",
+		Example: repoDeprecateExample,
+	})
+}
+
+func newRepoYankCmd(fs afero.Fs, out io.Writer) *cobra.Command {
+	return newRepoMarkCmd(fs, out, true, &cobra.Command{
+		Use:     "yank [flags] [NAME] [VERSION]",
+		Short:   "Mark a version of an operator in an index file as removed",
+		Long:    "Mark a version of an operator in an index file as removed. Removed versions stay in the index file but can not be installed anymore.",
+		Example: repoYankExample,
+	})
+}
+
+func newRepoMarkCmd(fs afero.Fs, out io.Writer, yank bool, cmd *cobra.Command) *cobra.Command {
+	mark := &repoMarkCmd{out: out, fs: fs, yank: yank}
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			return errors.New("this command needs 2. name and version of the operator")
+		}
+		mark.name = args[0]
+		mark.version = args[1]
+		return mark.run()
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&mark.indexFile, "index-file", "index.yaml", "Path of the index file to modify.")
+	f.BoolVar(&mark.undo, "undo", false, "Remove the mark again.")
+	return cmd
+}
+
+func (m *repoMarkCmd) run() error {
+	index, err := readIndexFile(m.fs, m.indexFile)
+	if err != nil {
+		return err
+	}
+
+	if m.yank {
+		err = index.SetRemoved(m.name, m.version, !m.undo)
+	} else {
+		err = index.SetDeprecated(m.name, m.version, !m.undo)
+	}
+	if err != nil {
+		return err
+	}
+	if err := index.WriteFile(m.fs, m.indexFile); err != nil {
+		return err
+	}
+
+	mark := "deprecated"
+	if m.yank {
+		mark = "removed"
+	}
+	if m.undo {
+		mark = "no longer " + mark
+	}
+	fmt.Fprintf(m.out, "%s-%s is %s in %v\n", m.name, m.version, mark, m.indexFile)
+	return nil
+}
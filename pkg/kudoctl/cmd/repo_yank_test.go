@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kudobuilder/kudo/pkg/kudoctl/util/repo"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepoYankAndDeprecate(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	index := `apiVersion: v1
+entries:
+  kafka:
+  - name: kafka
+    version: 0.1.0
+`
+	if err := afero.WriteFile(fs, "/opt/index.yaml", []byte(index), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out := &bytes.Buffer{}
+
+	yank := &repoMarkCmd{name: "kafka", version: "0.1.0", indexFile: "/opt/index.yaml", yank: true, fs: fs, out: out}
+	assert.NoError(t, yank.run())
+	deprecate := &repoMarkCmd{name: "kafka", version: "0.1.0", indexFile: "/opt/index.yaml", fs: fs, out: out}
+	assert.NoError(t, deprecate.run())
+	assert.Equal(t, "kafka-0.1.0 is removed in /opt/index.yaml\nkafka-0.1.0 is deprecated in /opt/index.yaml\n", out.String())
+
+	pv := readTestIndexEntry(t, fs)
+	assert.True(t, pv.Removed)
+	assert.True(t, pv.Deprecated)
+
+	yank.undo = true
+	assert.NoError(t, yank.run())
+	assert.False(t, readTestIndexEntry(t, fs).Removed)
+
+	missing := &repoMarkCmd{name: "kafka", version: "0.2.0", indexFile: "/opt/index.yaml", yank: true, fs: fs, out: out}
+	assert.EqualError(t, missing.run(), "no operator version found for kafka-0.2.0")
+}
+
+func readTestIndexEntry(t *testing.T, fs afero.Fs) *repo.PackageVersion {
+	index, err := readIndexFile(fs, "/opt/index.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return index.Entries["kafka"][0]
+}
@@ -38,6 +38,12 @@ and serves as an API aggregation layer.
 	# View all plan history of a specific package
 	kubectl kudo plan history [flags]
 
+	# Search operators in all configured repositories
+	kubectl kudo search <term>
+
+	# Show details of an operator in a repository
+	kubectl kudo info <name> [flags]
+
 	# Run integration tests against a Kubernetes cluster or mocked control plane.
 	kubectl kudo test
 
@@ -57,11 +63,13 @@ and serves as an API aggregation layer.
 	cmd.AddCommand(newInstallCmd(fs))
 	cmd.AddCommand(newInitCmd(fs, cmd.OutOrStdout()))
 	cmd.AddCommand(newUpgradeCmd(fs))
-	cmd.AddCommand(newUpdateCmd())
+	cmd.AddCommand(newUpdateCmd(fs))
 	cmd.AddCommand(newPackageCmd(fs, cmd.OutOrStdout()))
 	cmd.AddCommand(newGetCmd())
 	cmd.AddCommand(newPlanCmd())
 	cmd.AddCommand(newRepoCmd(fs, cmd.OutOrStdout()))
+	cmd.AddCommand(newSearchCmd(fs, cmd.OutOrStdout()))
+	cmd.AddCommand(newInfoCmd(fs, cmd.OutOrStdout()))
 	cmd.AddCommand(newTestCmd())
 	cmd.AddCommand(newVersionCmd())
 
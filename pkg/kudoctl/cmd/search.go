@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/kudobuilder/kudo/pkg/kudoctl/kudohome"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/util/repo"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+const searchDesc = `
+Search all configured repositories for operators whose name, description or maintainers contain the given term.
+Only the most recent version of every operator is shown. Without a term all operators are listed.
+`
+
+const searchExample = `  # Search for operators related to kafka
+  kubectl kudo search kafka
+
+  # List all operators of all repositories
+  kubectl kudo search`
+
+type searchCmd struct {
+	term   string
+	home   kudohome.Home
+	out    io.Writer
+	errOut io.Writer
+	fs     afero.Fs
+}
+
+func newSearchCmd(fs afero.Fs, out io.Writer) *cobra.Command {
+	search := &searchCmd{out: out, fs: fs}
+
+	cmd := &cobra.Command{
+		Use:     "search [TERM]",
+		Short:   "Search operators in all configured repositories",
+		Long:    searchDesc,
+		Example: searchExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 1 {
+				return errors.New("this command accepts at most one search term")
+			}
+			if len(args) == 1 {
+				search.term = args[0]
+			}
+			search.home = Settings.Home
+			search.errOut = cmd.ErrOrStderr()
+			return search.run()
+		},
+	}
+
+	return cmd
+}
+
+func (s *searchCmd) run() error {
+	repos, err := repo.LoadRepositories(s.fs, s.home.RepositoryFile())
+	if err != nil {
+		// this allows for no client init, same as when installing
+		repos = repo.NewRepositories()
+	}
+
+	table := uitable.New()
+	table.MaxColWidth = 80
+	table.AddRow("REPO", "NAME", "VERSION", "APP VERSION", "DESCRIPTION")
+	found := 0
+	for _, config := range repos.Repositories {
+		client, err := repo.NewClient(config)
+		if err != nil {
+			fmt.Fprintf(s.errOut, "Warning: skipping repository %q: %v\n", config.Name, err)
+			continue
+		}
+		client.Cache = repo.NewCache(s.fs, s.home)
+		index, err := client.DownloadIndexFile()
+		if err != nil {
+			fmt.Fprintf(s.errOut, "Warning: skipping repository %q: %v\n", config.Name, err)
+			continue
+		}
+		for _, pv := range index.Search(s.term) {
+			table.AddRow(config.Name, pv.Name, pv.Version, pv.AppVersion, pv.Description)
+			found++
+		}
+	}
+
+	if found == 0 {
+		fmt.Fprintf(s.out, "No operators found for %q\n", s.term)
+		return nil
+	}
+	fmt.Fprintln(s.out, table)
+	return nil
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kudobuilder/kudo/pkg/kudoctl/kudohome"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/util/repo"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestRepoHome initializes a KUDO home with a single repository named "test" pointing to url
+func newTestRepoHome(t *testing.T, fs afero.Fs, url string) kudohome.Home {
+	home := kudohome.Home("kudo_home")
+	i := &initCmd{fs: fs, out: &bytes.Buffer{}, home: home}
+	if err := i.initialize(); err != nil {
+		t.Fatal(err)
+	}
+	repos := &repo.Repositories{
+		RepoVersion:  repo.Version,
+		Context:      "test",
+		Repositories: []*repo.Configuration{{Name: "test", URL: url}},
+	}
+	if err := repos.WriteFile(fs, home.RepositoryFile(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return home
+}
+
+func TestSearch(t *testing.T) {
+	server := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		term     string
+		contains []string
+		missing  []string
+	}{
+		{"match by name", "redis", []string{"redis", "5.0.1"}, []string{"mysql"}},
+		{"match by maintainer ignoring case", "KEN", []string{"mysql"}, []string{"redis"}},
+		{"match all", "", []string{"mysql", "redis"}, nil},
+		{"no match", "cassandra", []string{`No operators found for "cassandra"`}, []string{"mysql", "redis"}},
+	}
+
+	for _, tt := range tests {
+		fs := afero.NewMemMapFs()
+		out := &bytes.Buffer{}
+		search := &searchCmd{term: tt.term, home: newTestRepoHome(t, fs, server.URL), out: out, errOut: out, fs: fs}
+
+		err := search.run()
+		assert.NoError(t, err, tt.name)
+		for _, s := range tt.contains {
+			assert.Contains(t, out.String(), s, tt.name)
+		}
+		for _, s := range tt.missing {
+			assert.NotContains(t, out.String(), s, tt.name)
+		}
+	}
+}
+
+func TestSearchUnreachableRepository(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	search := &searchCmd{term: "redis", home: newTestRepoHome(t, fs, "http://127.0.0.1:0"), out: out, errOut: errOut, fs: fs}
+
+	err := search.run()
+	assert.NoError(t, err)
+	assert.Contains(t, errOut.String(), `Warning: skipping repository "test"`)
+	assert.NotContains(t, out.String(), "Warning")
+}
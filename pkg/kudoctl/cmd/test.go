@@ -30,6 +30,9 @@ var (
 
       Run a Kubernetes control plane and KUDO and install manifests and CRDs for the running tests:
             kubectl kudo test --start-control-plane --start-kudo --crd-dir ./config/crds/ --manifests-dir ./test/manifests/ ./test/integration/
+
+      Run the tests bundled with an operator package in a KIND cluster, the package is installed for every test:
+            kubectl kudo test --start-kind --start-kudo --crd-dir ./config/crds/ --package ./my-operator
 `
 )
 
@@ -38,6 +41,7 @@ func newTestCmd() *cobra.Command {
 	configPath := ""
 	crdDir := ""
 	manifestDirs := []string{}
+	packages := []string{}
 	testToRun := ""
 	startControlPlane := false
 	startKIND := false
@@ -73,7 +77,7 @@ For more detailed documentation, visit: https://kudo.dev/docs/testing`,
 			if configPath == "" {
 				if _, err := os.Stat("kudo-test.yaml"); err == nil {
 					configPath = "kudo-test.yaml"
-				} else {
+				} else if len(args) == 0 && len(packages) == 0 {
 					return fmt.Errorf("kudo-test.yaml not found, provide either --config or arguments indicating the tests to load")
 				}
 			}
@@ -106,6 +110,10 @@ For more detailed documentation, visit: https://kudo.dev/docs/testing`,
 				options.ManifestDirs = manifestDirs
 			}
 
+			if isSet(flags, "package") {
+				options.Packages = packages
+			}
+
 			if isSet(flags, "start-control-plane") {
 				options.StartControlPlane = startControlPlane
 			}
@@ -155,8 +163,8 @@ For more detailed documentation, visit: https://kudo.dev/docs/testing`,
 				options.TestDirs = args
 			}
 
-			if len(options.TestDirs) == 0 {
-				return fmt.Errorf("no test directories provided, please provide either --config or test directories on the command line")
+			if len(options.TestDirs) == 0 && len(options.Packages) == 0 {
+				return fmt.Errorf("no test directories provided, please provide either --config, --package or test directories on the command line")
 			}
 
 			return nil
@@ -176,6 +184,7 @@ For more detailed documentation, visit: https://kudo.dev/docs/testing`,
 	testCmd.Flags().StringVar(&configPath, "config", "", "Path to file to load test settings from (must not be set with any other arguments).")
 	testCmd.Flags().StringVar(&crdDir, "crd-dir", "", "Directory to load CustomResourceDefinitions from prior to running the tests.")
 	testCmd.Flags().StringSliceVar(&manifestDirs, "manifest-dir", []string{}, "One or more directories containing manifests to apply before running the tests.")
+	testCmd.Flags().StringSliceVar(&packages, "package", []string{}, "One or more operator packages to install for every test, the tests in their tests directory are run as well.")
 	testCmd.Flags().StringVar(&testToRun, "test", "", "If set, the specific test case to run.")
 	testCmd.Flags().BoolVar(&startControlPlane, "start-control-plane", false, "Start a local Kubernetes control plane for the tests (requires etcd and kube-apiserver binaries, cannot be used with --start-kind).")
 	testCmd.Flags().BoolVar(&startKIND, "start-kind", false, "Start a KIND cluster for the tests (cannot be used with --start-control-plane).")
@@ -8,6 +8,7 @@ import (
 	"github.com/kudobuilder/kudo/pkg/kudoctl/util/kudo"
 
 	"github.com/pkg/errors"
+	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 )
 
@@ -19,10 +20,20 @@ var (
 		kubectl kudo update --instance dev-flink -p param=value
 
 		# Update dev-flink instance in namespace services with setting parameter param with value value
-		kubectl kudo update --instance dev-flink -n services -p param=value`
+		kubectl kudo update --instance dev-flink -n services -p param=value
+
+		# Update dev-flink instance with parameters from a file
+		kubectl kudo update --instance dev-flink --parameter-file values.yaml
+
+		# Update dev-flink instance setting parameter config to the content of a file
+		kubectl kudo update --instance dev-flink -p config=@flink-conf.yaml
+
+		# Update dev-flink instance and block until the triggered plan is complete
+		kubectl kudo update --instance dev-flink -p param=value --wait`
 )
 
 type updateOptions struct {
+	install.WaitOptions
 	InstanceName string
 	Parameters   map[string]string
 }
@@ -31,9 +42,10 @@ type updateOptions struct {
 var defaultUpdateOptions = &updateOptions{}
 
 // newUpdateCmd creates the install command for the CLI
-func newUpdateCmd() *cobra.Command {
+func newUpdateCmd(fs afero.Fs) *cobra.Command {
 	options := defaultUpdateOptions
 	var parameters []string
+	var parameterFiles []string
 	updateCmd := &cobra.Command{
 		Use:     "update",
 		Short:   "Update KUDO operator instance.",
@@ -42,7 +54,7 @@ func newUpdateCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Prior to command execution we parse and validate passed arguments
 			var err error
-			options.Parameters, err = install.GetParameterMap(parameters)
+			options.Parameters, err = install.GetParameterMap(fs, cmd.InOrStdin(), parameters, parameterFiles)
 			if err != nil {
 				return errors.WithMessage(err, "could not parse arguments")
 			}
@@ -51,7 +63,10 @@ func newUpdateCmd() *cobra.Command {
 	}
 
 	updateCmd.Flags().StringVar(&options.InstanceName, "instance", "", "The instance name.")
-	updateCmd.Flags().StringArrayVarP(&parameters, "parameter", "p", nil, "The parameter name and value separated by '='")
+	updateCmd.Flags().StringArrayVarP(&parameters, "parameter", "p", nil, "The parameter name and value separated by '='. Use '@' to read the value from a file, e.g. key=@path/to/file")
+	updateCmd.Flags().StringArrayVar(&parameterFiles, "parameter-file", nil, "A YAML file with parameter names and values, - reads the file from stdin. Later files override earlier ones, -p overrides all files")
+	updateCmd.Flags().BoolVar(&options.Wait, "wait", false, "Block until the plan triggered by the update is complete. Fails if the plan ends in ERROR.")
+	updateCmd.Flags().Int64Var(&options.WaitTimeout, "wait-timeout", install.DefaultWaitTimeout, "Wait timeout in seconds to be used with --wait")
 
 	return updateCmd
 }
@@ -64,7 +79,7 @@ func validateUpdateCmd(args []string, options *updateOptions) error {
 		return errors.New("--instance flag has to be provided to indicate which instance you want to update")
 	}
 	if len(options.Parameters) == 0 {
-		return errors.New("need to specify at least one parameter to override via -p otherwise there is nothing to update")
+		return errors.New("need to specify at least one parameter to override via -p or --parameter-file otherwise there is nothing to update")
 	}
 
 	return nil
@@ -95,11 +110,27 @@ func update(instanceToUpdate string, kc *kudo.Client, options *updateOptions, se
 		return fmt.Errorf("instance %s in namespace %s does not exist in the cluster", instanceToUpdate, settings.Namespace)
 	}
 
+	// Make sure the operator version of the instance defines all parameters
+	ov, err := kc.GetOperatorVersion(instance.Spec.OperatorVersion.Name, instance.GetOperatorVersionNamespace())
+	if err != nil {
+		return errors.Wrap(err, "retrieving existing operator version")
+	}
+	if ov == nil {
+		return fmt.Errorf("operatorversion %s of instance %s does not exist in the cluster", instance.Spec.OperatorVersion.Name, instanceToUpdate)
+	}
+	if err := install.ValidateParameters(options.Parameters, ov); err != nil {
+		return err
+	}
+
 	// Update arguments
 	err = kc.UpdateInstance(instanceToUpdate, settings.Namespace, nil, options.Parameters)
 	if err != nil {
 		return errors.Wrapf(err, "updating instance %s", instanceToUpdate)
 	}
-	fmt.Printf("Instance %s was updated.", instanceToUpdate)
+	fmt.Printf("Instance %s was updated.\n", instanceToUpdate)
+
+	if options.Wait {
+		return install.WaitForPlan(kc, instanceToUpdate, settings.Namespace, instance.Status.ActivePlan.Name, options.WaitOptions)
+	}
 	return nil
 }
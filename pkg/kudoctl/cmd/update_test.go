@@ -8,6 +8,7 @@ import (
 
 	"github.com/kudobuilder/kudo/pkg/apis/kudo/v1alpha1"
 	util "github.com/kudobuilder/kudo/pkg/util/kudo"
+	"github.com/spf13/afero"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -26,7 +27,7 @@ func TestUpdateCommand_Validation(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		cmd := newUpdateCmd()
+		cmd := newUpdateCmd(afero.NewMemMapFs())
 		cmd.SetArgs(tt.args)
 		for _, v := range tt.parameters {
 			cmd.Flags().Set("p", v)
@@ -61,6 +62,20 @@ func TestUpdate(t *testing.T) {
 		},
 	}
 
+	testOv := v1alpha1.OperatorVersion{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "kudo.dev/v1alpha1",
+			Kind:       "OperatorVersion",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-1.0",
+		},
+		Spec: v1alpha1.OperatorVersionSpec{
+			Version:    "1.0",
+			Parameters: []v1alpha1.Parameter{{Name: "param"}},
+		},
+	}
+
 	installNamespace := "default"
 	tests := []struct {
 		name               string
@@ -70,12 +85,14 @@ func TestUpdate(t *testing.T) {
 	}{
 		{"instance does not exist", false, map[string]string{"param": "value"}, "instance test in namespace default does not exist in the cluster"},
 		{"update arguments", true, map[string]string{"param": "value"}, ""},
+		{"update unknown argument", true, map[string]string{"param": "value", "other": "value"}, "parameters not defined by operatorversion test-1.0: other"},
 	}
 
 	for _, tt := range tests {
 		c := newTestClient()
 		if tt.instanceExists {
 			c.InstallInstanceObjToCluster(&testInstance, installNamespace)
+			c.InstallOperatorVersionObjToCluster(&testOv, installNamespace)
 		}
 
 		err := update(testInstance.Name, c, &updateOptions{Parameters: tt.parameters}, env.DefaultSettings)
@@ -7,7 +7,6 @@ import (
 	"github.com/kudobuilder/kudo/pkg/kudoctl/cmd/install"
 	"github.com/kudobuilder/kudo/pkg/kudoctl/env"
 	"github.com/kudobuilder/kudo/pkg/kudoctl/util/kudo"
-	"github.com/kudobuilder/kudo/pkg/kudoctl/util/repo"
 	util "github.com/kudobuilder/kudo/pkg/util/kudo"
 
 	"github.com/Masterminds/semver"
@@ -30,11 +29,15 @@ var (
 		kubectl kudo upgrade flink --instance dev-flink --version 1.1.1
 
 		# By default arguments are all reused from the previous installation, if you need to modify, use -p
-		kubectl kudo upgrade flink --instance dev-flink -p param=xxx`
+		kubectl kudo upgrade flink --instance dev-flink -p param=xxx
+
+		# Upgrade flink and block until the upgrade plan is complete
+		kubectl kudo upgrade flink --instance dev-flink --wait`
 )
 
 type options struct {
 	install.RepositoryOptions
+	install.WaitOptions
 	InstanceName   string
 	PackageVersion string
 	Parameters     map[string]string
@@ -47,6 +50,8 @@ var defaultOptions = &options{}
 func newUpgradeCmd(fs afero.Fs) *cobra.Command {
 	options := defaultOptions
 	var parameters []string
+	var parameterFiles []string
+	var registry registryFlags
 	upgradeCmd := &cobra.Command{
 		Use:     "upgrade <name>",
 		Short:   "Upgrade KUDO package.",
@@ -55,18 +60,30 @@ func newUpgradeCmd(fs afero.Fs) *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Prior to command execution we parse and validate passed arguments
 			var err error
-			options.Parameters, err = install.GetParameterMap(parameters)
+			options.Parameters, err = install.GetParameterMap(fs, cmd.InOrStdin(), parameters, parameterFiles)
 			if err != nil {
 				return errors.WithMessage(err, "could not parse arguments")
 			}
+			if registry.passwordStdin && containsStdin(parameterFiles) {
+				return errors.New("--password-stdin and --parameter-file - can not be used together")
+			}
+			options.Registry, err = registry.options(cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
 			return runUpgrade(args, options, fs, &Settings)
 		},
 	}
 
 	upgradeCmd.Flags().StringVar(&options.InstanceName, "instance", "", "The instance name.")
-	upgradeCmd.Flags().StringArrayVarP(&parameters, "parameter", "p", nil, "The parameter name and value separated by '='")
-	upgradeCmd.Flags().StringVar(&options.RepoName, "repo", "", "Name of repository configuration to use. (default defined by context)")
-	upgradeCmd.Flags().StringVarP(&options.PackageVersion, "version", "v", "", "A specific package version on the official repository. When installing from other sources than official repository, version from inside operator.yaml will be used. (default to the most recent)")
+	upgradeCmd.Flags().StringArrayVarP(&parameters, "parameter", "p", nil, "The parameter name and value separated by '='. Use '@' to read the value from a file, e.g. key=@path/to/file")
+	upgradeCmd.Flags().StringArrayVar(&parameterFiles, "parameter-file", nil, "A YAML file with parameter names and values, - reads the file from stdin. Later files override earlier ones, -p overrides all files")
+	upgradeCmd.Flags().StringVar(&options.RepoName, "repo", "", "Name of repository configuration to use. (default resolves the operator across all repositories by priority)")
+	upgradeCmd.Flags().BoolVar(&options.Offline, "offline", false, "Only use the locally cached repository index files and packages. Run 'kudo repo update' to refresh the cache.")
+	registry.addFlags(upgradeCmd.Flags())
+	upgradeCmd.Flags().StringVarP(&options.PackageVersion, "version", "v", "", "A specific package version or a semver constraint like '~2.3' on the official repository. When installing from other sources than official repository, version from inside operator.yaml will be used. (default to the most recent)")
+	upgradeCmd.Flags().BoolVar(&options.Wait, "wait", false, "Block until the plan triggered by the upgrade is complete. Fails if the plan ends in ERROR.")
+	upgradeCmd.Flags().Int64Var(&options.WaitTimeout, "wait-timeout", install.DefaultWaitTimeout, "Wait timeout in seconds to be used with --wait")
 
 	return upgradeCmd
 }
@@ -95,11 +112,11 @@ func runUpgrade(args []string, options *options, fs afero.Fs, settings *env.Sett
 	}
 
 	// Resolve the package to upgrade to
-	repository, err := repo.ClientFromSettings(fs, settings.Home, options.RepoName)
+	repository, err := install.NewRepository(fs, settings.Home, options.RepositoryOptions)
 	if err != nil {
 		return errors.WithMessage(err, "could not build operator repository")
 	}
-	crds, err := install.GetPackageCRDs(packageToUpgrade, options.PackageVersion, repository)
+	crds, err := install.GetPackageCRDs(packageToUpgrade, options.PackageVersion, repository, options.Registry)
 	if err != nil {
 		return errors.Wrapf(err, "failed to resolve package CRDs for operator: %s", packageToUpgrade)
 	}
@@ -139,6 +156,9 @@ func upgrade(newOv *v1alpha1.OperatorVersion, kc *kudo.Client, options *options,
 	if !oldVersion.LessThan(newVersion) {
 		return fmt.Errorf("upgraded version %s is the same or smaller as current version %s -> not upgrading", nextOperatorVersion, ov.Spec.Version)
 	}
+	if err := install.ValidateParameters(options.Parameters, newOv); err != nil {
+		return err
+	}
 
 	// install OV
 	versionsInstalled, err := kc.OperatorVersionsInstalled(operatorName, settings.Namespace)
@@ -158,5 +178,9 @@ func upgrade(newOv *v1alpha1.OperatorVersion, kc *kudo.Client, options *options,
 		return errors.Wrapf(err, "updating instance to point to new operatorversion %s", newOv.Name)
 	}
 	fmt.Printf("instance.%s/%s successfully updated\n", instance.APIVersion, instance.Name)
+
+	if options.Wait {
+		return install.WaitForPlan(kc, options.InstanceName, settings.Namespace, instance.Status.ActivePlan.Name, options.WaitOptions)
+	}
 	return nil
 }
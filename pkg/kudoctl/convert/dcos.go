@@ -0,0 +1,602 @@
+// Package convert creates KUDO operator packages from the service definitions of other frameworks.
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kudobuilder/kudo/pkg/apis/kudo/v1alpha1"
+	kudobundle "github.com/kudobuilder/kudo/pkg/bundle"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/bundle"
+	"github.com/kudobuilder/kudo/pkg/util/kudo"
+
+	validator "gopkg.in/go-playground/validator.v9"
+	yamlv2 "gopkg.in/yaml.v2"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/yaml"
+)
+
+// dcosPlan is a plan of a DC/OS service specification. Its phases are kept in the order they are defined in.
+type dcosPlan struct {
+	Strategy string          `yaml:"strategy"`
+	Phases   yamlv2.MapSlice `yaml:"phases"`
+}
+
+// dcosPhase deploys a pod of a DC/OS service specification
+type dcosPhase struct {
+	Strategy string        `yaml:"strategy"`
+	Pod      string        `yaml:"pod"`
+	Steps    []interface{} `yaml:"steps"`
+}
+
+// sandboxPath is the working directory of the containers. Paths of DC/OS volumes are relative to the sandbox of the
+// task, which is mounted here in DC/OS Docker containers.
+const sandboxPath = "/mnt/mesos/sandbox"
+
+// quotedTemplateRegexp matches quoted values which start with a template expression
+var quotedTemplateRegexp = regexp.MustCompile(`'(\{\{[^'\n]*)'`)
+
+// portNameRegexp matches valid names of container ports
+var portNameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// DCOSPackage converts a rendered DC/OS SDK service specification (svc.yml) into an operator package.
+// Each pod becomes a StatefulSet with a headless Service deployed by a task of the same name, the plans of the
+// specification deploy these tasks. Returns the package files and a report of the fields which could not be
+// converted.
+func DCOSPackage(svc []byte) (*bundle.PackageFiles, []string, error) {
+	spec, podNames, report, err := parseServiceSpec(svc)
+	if err != nil {
+		return nil, nil, err
+	}
+	// the name is the directory of the package, too
+	name := resourceName(*spec.Name)
+	if err := bundle.ValidateOperatorName(name); err != nil {
+		return nil, nil, err
+	}
+
+	c := &dcosConverter{
+		report:    report,
+		templates: map[string]string{},
+		tasks:     map[string]v1alpha1.TaskSpec{},
+	}
+	for _, name := range podNames {
+		if err := c.convertPod(name, spec.Pods[name]); err != nil {
+			return nil, nil, err
+		}
+	}
+	if spec.Scheduler != nil {
+		c.unsupported("scheduler", "scheduler settings are not needed on Kubernetes")
+	}
+
+	plans := map[string]v1alpha1.Plan{}
+	for name, plan := range spec.Plans {
+		for _, phase := range plan.Phases {
+			for _, step := range phase.Steps {
+				if _, ok := c.tasks[step.Name]; !ok {
+					return nil, nil, fmt.Errorf("phase %s of plan %s deploys pod %s which does not exist", phase.Name, name, step.Name)
+				}
+			}
+		}
+		plans[name] = *plan
+	}
+	if _, ok := plans["deploy"]; !ok {
+		plans["deploy"] = defaultPlan(podNames)
+	}
+
+	pf := &bundle.PackageFiles{
+		Operator: &kudobundle.Operator{
+			Name:    name,
+			Version: "0.1.0",
+			URL:     *spec.WebURL,
+			Tasks:   c.tasks,
+			Plans:   plans,
+		},
+		Templates: c.templates,
+		Params:    c.params,
+	}
+	return pf, c.report, nil
+}
+
+// parseServiceSpec parses and validates a service specification. Returns the names of the pods in the order of
+// their definition.
+func parseServiceSpec(svc []byte) (*v1alpha1.ServiceSpec, []string, []string, error) {
+	var doc yamlv2.MapSlice
+	if err := yamlv2.Unmarshal(svc, &doc); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to parse service specification, it has to be rendered without mustache templates: %v", err)
+	}
+
+	// the plans of the specification don't match the KUDO plans of the ServiceSpec, they are converted separately
+	var podNames []string
+	var rawPlans interface{}
+	rest := yamlv2.MapSlice{}
+	for _, item := range doc {
+		switch item.Key {
+		case "plans":
+			rawPlans = item.Value
+			continue
+		case "pods":
+			if pods, ok := item.Value.(yamlv2.MapSlice); ok {
+				for _, pod := range pods {
+					podNames = append(podNames, fmt.Sprint(pod.Key))
+				}
+			}
+		}
+		rest = append(rest, item)
+	}
+
+	content, err := yamlv2.Marshal(rest)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	spec := &v1alpha1.ServiceSpec{}
+	if err := yaml.Unmarshal(content, spec); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to parse service specification: %v", err)
+	}
+	var report []string
+	if rawPlans != nil {
+		if spec.Plans, report, err = convertPlans(rawPlans); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	if err := resolveResourceSets(spec); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := validator.New().Struct(spec); err != nil {
+		verrs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			return nil, nil, nil, err
+		}
+		lines := make([]string, 0, len(verrs))
+		for _, e := range verrs {
+			lines = append(lines, fmt.Sprintf("  %s: failed %s validation, is %v", e.Namespace(), e.Tag(), e.Value()))
+		}
+		return nil, nil, nil, fmt.Errorf("invalid service specification:\n%s", strings.Join(lines, "\n"))
+	}
+	return spec, podNames, report, nil
+}
+
+// resolveResourceSets sets the resources of tasks using a resource set to the ones of the set, so that they are
+// validated as if they were defined in the task
+func resolveResourceSets(spec *v1alpha1.ServiceSpec) error {
+	for podName, pod := range spec.Pods {
+		if pod == nil {
+			continue
+		}
+		for taskName, task := range pod.Tasks {
+			if task == nil || task.ResourceSet == nil {
+				continue
+			}
+			rs, ok := pod.ResourceSets[*task.ResourceSet]
+			if !ok || rs == nil {
+				return fmt.Errorf("task %s of pod %s uses resource set %s which does not exist", taskName, podName, *task.ResourceSet)
+			}
+			task.Cpus, task.Gpus, task.MemoryMB, task.Ports = rs.Cpus, rs.Gpus, float64(rs.MemoryMB), rs.Ports
+			task.Volume, task.Volumes = rs.Volume, rs.Volumes
+		}
+	}
+	return nil
+}
+
+// convertPlans converts the plans of a service specification into KUDO plans. Every phase deploys the task of its pod.
+// Returns a report of the fields which could not be converted.
+func convertPlans(raw interface{}) (map[string]*v1alpha1.Plan, []string, error) {
+	content, err := yamlv2.Marshal(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	dcosPlans := map[string]dcosPlan{}
+	if err := yamlv2.Unmarshal(content, &dcosPlans); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse plans: %v", err)
+	}
+
+	plans := map[string]*v1alpha1.Plan{}
+	var report []string
+	for _, name := range sortedKeys(dcosPlans) {
+		p := dcosPlans[name]
+		plan := &v1alpha1.Plan{Strategy: ordering(p.Strategy)}
+		for _, item := range p.Phases {
+			content, err := yamlv2.Marshal(item.Value)
+			if err != nil {
+				return nil, nil, err
+			}
+			phase := dcosPhase{}
+			if err := yamlv2.Unmarshal(content, &phase); err != nil {
+				return nil, nil, fmt.Errorf("failed to parse phase %v of plan %s: %v", item.Key, name, err)
+			}
+			if len(phase.Steps) > 0 {
+				report = append(report, fmt.Sprintf("plans.%s.phases.%v.steps: steps are not supported, all pods of the phase are deployed by the StatefulSet", name, item.Key))
+			}
+			pod := resourceName(phase.Pod)
+			plan.Phases = append(plan.Phases, v1alpha1.Phase{
+				Name:     fmt.Sprint(item.Key),
+				Strategy: ordering(phase.Strategy),
+				Steps:    []v1alpha1.Step{{Name: pod, Tasks: []string{pod}}},
+			})
+		}
+		plans[name] = plan
+	}
+	return plans, report, nil
+}
+
+// defaultPlan deploys the pods one after the other in the order of their definition, as the DC/OS SDK does
+func defaultPlan(podNames []string) v1alpha1.Plan {
+	plan := v1alpha1.Plan{Strategy: v1alpha1.Serial}
+	for _, name := range podNames {
+		pod := resourceName(name)
+		plan.Phases = append(plan.Phases, v1alpha1.Phase{
+			Name:     pod,
+			Strategy: v1alpha1.Serial,
+			Steps:    []v1alpha1.Step{{Name: pod, Tasks: []string{pod}}},
+		})
+	}
+	return plan
+}
+
+// ordering maps a DC/OS strategy to a KUDO ordering. Strategies like serial-canary are deployed serially.
+func ordering(strategy string) v1alpha1.Ordering {
+	if strategy == string(v1alpha1.Parallel) {
+		return v1alpha1.Parallel
+	}
+	return v1alpha1.Serial
+}
+
+type dcosConverter struct {
+	templates map[string]string
+	tasks     map[string]v1alpha1.TaskSpec
+	params    []v1alpha1.Parameter
+	report    []string
+}
+
+func (c *dcosConverter) unsupported(field string, reason string) {
+	c.report = append(c.report, fmt.Sprintf("%s: %s", field, reason))
+}
+
+// convertPod creates the StatefulSet, Service and task of a pod
+func (c *dcosConverter) convertPod(podName string, pod *v1alpha1.Pod) error {
+	name := resourceName(podName)
+	field := "pods." + podName
+	countParam := paramName(podName, "COUNT")
+	imageParam := paramName(podName, "IMAGE")
+
+	c.params = append(c.params, v1alpha1.Parameter{
+		Name:        countParam,
+		Description: fmt.Sprintf("Number of %s pods", podName),
+		Default:     kudo.String(strconv.Itoa(int(pod.Count))),
+		Required:    true,
+	})
+	image := v1alpha1.Parameter{Name: imageParam, Description: fmt.Sprintf("Container image of the %s pods", podName), Required: true}
+	if pod.Image != nil {
+		image.Default = pod.Image
+	} else {
+		c.unsupported(field, "pods without an image run in the Mesos containerizer, the image has to be set with parameter "+imageParam)
+	}
+	c.params = append(c.params, image)
+
+	if pod.Placement != nil {
+		c.unsupported(field+".placement", "placement constraints are not supported, use affinities in the StatefulSet instead")
+	}
+	if len(pod.Networks) > 0 {
+		c.unsupported(field+".networks", "virtual networks are not supported")
+	}
+	if len(pod.RLimits) > 0 {
+		c.unsupported(field+".rlimits", "rlimits are not supported")
+	}
+	if len(pod.Uris) > 0 {
+		c.unsupported(field+".uris", "artifacts have to be part of the image")
+	}
+	if pod.PreReservedRole != nil {
+		c.unsupported(field+".pre-reserved-role", "reservations are not supported")
+	}
+	if len(pod.Secrets) > 0 {
+		c.unsupported(field+".secrets", "secrets are not supported, use Kubernetes secrets instead")
+	}
+	if pod.AllowDecommission {
+		c.unsupported(field+".allow-decommission", "scaling down is done by changing parameter "+countParam)
+	}
+
+	labels := map[string]string{"app": name, "instance": "{{ .Name }}"}
+	podSpec := corev1.PodSpec{}
+	if pod.SharePidNamespace {
+		share := true
+		podSpec.ShareProcessNamespace = &share
+	}
+
+	// volumes and host volumes of the pod are available to all its tasks
+	var podMounts []corev1.VolumeMount
+	var claims []corev1.PersistentVolumeClaim
+	addVolume := func(key string, v *v1alpha1.Volume) corev1.VolumeMount {
+		volumeName := resourceName(key)
+		for _, claim := range claims {
+			if claim.Name == volumeName {
+				return corev1.VolumeMount{Name: volumeName, MountPath: sandboxMountPath(*v.Path)}
+			}
+		}
+		claims = append(claims, persistentVolumeClaim(volumeName, v))
+		return corev1.VolumeMount{Name: volumeName, MountPath: sandboxMountPath(*v.Path)}
+	}
+	if pod.Volume != nil {
+		podMounts = append(podMounts, addVolume(podName+"-volume", pod.Volume))
+	}
+	for _, key := range sortedKeys(pod.Volumes) {
+		podMounts = append(podMounts, addVolume(key, pod.Volumes[key]))
+	}
+	for _, key := range sortedKeys(pod.HostVolumes) {
+		hv := pod.HostVolumes[key]
+		volumeName := resourceName(key)
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name:         volumeName,
+			VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: *hv.HostPath}},
+		})
+		podMounts = append(podMounts, corev1.VolumeMount{Name: volumeName, MountPath: sandboxMountPath(*hv.ContainerPath)})
+	}
+
+	var servicePorts []corev1.ServicePort
+	for _, taskName := range sortedKeys(pod.Tasks) {
+		task := pod.Tasks[taskName]
+		taskField := fmt.Sprintf("%s.tasks.%s", field, taskName)
+		container := corev1.Container{
+			Name:         resourceName(taskName),
+			Image:        fmt.Sprintf("{{ .Params.%s }}", imageParam),
+			Command:      []string{"/bin/sh", "-c", *task.Cmd},
+			WorkingDir:   sandboxPath,
+			VolumeMounts: append([]corev1.VolumeMount{}, podMounts...),
+		}
+		container.Env = append(container.Env, corev1.EnvVar{Name: "MESOS_SANDBOX", Value: sandboxPath})
+		for _, key := range sortedKeys(task.Env) {
+			container.Env = append(container.Env, corev1.EnvVar{Name: key, Value: *task.Env[key]})
+		}
+
+		volumes := map[string]*v1alpha1.Volume{}
+		if task.Volume != nil {
+			volume := taskName + "-volume"
+			if task.ResourceSet != nil {
+				volume = *task.ResourceSet + "-volume"
+			}
+			volumes[volume] = task.Volume
+		}
+		for key, v := range task.Volumes {
+			volumes[key] = v
+		}
+		container.Resources = resources(task.Cpus, task.Gpus, task.MemoryMB)
+		for _, key := range sortedKeys(volumes) {
+			container.VolumeMounts = append(container.VolumeMounts, addVolume(key, volumes[key]))
+		}
+
+		for _, key := range sortedKeys(task.Ports) {
+			p := task.Ports[key]
+			port := corev1.ContainerPort{ContainerPort: p.Port}
+			if len(key) <= 15 && portNameRegexp.MatchString(key) {
+				port.Name = key
+			}
+			container.Ports = append(container.Ports, port)
+			servicePorts = append(servicePorts, corev1.ServicePort{Name: resourceName(key), Port: p.Port, TargetPort: intstr.FromInt(int(p.Port))})
+			if p.EnvKey != nil {
+				container.Env = append(container.Env, corev1.EnvVar{Name: *p.EnvKey, Value: strconv.Itoa(int(p.Port))})
+			}
+			if p.VIP != nil || p.Advertise {
+				c.unsupported(fmt.Sprintf("%s.ports.%s", taskField, key), "VIPs and advertised ports are not supported, the port is exposed by the Service of the pod")
+			}
+		}
+
+		if hc := task.HealthCheck; hc != nil {
+			container.LivenessProbe = &corev1.Probe{
+				Handler:             execHandler(hc.Cmd),
+				InitialDelaySeconds: hc.GracePeriodSecs,
+				PeriodSeconds:       hc.DelaySecs,
+				TimeoutSeconds:      hc.TimeoutSecs,
+				FailureThreshold:    hc.MaxConsecutiveFailures,
+			}
+		}
+		if rc := task.ReadinessCheck; rc != nil {
+			container.ReadinessProbe = &corev1.Probe{
+				Handler:             execHandler(rc.Cmd),
+				InitialDelaySeconds: rc.DelaySecs,
+				PeriodSeconds:       rc.IntervalSecs,
+				TimeoutSeconds:      rc.TimeoutSecs,
+			}
+		}
+		if task.TaskKillGracePeriodSecs > 0 {
+			grace := int64(task.TaskKillGracePeriodSecs)
+			if podSpec.TerminationGracePeriodSeconds == nil || *podSpec.TerminationGracePeriodSeconds < grace {
+				podSpec.TerminationGracePeriodSeconds = &grace
+			}
+		}
+		if len(task.Configs) > 0 {
+			c.unsupported(taskField+".configs", "config templates are not supported, use a ConfigMap template instead")
+		}
+		if task.Discovery != nil {
+			c.unsupported(taskField+".discovery", "discovery is done by the Service of the pod")
+		}
+		if len(task.TransportEncryption) > 0 {
+			c.unsupported(taskField+".transport-encryption", "transport encryption is not supported")
+		}
+
+		if *task.Goal == "RUNNING" {
+			podSpec.Containers = append(podSpec.Containers, container)
+		} else {
+			podSpec.InitContainers = append(podSpec.InitContainers, container)
+			c.unsupported(taskField+".goal", fmt.Sprintf("tasks with goal %s are converted to init containers, they run on every restart of the pod", *task.Goal))
+		}
+	}
+
+	statefulSet := &appsv1.StatefulSet{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "StatefulSet"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "{{ .Namespace }}"},
+		Spec: appsv1.StatefulSetSpec{
+			Selector:            &metav1.LabelSelector{MatchLabels: labels},
+			ServiceName:         fmt.Sprintf("{{ .Name }}-%s", name),
+			PodManagementPolicy: appsv1.OrderedReadyPodManagement,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       podSpec,
+			},
+			VolumeClaimTemplates: claims,
+		},
+	}
+	service := &corev1.Service{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "{{ .Namespace }}", Labels: labels},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Selector:  labels,
+			Ports:     servicePorts,
+		},
+	}
+
+	statefulSetTemplate, err := toTemplate(statefulSet, map[string]string{"replicas": fmt.Sprintf("{{ .Params.%s }}", countParam)})
+	if err != nil {
+		return err
+	}
+	serviceTemplate, err := toTemplate(service, nil)
+	if err != nil {
+		return err
+	}
+	c.templates[name+"-statefulset.yaml"] = statefulSetTemplate
+	c.templates[name+"-service.yaml"] = serviceTemplate
+	c.tasks[name] = v1alpha1.TaskSpec{Resources: []string{name + "-service.yaml", name + "-statefulset.yaml"}}
+	return nil
+}
+
+func resources(cpus, gpus, memoryMB float64) corev1.ResourceRequirements {
+	r := corev1.ResourceRequirements{Requests: corev1.ResourceList{}, Limits: corev1.ResourceList{}}
+	if cpus > 0 {
+		r.Requests[corev1.ResourceCPU] = *resource.NewMilliQuantity(int64(cpus*1000), resource.DecimalSI)
+	}
+	if memoryMB > 0 {
+		memory := *resource.NewQuantity(int64(memoryMB)*1024*1024, resource.BinarySI)
+		r.Requests[corev1.ResourceMemory] = memory
+		r.Limits[corev1.ResourceMemory] = memory
+	}
+	if gpus > 0 {
+		r.Limits["nvidia.com/gpu"] = *resource.NewQuantity(int64(gpus), resource.DecimalSI)
+	}
+	return r
+}
+
+// sandboxMountPath returns the absolute path of a volume path relative to the sandbox
+func sandboxMountPath(p string) string {
+	if path.IsAbs(p) {
+		return p
+	}
+	return path.Join(sandboxPath, p)
+}
+
+func persistentVolumeClaim(name string, v *v1alpha1.Volume) corev1.PersistentVolumeClaim {
+	return corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: *resource.NewQuantity(int64(v.SizeMB)*1024*1024, resource.BinarySI),
+				},
+			},
+		},
+	}
+}
+
+func execHandler(cmd *string) corev1.Handler {
+	if cmd == nil {
+		return corev1.Handler{}
+	}
+	return corev1.Handler{Exec: &corev1.ExecAction{Command: []string{"/bin/sh", "-c", *cmd}}}
+}
+
+// toTemplate renders an object as a template. Fields of the spec in specTemplates are replaced by template
+// expressions, e.g. to take the value from a parameter.
+func toTemplate(obj interface{}, specTemplates map[string]string) (string, error) {
+	content, err := json.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(content, &m); err != nil {
+		return "", err
+	}
+	delete(m, "status")
+	removeEmpty(m)
+	if spec, ok := m["spec"].(map[string]interface{}); ok {
+		for field, t := range specTemplates {
+			spec[field] = t
+		}
+	}
+
+	content, err = yaml.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+	// values starting with a template expression are quoted by the marshaller as they start with a brace
+	return quotedTemplateRegexp.ReplaceAllString(string(content), "$1"), nil
+}
+
+// removeEmpty removes null values and empty objects, returns true if v itself is empty
+func removeEmpty(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		for k, value := range t {
+			if removeEmpty(value) {
+				delete(t, k)
+			}
+		}
+		return len(t) == 0
+	case []interface{}:
+		for _, value := range t {
+			removeEmpty(value)
+		}
+	}
+	return false
+}
+
+// resourceName converts a DC/OS name into a valid Kubernetes name
+func resourceName(name string) string {
+	return strings.ToLower(strings.Replace(name, "_", "-", -1))
+}
+
+// paramName returns the name of a parameter for a property of a pod, e.g. NODE_COUNT
+func paramName(pod string, property string) string {
+	return strings.ToUpper(strings.Replace(pod, "-", "_", -1)) + "_" + property
+}
+
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch t := m.(type) {
+	case map[string]*v1alpha1.Volume:
+		for k := range t {
+			keys = append(keys, k)
+		}
+	case map[string]*v1alpha1.HostVolume:
+		for k := range t {
+			keys = append(keys, k)
+		}
+	case map[string]*v1alpha1.Task:
+		for k := range t {
+			keys = append(keys, k)
+		}
+	case map[string]*v1alpha1.Port:
+		for k := range t {
+			keys = append(keys, k)
+		}
+	case map[string]dcosPlan:
+		for k := range t {
+			keys = append(keys, k)
+		}
+	case map[string]*string:
+		for k := range t {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
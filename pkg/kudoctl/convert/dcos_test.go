@@ -0,0 +1,121 @@
+package convert
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/kudobuilder/kudo/pkg/apis/kudo/v1alpha1"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/bundle"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDCOSPackage(t *testing.T) {
+	svc, err := ioutil.ReadFile("testdata/svc.yml")
+	assert.NoError(t, err)
+
+	pf, report, err := DCOSPackage(svc)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the converted package can be written and loaded again
+	fs := afero.NewMemMapFs()
+	assert.NoError(t, bundle.WritePackage(fs, "/opt/hello-world", pf))
+	b, err := bundle.NewBundle(fs, "/opt/hello-world")
+	assert.NoError(t, err)
+	crds, err := b.GetCRDs()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "hello-world", crds.Operator.Name)
+	assert.Equal(t, "http://localhost:8080/v1/health", crds.Operator.Spec.URL)
+
+	spec := crds.OperatorVersion.Spec
+	assert.Equal(t, []string{"hello-service.yaml", "hello-statefulset.yaml"}, spec.Tasks["hello"].Resources)
+	assert.Equal(t, []string{"world-service.yaml", "world-statefulset.yaml"}, spec.Tasks["world"].Resources)
+
+	deploy := spec.Plans["deploy"]
+	assert.Equal(t, v1alpha1.Serial, deploy.Strategy)
+	if assert.Len(t, deploy.Phases, 2) {
+		assert.Equal(t, "world-deploy", deploy.Phases[0].Name)
+		assert.Equal(t, v1alpha1.Parallel, deploy.Phases[0].Strategy)
+		assert.Equal(t, []string{"world"}, deploy.Phases[0].Steps[0].Tasks)
+		assert.Equal(t, "hello-deploy", deploy.Phases[1].Name)
+	}
+
+	params := map[string]string{}
+	for _, p := range spec.Parameters {
+		params[p.Name] = *p.Default
+	}
+	assert.Equal(t, map[string]string{
+		"HELLO_COUNT": "2",
+		"HELLO_IMAGE": "busybox:1.31",
+		"WORLD_COUNT": "1",
+		"WORLD_IMAGE": "busybox:1.31",
+	}, params)
+
+	hello := spec.Templates["hello-statefulset.yaml"]
+	for _, expected := range []string{
+		"replicas: {{ .Params.HELLO_COUNT }}",
+		"image: {{ .Params.HELLO_IMAGE }}",
+		"serviceName: {{ .Name }}-hello\n",
+		"- echo hello >> hello-container-path/output && sleep 1000",
+		"name: PORT_HTTP\n          value: \"8080\"",
+		"cpu: \"1\"",
+		"memory: 256Mi",
+		"livenessProbe:",
+		"failureThreshold: 3",
+		"readinessProbe:",
+		"storage: 50Mi",
+		"mountPath: /mnt/mesos/sandbox/hello-container-path",
+		"workingDir: /mnt/mesos/sandbox",
+	} {
+		assert.Contains(t, hello, expected)
+	}
+	assert.NotContains(t, hello, "status")
+	assert.NotContains(t, hello, "creationTimestamp")
+
+	world := spec.Templates["world-statefulset.yaml"]
+	for _, expected := range []string{
+		"initContainers:",
+		"cpu: \"2\"",
+		"storage: 100Mi",
+		"terminationGracePeriodSeconds: 30",
+	} {
+		assert.Contains(t, world, expected)
+	}
+
+	assert.Contains(t, spec.Templates["hello-service.yaml"], "clusterIP: None")
+	assert.Contains(t, spec.Templates["hello-service.yaml"], "targetPort: 8080")
+
+	assert.Equal(t, []string{
+		"plans.deploy.phases.hello-deploy.steps: steps are not supported, all pods of the phase are deployed by the StatefulSet",
+		"pods.hello.placement: placement constraints are not supported, use affinities in the StatefulSet instead",
+		"pods.hello.uris: artifacts have to be part of the image",
+		"pods.world.uris: artifacts have to be part of the image",
+		"pods.world.tasks.init.goal: tasks with goal ONCE are converted to init containers, they run on every restart of the pod",
+		"scheduler: scheduler settings are not needed on Kubernetes",
+	}, report)
+}
+
+func TestDCOSPackageInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		svc  string
+		err  string
+	}{
+		{"not yaml", "name: [", "failed to parse service specification, it has to be rendered without mustache templates: yaml: line 1: did not find expected node content"},
+		{"validation", "name: test\nweb-url: http://example.com\npods:\n  app:\n    count: 0\n    uris: [a]\n",
+			"invalid service specification:\n  ServiceSpec.Pods[app].Count: failed gte validation, is 0"},
+		{"unknown pod", "name: test\nweb-url: http://example.com\npods:\n  app:\n    count: 1\n    uris: [a]\nplans:\n  deploy:\n    strategy: serial\n    phases:\n      main:\n        pod: other\n",
+			"phase main of plan deploy deploys pod other which does not exist"},
+		{"path in name", "name: ../test\nweb-url: http://example.com\npods:\n  app:\n    count: 1\n    uris: [a]\n",
+			`invalid operator name "../test": has to consist of lower case alphanumeric characters or '-'`},
+	}
+	for _, tt := range tests {
+		_, _, err := DCOSPackage([]byte(tt.svc))
+		assert.EqualError(t, err, tt.err, tt.name)
+	}
+}
@@ -2,8 +2,11 @@ package http
 
 import (
 	"bytes"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strings"
@@ -14,18 +17,49 @@ import (
 // Client is client used to communicate with KUDO repositories
 // it enriches HTTP client with expected headers etc.
 type Client struct {
-	client *http.Client
+	client  *http.Client
+	options Options
+}
+
+// Options configure authentication and TLS of a Client for repositories that are not publicly accessible
+type Options struct {
+	// Username and Password are used for basic auth
+	Username string
+	Password string
+	// BearerToken is sent in the Authorization header if no username is set
+	BearerToken string
+	// CAFile is a PEM encoded CA bundle used to verify the server certificate in addition to the system roots
+	CAFile string
+	// CertFile and KeyFile are a PEM encoded client certificate and key
+	CertFile string
+	KeyFile  string
+	// InsecureSkipTLSVerify disables verification of the server certificate
+	InsecureSkipTLSVerify bool
+}
+
+// newRequest creates a GET request with the KUDO user agent and the credentials of the client
+func (c *Client) newRequest(href string) (*http.Request, error) {
+	req, err := http.NewRequest("GET", href, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", fmt.Sprintf("KUDO/%s", strings.TrimPrefix(version.Get().GitVersion, "v")))
+	if c.options.Username != "" {
+		req.SetBasicAuth(c.options.Username, c.options.Password)
+	} else if c.options.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.options.BearerToken)
+	}
+	return req, nil
 }
 
 // Get performs HTTP get on KUDO repository
 func (c *Client) Get(href string) (*bytes.Buffer, error) {
 	buf := bytes.NewBuffer(nil)
 
-	req, err := http.NewRequest("GET", href, nil)
+	req, err := c.newRequest(href)
 	if err != nil {
 		return buf, err
 	}
-	req.Header.Set("User-Agent", fmt.Sprintf("KUDO/%s", strings.TrimPrefix(version.Get().GitVersion, "v")))
 
 	resp, err := c.client.Do(req)
 	if err != nil {
@@ -46,6 +80,49 @@ func (c *Client) Get(href string) (*bytes.Buffer, error) {
 	return buf, err
 }
 
+// Validators are the cache validators of a previously fetched response
+type Validators struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+// GetIfChanged performs a conditional HTTP get on KUDO repository using the validators of a previous response.
+// If the server reports the resource as not modified, the returned buffer is nil and the validators are unchanged.
+func (c *Client) GetIfChanged(href string, validators Validators) (*bytes.Buffer, Validators, error) {
+	req, err := c.newRequest(href)
+	if err != nil {
+		return nil, validators, err
+	}
+	if validators.ETag != "" {
+		req.Header.Set("If-None-Match", validators.ETag)
+	}
+	if validators.LastModified != "" {
+		req.Header.Set("If-Modified-Since", validators.LastModified)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, validators, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, validators, nil
+	}
+	if resp.StatusCode != 200 {
+		return nil, validators, fmt.Errorf("failed to fetch %s : %s", href, resp.Status)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if _, err := io.Copy(buf, resp.Body); err != nil {
+		return nil, validators, err
+	}
+	return buf, Validators{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}, nil
+}
+
 // NewClient creates HTTP client
 func NewClient() *Client {
 	var client Client
@@ -58,6 +135,53 @@ func NewClient() *Client {
 	return &client
 }
 
+// NewClientWithOptions creates HTTP client which authenticates with the given credentials and uses the given TLS settings
+func NewClientWithOptions(options Options) (*Client, error) {
+	tlsConfig, err := NewTLSConfig(options)
+	if err != nil {
+		return nil, err
+	}
+
+	client := NewClient()
+	client.client.Transport.(*http.Transport).TLSClientConfig = tlsConfig
+	client.options = options
+	return client, nil
+}
+
+// NewTLSConfig creates the TLS configuration for the CA bundle, client certificate and verification settings of the options
+func NewTLSConfig(options Options) (*tls.Config, error) {
+	config := &tls.Config{
+		InsecureSkipVerify: options.InsecureSkipTLSVerify,
+	}
+
+	if options.CAFile != "" {
+		ca, err := ioutil.ReadFile(options.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA file: %v", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no PEM encoded certificates found in CA file %s", options.CAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if options.CertFile != "" || options.KeyFile != "" {
+		if options.CertFile == "" || options.KeyFile == "" {
+			return nil, fmt.Errorf("client certificate and key have to be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(options.CertFile, options.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %v", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
 // IsValidURL returns true if the url is a Parsable URL
 func IsValidURL(uri string) bool {
 	_, err := url.ParseRequestURI(uri)
@@ -1,6 +1,11 @@
 package http
 
 import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"testing"
 )
 
@@ -24,3 +29,103 @@ func TestIsValidURL(t *testing.T) {
 		})
 	}
 }
+
+func TestGetIfChanged(t *testing.T) {
+	lastModified := "Wed, 21 Oct 2015 07:28:00 GMT"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` || r.Header.Get("If-Modified-Since") == lastModified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", lastModified)
+		w.Write([]byte("index"))
+	}))
+	defer server.Close()
+
+	client := NewClient()
+
+	body, validators, err := client.GetIfChanged(server.URL, Validators{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body == nil || body.String() != "index" {
+		t.Errorf("expected body of first request to be returned but got %v", body)
+	}
+	if validators.ETag != `"v1"` || validators.LastModified != lastModified {
+		t.Errorf("expected validators of the response but got %+v", validators)
+	}
+
+	for _, v := range []Validators{{ETag: `"v1"`}, {LastModified: lastModified}} {
+		body, _, err = client.GetIfChanged(server.URL, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if body != nil {
+			t.Errorf("expected no body for unchanged resource with validators %+v but got %s", v, body.String())
+		}
+	}
+}
+
+func TestClientWithOptions_Auth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		options  Options
+		expected string
+	}{
+		{"no credentials", Options{}, ""},
+		{"basic auth", Options{Username: "user", Password: "secret"}, "Basic dXNlcjpzZWNyZXQ="},
+		{"bearer token", Options{BearerToken: "token"}, "Bearer token"},
+	}
+
+	for _, tt := range tests {
+		client, err := NewClientWithOptions(tt.options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if body.String() != tt.expected {
+			t.Errorf("%s: expected authorization header %q but got %q", tt.name, tt.expected, body.String())
+		}
+	}
+}
+
+func TestClientWithOptions_CAFile(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("index"))
+	}))
+	defer server.Close()
+
+	caFile, err := ioutil.TempFile("", "ca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(caFile.Name())
+	pem.Encode(caFile, &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	caFile.Close()
+
+	client := NewClient()
+	if _, err := client.Get(server.URL); err == nil {
+		t.Errorf("expected the certificate of the server to be untrusted without CA file")
+	}
+
+	client, err = NewClientWithOptions(Options{CAFile: caFile.Name()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Get(server.URL); err != nil {
+		t.Errorf("expected the certificate of the server to be trusted with CA file but got %v", err)
+	}
+
+	if _, err := NewClientWithOptions(Options{CertFile: caFile.Name()}); err == nil {
+		t.Errorf("expected an error for a client certificate without key")
+	}
+}
@@ -29,3 +29,8 @@ func (h Home) Repository() string {
 func (h Home) RepositoryFile() string {
 	return h.path("repository", "repositories.yaml")
 }
+
+// Cache returns the path to the local cache of repository index files and packages.
+func (h Home) Cache() string {
+	return h.path("cache")
+}
@@ -11,4 +11,5 @@ func TestKudoHome(t *testing.T) {
 
 	assert.Equal(t, h.String(), "/a")
 	assert.Equal(t, h.RepositoryFile(), "/a/repository/repositories.yaml")
+	assert.Equal(t, h.Cache(), "/a/cache")
 }
@@ -0,0 +1,354 @@
+package oci
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	kudohttp "github.com/kudobuilder/kudo/pkg/kudoctl/http"
+	"github.com/kudobuilder/kudo/pkg/version"
+)
+
+// Operator packages are stored as OCI artifacts: the manifest references a small JSON config with the operator
+// metadata and a single layer which is the package tarball created by `kudo package`.
+const (
+	// ManifestMediaType is the media type of the manifest of an operator package
+	ManifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+	// ConfigMediaType is the media type of the config of an operator package
+	ConfigMediaType = "application/vnd.kudo.operator.config.v1+json"
+	// PackageMediaType is the media type of the layer containing the package tarball
+	PackageMediaType = "application/vnd.kudo.operator.package.v1.tar+gzip"
+)
+
+// Descriptor references content in a registry
+type Descriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+// Manifest is an OCI image manifest
+type Manifest struct {
+	SchemaVersion int          `json:"schemaVersion"`
+	MediaType     string       `json:"mediaType,omitempty"`
+	Config        Descriptor   `json:"config"`
+	Layers        []Descriptor `json:"layers"`
+}
+
+// Config is the metadata of an operator package stored in its manifest config
+type Config struct {
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	AppVersion string `json:"appVersion,omitempty"`
+}
+
+// Options configure a Client
+type Options struct {
+	// Username and Password are used for basic auth and to request tokens from the registry
+	Username string
+	Password string
+	// PlainHTTP uses http instead of https. Registries on localhost always use http.
+	PlainHTTP bool
+	// CAFile is a PEM encoded CA bundle used to verify the certificate of the registry in addition to the system roots
+	CAFile string
+	// InsecureSkipTLSVerify disables verification of the certificate of the registry
+	InsecureSkipTLSVerify bool
+}
+
+// Client pushes and pulls operator packages to and from OCI registries using the distribution API
+type Client struct {
+	client  *http.Client
+	options Options
+	// auth contains the Authorization header per registry and repository, obtained from earlier challenges
+	auth map[string]string
+}
+
+// NewClient creates a client for OCI registries
+func NewClient(options Options) (*Client, error) {
+	tlsConfig, err := kudohttp.NewTLSConfig(kudohttp.Options{CAFile: options.CAFile, InsecureSkipTLSVerify: options.InsecureSkipTLSVerify})
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		client: &http.Client{
+			Transport: &http.Transport{Proxy: http.ProxyFromEnvironment, TLSClientConfig: tlsConfig},
+		},
+		options: options,
+		auth:    map[string]string{},
+	}, nil
+}
+
+// Pull downloads the package tarball the reference points to. The reference needs a tag.
+func (c *Client) Pull(ref Reference) ([]byte, error) {
+	if ref.Tag == "" {
+		return nil, fmt.Errorf("no tag given for %s", ref)
+	}
+
+	resp, err := c.do(ref, "GET", c.url(ref, "manifests", ref.Tag), nil, http.Header{"Accept": {ManifestMediaType}})
+	if err != nil {
+		return nil, err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(resp, &manifest); err != nil {
+		return nil, fmt.Errorf("parsing manifest of %s: %v", ref, err)
+	}
+
+	for _, layer := range manifest.Layers {
+		if layer.MediaType != PackageMediaType {
+			continue
+		}
+		content, err := c.do(ref, "GET", c.url(ref, "blobs", layer.Digest), nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		if actual := digest(content); actual != layer.Digest {
+			return nil, fmt.Errorf("package %s has digest %s but the manifest expects %s", ref, actual, layer.Digest)
+		}
+		return content, nil
+	}
+	return nil, fmt.Errorf("%s is not a KUDO operator package: no layer with media type %s", ref, PackageMediaType)
+}
+
+// Push uploads the package tarball with its config and tags it with the tag of the reference.
+// Returns the digest of the manifest.
+func (c *Client) Push(ref Reference, pkg []byte, config Config) (string, error) {
+	if ref.Tag == "" {
+		return "", fmt.Errorf("no tag given for %s", ref)
+	}
+
+	configContent, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+	manifest := Manifest{
+		SchemaVersion: 2,
+		MediaType:     ManifestMediaType,
+		Config:        Descriptor{MediaType: ConfigMediaType, Digest: digest(configContent), Size: int64(len(configContent))},
+		Layers:        []Descriptor{{MediaType: PackageMediaType, Digest: digest(pkg), Size: int64(len(pkg))}},
+	}
+
+	if err := c.pushBlob(ref, configContent); err != nil {
+		return "", err
+	}
+	if err := c.pushBlob(ref, pkg); err != nil {
+		return "", err
+	}
+
+	manifestContent, err := json.Marshal(manifest)
+	if err != nil {
+		return "", err
+	}
+	if _, err := c.do(ref, "PUT", c.url(ref, "manifests", ref.Tag), manifestContent, http.Header{"Content-Type": {ManifestMediaType}}); err != nil {
+		return "", err
+	}
+	return digest(manifestContent), nil
+}
+
+// pushBlob uploads content in a single request unless the registry already has it
+func (c *Client) pushBlob(ref Reference, content []byte) error {
+	d := digest(content)
+	if _, err := c.do(ref, "HEAD", c.url(ref, "blobs", d), nil, nil); err == nil {
+		return nil
+	}
+
+	location, err := c.startUpload(ref)
+	if err != nil {
+		return err
+	}
+	query := location.Query()
+	query.Set("digest", d)
+	location.RawQuery = query.Encode()
+
+	_, err = c.do(ref, "PUT", location.String(), content, http.Header{"Content-Type": {"application/octet-stream"}})
+	return err
+}
+
+// startUpload starts a blob upload and returns the location to upload the blob to
+func (c *Client) startUpload(ref Reference) (*url.URL, error) {
+	req, err := c.newRequest(ref, "POST", c.url(ref, "blobs", "uploads")+"/", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.send(ref, req, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	location, err := resp.Request.URL.Parse(resp.Header.Get("Location"))
+	if err != nil || resp.Header.Get("Location") == "" {
+		return nil, fmt.Errorf("registry %s returned no valid upload location for %s", ref.Registry, ref)
+	}
+	return location, nil
+}
+
+// do sends a request and returns the response body
+func (c *Client) do(ref Reference, method string, href string, body []byte, header http.Header) ([]byte, error) {
+	req, err := c.newRequest(ref, method, href, body, header)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.send(ref, req, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return ioutil.ReadAll(resp.Body)
+}
+
+// send sends a request. If the registry asks for authentication, the request is authenticated and sent once more.
+func (c *Client) send(ref Reference, req *http.Request, body []byte) (*http.Response, error) {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		if err := c.authenticate(ref, challenge); err != nil {
+			return nil, err
+		}
+		req, err = c.newRequest(ref, req.Method, req.URL.String(), body, req.Header)
+		if err != nil {
+			return nil, err
+		}
+		resp, err = c.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to %s %s : %s", strings.ToLower(req.Method), req.URL, resp.Status)
+	}
+	return resp, nil
+}
+
+func (c *Client) newRequest(ref Reference, method string, href string, body []byte, header http.Header) (*http.Request, error) {
+	req, err := http.NewRequest(method, href, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("User-Agent", fmt.Sprintf("KUDO/%s", strings.TrimPrefix(version.Get().GitVersion, "v")))
+	if auth, ok := c.auth[authKey(ref)]; ok {
+		req.Header.Set("Authorization", auth)
+	}
+	return req, nil
+}
+
+var challengeParamRegexp = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// authenticate answers a WWW-Authenticate challenge of the registry with basic auth or a bearer token
+func (c *Client) authenticate(ref Reference, challenge string) error {
+	switch {
+	case strings.HasPrefix(strings.ToLower(challenge), "basic"):
+		if c.options.Username == "" {
+			return fmt.Errorf("registry %s requires authentication", ref.Registry)
+		}
+		credentials := base64.StdEncoding.EncodeToString([]byte(c.options.Username + ":" + c.options.Password))
+		c.auth[authKey(ref)] = "Basic " + credentials
+		return nil
+
+	case strings.HasPrefix(strings.ToLower(challenge), "bearer"):
+		params := map[string]string{}
+		for _, m := range challengeParamRegexp.FindAllStringSubmatch(challenge, -1) {
+			params[m[1]] = m[2]
+		}
+		token, err := c.fetchToken(params["realm"], params["service"], params["scope"])
+		if err != nil {
+			return fmt.Errorf("authenticating with registry %s: %v", ref.Registry, err)
+		}
+		c.auth[authKey(ref)] = "Bearer " + token
+		return nil
+	}
+	return fmt.Errorf("registry %s requires unsupported authentication %q", ref.Registry, challenge)
+}
+
+// fetchToken requests a bearer token from the token service of a registry
+func (c *Client) fetchToken(realm, service, scope string) (string, error) {
+	tokenURL, err := url.Parse(realm)
+	if err != nil || realm == "" {
+		return "", fmt.Errorf("invalid token realm %q", realm)
+	}
+	query := tokenURL.Query()
+	if service != "" {
+		query.Set("service", service)
+	}
+	if scope != "" {
+		query.Set("scope", scope)
+	}
+	tokenURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequest("GET", tokenURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if c.options.Username != "" {
+		req.SetBasicAuth(c.options.Username, c.options.Password)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get token from %s : %s", realm, resp.Status)
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", err
+	}
+	if token.Token != "" {
+		return token.Token, nil
+	}
+	if token.AccessToken != "" {
+		return token.AccessToken, nil
+	}
+	return "", fmt.Errorf("no token returned by %s", realm)
+}
+
+// url returns the URL of an endpoint of the distribution API for the repository of the reference
+func (c *Client) url(ref Reference, endpoint string, name string) string {
+	scheme := "https"
+	if c.options.PlainHTTP || isLocalhost(ref.Registry) {
+		scheme = "http"
+	}
+	return fmt.Sprintf("%s://%s/v2/%s/%s/%s", scheme, ref.Registry, ref.Repository, endpoint, name)
+}
+
+func isLocalhost(registry string) bool {
+	host, _, err := net.SplitHostPort(registry)
+	if err != nil {
+		host = registry
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func authKey(ref Reference) string {
+	return ref.Registry + "/" + ref.Repository
+}
+
+func digest(content []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(content))
+}
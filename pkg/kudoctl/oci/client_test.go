@@ -0,0 +1,65 @@
+package oci
+
+import (
+	"testing"
+
+	"github.com/kudobuilder/kudo/pkg/kudoctl/oci/ocitest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected Reference
+		err      string
+	}{
+		{"oci://registry.example.com/operators/kafka:1.0.0", Reference{"registry.example.com", "operators/kafka", "1.0.0"}, ""},
+		{"oci://localhost:5000/kafka", Reference{"localhost:5000", "kafka", ""}, ""},
+		{"oci://localhost:5000/kafka:latest", Reference{"localhost:5000", "kafka", "latest"}, ""},
+		{"registry.example.com/kafka", Reference{}, "invalid OCI reference registry.example.com/kafka: missing oci:// prefix"},
+		{"oci://registry.example.com", Reference{}, "invalid OCI reference oci://registry.example.com: expecting oci://registry/repository[:tag]"},
+		{"oci://registry.example.com/Kafka", Reference{}, "invalid OCI reference oci://registry.example.com/Kafka: repository has to be lowercase and not empty"},
+	}
+
+	for _, tt := range tests {
+		ref, err := ParseReference(tt.name)
+		if tt.err != "" {
+			assert.EqualError(t, err, tt.err, tt.name)
+			continue
+		}
+		assert.NoError(t, err, tt.name)
+		assert.Equal(t, tt.expected, ref, tt.name)
+		assert.Equal(t, tt.name, ref.String(), tt.name)
+	}
+}
+
+func TestClient_PushPull(t *testing.T) {
+	for _, token := range []string{"", "secret"} {
+		registry := ocitest.NewRegistry()
+		registry.Token = token
+
+		ref, _ := ParseReference("oci://" + registry.Host() + "/operators/kafka:1.0.0")
+		client, err := NewClient(Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pkg := []byte("package")
+		_, err = client.Push(ref, pkg, Config{Name: "kafka", Version: "1.0.0"})
+		assert.NoError(t, err, "push with token %q", token)
+		// pushing the same content again only uploads the manifest
+		_, err = client.Push(ref, pkg, Config{Name: "kafka", Version: "1.0.0"})
+		assert.NoError(t, err, "second push with token %q", token)
+
+		puller, _ := NewClient(Options{})
+		content, err := puller.Pull(ref)
+		assert.NoError(t, err, "pull with token %q", token)
+		assert.Equal(t, pkg, content)
+
+		_, err = client.Pull(ref.WithTag("2.0.0"))
+		assert.Error(t, err, "pull of missing tag with token %q", token)
+
+		registry.Close()
+	}
+}
@@ -0,0 +1,106 @@
+// Package ocitest provides an in-process OCI registry for tests.
+package ocitest
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Registry is a minimal in-memory implementation of the parts of the OCI distribution API used by KUDO.
+// If a token is set, the registry requires a bearer token which it hands out at /token.
+type Registry struct {
+	*httptest.Server
+
+	Token string
+
+	mu        sync.Mutex
+	blobs     map[string][]byte
+	manifests map[string][]byte
+}
+
+var (
+	manifestPath = regexp.MustCompile(`^/v2/(.+)/manifests/([^/]+)$`)
+	blobPath     = regexp.MustCompile(`^/v2/(.+)/blobs/(sha256:[a-f0-9]{64})$`)
+	uploadPath   = regexp.MustCompile(`^/v2/(.+)/blobs/uploads/(\w*)$`)
+)
+
+// NewRegistry starts a registry. Callers should call Close when finished.
+func NewRegistry() *Registry {
+	r := &Registry{
+		blobs:     map[string][]byte{},
+		manifests: map[string][]byte{},
+	}
+	r.Server = httptest.NewServer(http.HandlerFunc(r.serve))
+	return r
+}
+
+// Host returns the host and port of the registry to be used in references
+func (r *Registry) Host() string {
+	return strings.TrimPrefix(r.URL, "http://")
+}
+
+func (r *Registry) serve(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == "/token" {
+		fmt.Fprintf(w, `{"token": %q}`, r.Token)
+		return
+	}
+	if r.Token != "" && req.Header.Get("Authorization") != "Bearer "+r.Token {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test"`, r.URL))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	body, _ := ioutil.ReadAll(req.Body)
+	switch {
+	case manifestPath.MatchString(req.URL.Path):
+		m := manifestPath.FindStringSubmatch(req.URL.Path)
+		key := m[1] + ":" + m[2]
+		if req.Method == "PUT" {
+			r.manifests[key] = body
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		content, ok := r.manifests[key]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+		w.Write(content)
+
+	case uploadPath.MatchString(req.URL.Path):
+		m := uploadPath.FindStringSubmatch(req.URL.Path)
+		if req.Method == "POST" {
+			w.Header().Set("Location", fmt.Sprintf("/v2/%s/blobs/uploads/upload%d", m[1], len(r.blobs)))
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		digest := req.URL.Query().Get("digest")
+		if digest != fmt.Sprintf("sha256:%x", sha256.Sum256(body)) {
+			http.Error(w, "digest mismatch", http.StatusBadRequest)
+			return
+		}
+		r.blobs[digest] = body
+		w.WriteHeader(http.StatusCreated)
+
+	case blobPath.MatchString(req.URL.Path):
+		content, ok := r.blobs[blobPath.FindStringSubmatch(req.URL.Path)[2]]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		w.Write(content)
+
+	default:
+		http.NotFound(w, req)
+	}
+}
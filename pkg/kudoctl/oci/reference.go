@@ -0,0 +1,68 @@
+package oci
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Scheme is the prefix of operator packages stored in an OCI registry, e.g. oci://registry.example.com/operators/kafka:1.0.0
+const Scheme = "oci://"
+
+var tagRegexp = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+
+// Reference points to an operator package in an OCI registry
+type Reference struct {
+	// Registry is the host and optional port of the registry
+	Registry string
+	// Repository is the path of the repository in the registry, e.g. operators/kafka
+	Repository string
+	// Tag is usually the version of the operator, it is empty if the reference has no tag
+	Tag string
+}
+
+// IsReference returns true if name refers to a package in an OCI registry
+func IsReference(name string) bool {
+	return strings.HasPrefix(name, Scheme)
+}
+
+// ParseReference parses a reference of the form oci://registry/repository[:tag]
+func ParseReference(name string) (Reference, error) {
+	if !IsReference(name) {
+		return Reference{}, fmt.Errorf("invalid OCI reference %s: missing %s prefix", name, Scheme)
+	}
+	ref := strings.TrimPrefix(name, Scheme)
+
+	slash := strings.Index(ref, "/")
+	if slash <= 0 || slash == len(ref)-1 {
+		return Reference{}, fmt.Errorf("invalid OCI reference %s: expecting %sregistry/repository[:tag]", name, Scheme)
+	}
+	r := Reference{Registry: ref[:slash], Repository: ref[slash+1:]}
+
+	// a colon after the last slash separates the tag, other colons belong to the registry port
+	if colon := strings.LastIndex(r.Repository, ":"); colon > strings.LastIndex(r.Repository, "/") {
+		r.Tag = r.Repository[colon+1:]
+		r.Repository = r.Repository[:colon]
+		if !tagRegexp.MatchString(r.Tag) {
+			return Reference{}, fmt.Errorf("invalid OCI reference %s: invalid tag %q", name, r.Tag)
+		}
+	}
+	if r.Repository == "" || strings.ToLower(r.Repository) != r.Repository {
+		return Reference{}, fmt.Errorf("invalid OCI reference %s: repository has to be lowercase and not empty", name)
+	}
+	return r, nil
+}
+
+// WithTag returns a copy of the reference with the given tag
+func (r Reference) WithTag(tag string) Reference {
+	r.Tag = tag
+	return r
+}
+
+func (r Reference) String() string {
+	s := Scheme + r.Registry + "/" + r.Repository
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	return s
+}
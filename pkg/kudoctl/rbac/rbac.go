@@ -0,0 +1,183 @@
+// Package rbac computes the permissions the resources of an operator version need to be applied with.
+package rbac
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kudobuilder/kudo/pkg/apis/kudo/v1alpha1"
+	"github.com/kudobuilder/kudo/pkg/engine"
+	"github.com/kudobuilder/kudo/pkg/util/kudo"
+	"github.com/kudobuilder/kudo/pkg/util/template"
+
+	"github.com/pkg/errors"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	// applyVerbs are the verbs KUDO needs to create and update the resources of a step
+	applyVerbs = []string{"create", "patch"}
+	// deleteVerbs are the verbs KUDO needs to delete the resources of a step and to prune the resources of prior
+	// operator versions
+	deleteVerbs = []string{"delete"}
+	// roles and roleBindings need additional verbs to be applied: creating a role with permissions the service account
+	// does not have needs escalate, binding a role needs bind on the role or cluster role
+	roles        = schema.GroupResource{Group: rbacv1.GroupName, Resource: "roles"}
+	clusterRoles = schema.GroupResource{Group: rbacv1.GroupName, Resource: "clusterroles"}
+	roleBindings = schema.GroupResource{Group: rbacv1.GroupName, Resource: "rolebindings"}
+	// ownerFinalizers is the subresource KUDO needs to update to set owner references to the instance which block its
+	// deletion
+	ownerFinalizers = schema.GroupResource{Group: "kudo.dev", Resource: "instances/finalizers"}
+)
+
+// Rules returns the policy rules a service account needs to apply the resources of all plans of the operator version.
+// Plans after which the resources of prior operator versions are pruned also need to delete the rendered resources. When
+// impersonating a service account, only the kinds rendered by the plan are pruned. Applying roles and role bindings also
+// needs to escalate and bind roles. The templates are rendered with the default values of the
+// parameters, resources which are only rendered with other values are missed. Resources are identified by their group
+// and the plural of their kind, as there is no discovery.
+func Rules(ov *v1alpha1.OperatorVersion) ([]rbacv1.PolicyRule, error) {
+	params := map[string]string{}
+	for _, p := range ov.Spec.Parameters {
+		params[p.Name] = ""
+		if p.Default != nil {
+			params[p.Name] = *p.Default
+		}
+	}
+	configs := map[string]interface{}{
+		"OperatorName": ov.Spec.Operator.Name,
+		"Name":         "instance",
+		"Namespace":    "namespace",
+		"Params":       params,
+	}
+
+	verbs := map[schema.GroupResource]map[string]bool{}
+	addVerbs := func(gr schema.GroupResource, vs []string) {
+		if verbs[gr] == nil {
+			verbs[gr] = map[string]bool{}
+		}
+		for _, v := range vs {
+			verbs[gr][v] = true
+		}
+	}
+	addVerbs(ownerFinalizers, []string{"update"})
+
+	e := engine.New()
+	for _, planName := range sortedPlans(ov.Spec.Plans) {
+		configs["PlanName"] = planName
+		pruned := kudo.PrunedPlans[planName]
+		for _, phase := range ov.Spec.Plans[planName].Phases {
+			configs["PhaseName"] = phase.Name
+			for i, step := range phase.Steps {
+				configs["StepName"] = step.Name
+				configs["StepNumber"] = strconv.Itoa(i)
+				stepVerbs := applyVerbs
+				if pruned {
+					stepVerbs = append(stepVerbs[:len(stepVerbs):len(stepVerbs)], deleteVerbs...)
+				}
+				if step.Delete {
+					stepVerbs = deleteVerbs
+				}
+
+				for _, taskName := range step.Tasks {
+					task, ok := ov.Spec.Tasks[taskName]
+					if !ok {
+						return nil, fmt.Errorf("step %s of plan %s references the unknown task %s", step.Name, planName, taskName)
+					}
+					for _, name := range task.Resources {
+						template, ok := ov.Spec.Templates[name]
+						if !ok {
+							return nil, fmt.Errorf("task %s references the unknown template %s", taskName, name)
+						}
+						rendered, err := e.Render(template, configs)
+						if err != nil {
+							return nil, errors.Wrapf(err, "rendering template %s", name)
+						}
+						resources, err := groupResources(rendered)
+						if err != nil {
+							return nil, errors.Wrapf(err, "parsing template %s", name)
+						}
+						for _, gr := range resources {
+							addVerbs(gr, stepVerbs)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	if verbs[roles]["create"] {
+		addVerbs(roles, []string{"escalate"})
+	}
+	if verbs[roleBindings]["create"] {
+		addVerbs(roles, []string{"bind"})
+		addVerbs(clusterRoles, []string{"bind"})
+	}
+
+	return policyRules(verbs), nil
+}
+
+// groupResources returns the group and resource of every object of a rendered template
+func groupResources(rendered string) ([]schema.GroupResource, error) {
+	objs, err := template.ParseUnstructuredObjects(rendered)
+	if err != nil {
+		return nil, err
+	}
+	var resources []schema.GroupResource
+	for _, obj := range objs {
+		if obj.GetKind() == "" {
+			continue
+		}
+		gv, err := schema.ParseGroupVersion(obj.GetAPIVersion())
+		if err != nil {
+			return nil, err
+		}
+		plural, _ := meta.UnsafeGuessKindToResource(gv.WithKind(obj.GetKind()))
+		resources = append(resources, plural.GroupResource())
+	}
+	return resources, nil
+}
+
+// policyRules returns one rule for every group and set of verbs, sorted by group and verbs
+func policyRules(verbs map[schema.GroupResource]map[string]bool) []rbacv1.PolicyRule {
+	rules := map[string]*rbacv1.PolicyRule{}
+	for gr, vs := range verbs {
+		ruleVerbs := make([]string, 0, len(vs))
+		for v := range vs {
+			ruleVerbs = append(ruleVerbs, v)
+		}
+		sort.Strings(ruleVerbs)
+
+		key := gr.Group + "/" + strings.Join(ruleVerbs, ",")
+		if rules[key] == nil {
+			rules[key] = &rbacv1.PolicyRule{APIGroups: []string{gr.Group}, Verbs: ruleVerbs}
+		}
+		rules[key].Resources = append(rules[key].Resources, gr.Resource)
+	}
+
+	keys := make([]string, 0, len(rules))
+	for k := range rules {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	result := make([]rbacv1.PolicyRule, 0, len(keys))
+	for _, k := range keys {
+		sort.Strings(rules[k].Resources)
+		result = append(result, *rules[k])
+	}
+	return result
+}
+
+func sortedPlans(plans map[string]v1alpha1.Plan) []string {
+	names := make([]string, 0, len(plans))
+	for name := range plans {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
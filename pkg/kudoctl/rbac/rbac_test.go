@@ -0,0 +1,94 @@
+package rbac
+
+import (
+	"testing"
+
+	"github.com/kudobuilder/kudo/pkg/apis/kudo/v1alpha1"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/bundle"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+func TestRulesOfPackage(t *testing.T) {
+	b, err := bundle.NewBundle(afero.NewOsFs(), "../bundle/testdata/zk")
+	assert.NoError(t, err)
+	crds, err := b.GetCRDs()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	rules, err := Rules(crds.OperatorVersion)
+	assert.NoError(t, err)
+	assert.Equal(t, []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"services"}, Verbs: []string{"create", "delete", "patch"}},
+		{APIGroups: []string{"apps"}, Resources: []string{"statefulsets"}, Verbs: []string{"create", "delete", "patch"}},
+		{APIGroups: []string{"batch"}, Resources: []string{"jobs"}, Verbs: []string{"create", "patch"}},
+		{APIGroups: []string{"kudo.dev"}, Resources: []string{"instances/finalizers"}, Verbs: []string{"update"}},
+		{APIGroups: []string{"policy"}, Resources: []string{"poddisruptionbudgets"}, Verbs: []string{"create", "delete", "patch"}},
+	}, rules)
+}
+
+func TestRules(t *testing.T) {
+	configMaps := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: {{ .Name }}\n---\napiVersion: v1\nkind: Secret\nmetadata:\n  name: {{ .Params.SECRET }}\n"
+	role := "apiVersion: rbac.authorization.k8s.io/v1\nkind: Role\nmetadata:\n  name: {{ .Name }}\n---\napiVersion: rbac.authorization.k8s.io/v1\nkind: RoleBinding\nmetadata:\n  name: {{ .Name }}\n"
+	separator := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: {{ .Name }}\ndata:\n  separator: a---b\n"
+	finalizers := rbacv1.PolicyRule{APIGroups: []string{"kudo.dev"}, Resources: []string{"instances/finalizers"}, Verbs: []string{"update"}}
+	plan := func(delete bool) v1alpha1.Plan {
+		return v1alpha1.Plan{Phases: []v1alpha1.Phase{{Name: "phase", Steps: []v1alpha1.Step{{Name: "step", Tasks: []string{"task"}, Delete: delete}}}}}
+	}
+
+	tests := []struct {
+		name     string
+		plans    map[string]v1alpha1.Plan
+		template string
+		rules    []rbacv1.PolicyRule
+		err      string
+	}{
+		{"apply", map[string]v1alpha1.Plan{"backup": plan(false)}, configMaps, []rbacv1.PolicyRule{
+			{APIGroups: []string{""}, Resources: []string{"configmaps", "secrets"}, Verbs: []string{"create", "patch"}},
+			finalizers,
+		}, ""},
+		{"apply and prune", map[string]v1alpha1.Plan{"deploy": plan(false)}, configMaps, []rbacv1.PolicyRule{
+			{APIGroups: []string{""}, Resources: []string{"configmaps", "secrets"}, Verbs: []string{"create", "delete", "patch"}},
+			finalizers,
+		}, ""},
+		{"roles", map[string]v1alpha1.Plan{"backup": plan(false)}, role, []rbacv1.PolicyRule{
+			finalizers,
+			{APIGroups: []string{"rbac.authorization.k8s.io"}, Resources: []string{"clusterroles"}, Verbs: []string{"bind"}},
+			{APIGroups: []string{"rbac.authorization.k8s.io"}, Resources: []string{"roles"}, Verbs: []string{"bind", "create", "escalate", "patch"}},
+			{APIGroups: []string{"rbac.authorization.k8s.io"}, Resources: []string{"rolebindings"}, Verbs: []string{"create", "patch"}},
+		}, ""},
+		{"delete", map[string]v1alpha1.Plan{"cleanup": plan(true)}, configMaps, []rbacv1.PolicyRule{
+			{APIGroups: []string{""}, Resources: []string{"configmaps", "secrets"}, Verbs: []string{"delete"}},
+			finalizers,
+		}, ""},
+		{"separator in value", map[string]v1alpha1.Plan{"backup": plan(false)}, separator, []rbacv1.PolicyRule{
+			{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"create", "patch"}},
+			finalizers,
+		}, ""},
+		{"unknown parameter", map[string]v1alpha1.Plan{"deploy": plan(false)}, "{{ .Params.UNKNOWN }}", nil,
+			`rendering template resource.yaml: error rendering template: template: tpl:1:10: executing "tpl" at <.Params.UNKNOWN>: map has no entry for key "UNKNOWN"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := "secret"
+			ov := &v1alpha1.OperatorVersion{Spec: v1alpha1.OperatorVersionSpec{
+				Parameters: []v1alpha1.Parameter{{Name: "SECRET", Default: &secret}},
+				Plans:      tt.plans,
+				Tasks:      map[string]v1alpha1.TaskSpec{"task": {Resources: []string{"resource.yaml"}}},
+				Templates:  map[string]string{"resource.yaml": tt.template},
+			}}
+
+			rules, err := Rules(ov)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.rules, rules)
+		})
+	}
+}
@@ -16,6 +16,8 @@ import (
 	"github.com/pkg/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
 
 	// Import Kubernetes authentication providers to support GKE, etc.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
@@ -143,6 +145,94 @@ func (c *Client) GetOperatorVersion(name, namespace string) (*v1alpha1.OperatorV
 	return ov, err
 }
 
+// GetPlanExecution queries kubernetes api for planexecution of given name in given namespace
+// returns error for all other errors that not found, not found is treated as result being 'nil, nil'
+func (c *Client) GetPlanExecution(name, namespace string) (*v1alpha1.PlanExecution, error) {
+	pe, err := c.clientset.KudoV1alpha1().PlanExecutions(namespace).Get(name, v1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	return pe, err
+}
+
+// WatchInstance starts a watch on the instance of given name in given namespace
+func (c *Client) WatchInstance(name, namespace string) (watch.Interface, error) {
+	return c.clientset.KudoV1alpha1().Instances(namespace).Watch(v1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("metadata.name", name).String(),
+	})
+}
+
+// WatchPlanExecutions starts a watch on all planexecutions created for the given instance
+func (c *Client) WatchPlanExecutions(instanceName, namespace string) (watch.Interface, error) {
+	return c.clientset.KudoV1alpha1().PlanExecutions(namespace).Watch(v1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", kudo.InstanceLabel, instanceName),
+	})
+}
+
+// WaitForPlan waits until a plan execution other than previousPlan becomes the active plan of the instance and that
+// plan execution reaches a final state. Pass an empty previousPlan for a freshly installed instance.
+//
+// Returns the finished plan execution. If it ends in ERROR or the timeout is reached first, an error naming the
+// failing or unfinished phase and step is returned.
+func (c *Client) WaitForPlan(instanceName, namespace, previousPlan string, timeout time.Duration) (*v1alpha1.PlanExecution, error) {
+	deadlineChan := time.NewTimer(timeout).C
+	checkPlanTicker := time.NewTicker(500 * time.Millisecond)
+	defer checkPlanTicker.Stop()
+
+	var pe *v1alpha1.PlanExecution
+	for {
+		instance, err := c.GetInstance(instanceName, namespace)
+		if err != nil {
+			return nil, err
+		}
+		if instance == nil {
+			return nil, fmt.Errorf("instance %s in namespace %s does not exist in the cluster", instanceName, namespace)
+		}
+
+		activePlan := instance.Status.ActivePlan.Name
+		if activePlan != "" && activePlan != previousPlan {
+			pe, err = c.GetPlanExecution(activePlan, namespace)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if pe != nil {
+			switch pe.Status.State {
+			case v1alpha1.PhaseStateComplete:
+				return pe, nil
+			case v1alpha1.PhaseStateError:
+				return pe, fmt.Errorf("plan %s of instance %s failed%s", pe.Spec.PlanName, instanceName, describeProgress(pe))
+			}
+		}
+
+		select {
+		case <-deadlineChan:
+			if pe == nil {
+				return nil, fmt.Errorf("timed out waiting for a plan of instance %s to start", instanceName)
+			}
+			return pe, fmt.Errorf("timed out waiting for plan %s of instance %s to finish%s", pe.Spec.PlanName, instanceName, describeProgress(pe))
+		case <-checkPlanTicker.C:
+		}
+	}
+}
+
+// describeProgress names the first phase and step of a plan execution that are not complete
+func describeProgress(pe *v1alpha1.PlanExecution) string {
+	for _, phase := range pe.Status.Phases {
+		if phase.State == v1alpha1.PhaseStateComplete {
+			continue
+		}
+		for _, step := range phase.Steps {
+			if step.State != v1alpha1.PhaseStateComplete {
+				return fmt.Sprintf(" in phase %s step %s (%s)", phase.Name, step.Name, step.State)
+			}
+		}
+		return fmt.Sprintf(" in phase %s (%s)", phase.Name, phase.State)
+	}
+	return ""
+}
+
 // UpdateInstance updates operatorversion on instance
 func (c *Client) UpdateInstance(instanceName, namespace string, operatorVersionName *string, parameters map[string]string) error {
 	instanceSpec := v1alpha1.InstanceSpec{}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/kudobuilder/kudo/pkg/util/kudo"
 
@@ -574,3 +575,71 @@ func TestKudoClient_UpdateOperatorVersion(t *testing.T) {
 		}
 	}
 }
+
+func TestKudoClient_WaitForPlan(t *testing.T) {
+	installNamespace := "default"
+	testInstance := v1alpha1.Instance{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: installNamespace,
+		},
+		Spec: v1alpha1.InstanceSpec{
+			OperatorVersion: v1.ObjectReference{
+				Name: "test-1.0",
+			},
+		},
+	}
+	testPlan := func(state v1alpha1.PhaseState) *v1alpha1.PlanExecution {
+		return &v1alpha1.PlanExecution{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-deploy-1",
+				Namespace: installNamespace,
+			},
+			Spec: v1alpha1.PlanExecutionSpec{PlanName: "deploy"},
+			Status: v1alpha1.PlanExecutionStatus{
+				State: state,
+				Phases: []v1alpha1.PhaseStatus{
+					{Name: "zookeeper", State: v1alpha1.PhaseStateComplete, Steps: []v1alpha1.StepStatus{{Name: "everything", State: v1alpha1.PhaseStateComplete}}},
+					{Name: "kafka", State: state, Steps: []v1alpha1.StepStatus{{Name: "brokers", State: state}}},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		activePlan   string
+		previousPlan string
+		plan         *v1alpha1.PlanExecution
+		err          string
+	}{
+		{"plan is complete", "test-deploy-1", "", testPlan(v1alpha1.PhaseStateComplete), ""},
+		{"plan failed", "test-deploy-1", "", testPlan(v1alpha1.PhaseStateError), "plan deploy of instance test failed in phase kafka step brokers (ERROR)"},
+		{"plan in progress", "test-deploy-1", "", testPlan(v1alpha1.PhaseStateInProgress), "timed out waiting for plan deploy of instance test to finish in phase kafka step brokers (IN_PROGRESS)"},
+		{"no plan started", "", "", nil, "timed out waiting for a plan of instance test to start"},
+		{"only previous plan exists", "test-deploy-1", "test-deploy-1", testPlan(v1alpha1.PhaseStateComplete), "timed out waiting for a plan of instance test to start"},
+	}
+
+	for _, tt := range tests {
+		k2o := newTestSimpleK2o()
+
+		instance := testInstance
+		instance.Status.ActivePlan.Name = tt.activePlan
+		if _, err := k2o.clientset.KudoV1alpha1().Instances(installNamespace).Create(&instance); err != nil {
+			t.Fatalf("%s: error creating instance in test setup: %v", tt.name, err)
+		}
+		if tt.plan != nil {
+			if _, err := k2o.clientset.KudoV1alpha1().PlanExecutions(installNamespace).Create(tt.plan); err != nil {
+				t.Fatalf("%s: error creating plan execution in test setup: %v", tt.name, err)
+			}
+		}
+
+		_, err := k2o.WaitForPlan(testInstance.Name, installNamespace, tt.previousPlan, time.Second)
+		if tt.err == "" && err != nil {
+			t.Errorf("%s: expected no error but got %v", tt.name, err)
+		}
+		if tt.err != "" && (err == nil || err.Error() != tt.err) {
+			t.Errorf("%s:\nexpected error: %v\n     got: %v", tt.name, tt.err, err)
+		}
+	}
+}
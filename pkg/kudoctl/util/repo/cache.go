@@ -0,0 +1,159 @@
+package repo
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kudobuilder/kudo/pkg/kudoctl/bundle"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/files"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/http"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/kudohome"
+
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	cachedIndexFileName      = "index.yaml"
+	cachedValidatorsFileName = "index-validators.yaml"
+	cachedPackagesDirName    = "packages"
+)
+
+// Cache stores downloaded repository index files and packages on the local filesystem, usually in $KUDO_HOME/cache.
+// Index files are kept per repository together with the HTTP validators needed to revalidate them,
+// packages are kept per repository and digest.
+type Cache struct {
+	fs   afero.Fs
+	path string
+}
+
+// NewCache creates a cache in the cache directory of the given KUDO home
+func NewCache(fs afero.Fs, home kudohome.Home) *Cache {
+	return &Cache{fs: fs, path: home.Cache()}
+}
+
+// repositoryPath returns the path of elem in the directory of the repository. The name is escaped to be a single
+// directory within the cache, "." and ".." are left unchanged by PathEscape and have their dots escaped, too.
+func (c *Cache) repositoryPath(repoName string, elem ...string) string {
+	dir := url.PathEscape(repoName)
+	if dir == "." || dir == ".." {
+		dir = strings.Replace(dir, ".", "%2E", -1)
+	}
+	p := []string{c.path, dir}
+	p = append(p, elem...)
+	return filepath.Join(p...)
+}
+
+// Index returns the cached index file of a repository and the validators it was downloaded with.
+// The returned content is nil if the index file is not cached.
+func (c *Cache) Index(repoName string) ([]byte, http.Validators, error) {
+	validators := http.Validators{}
+	path := c.repositoryPath(repoName, cachedIndexFileName)
+	exists, err := afero.Exists(c.fs, path)
+	if err != nil || !exists {
+		return nil, validators, err
+	}
+	content, err := afero.ReadFile(c.fs, path)
+	if err != nil {
+		return nil, validators, err
+	}
+
+	// missing or broken validators only cost a full download
+	if v, err := afero.ReadFile(c.fs, c.repositoryPath(repoName, cachedValidatorsFileName)); err == nil {
+		if err := yaml.Unmarshal(v, &validators); err != nil {
+			validators = http.Validators{}
+		}
+	}
+	return content, validators, nil
+}
+
+// StoreIndex stores the index file of a repository together with the validators it was downloaded with
+func (c *Cache) StoreIndex(repoName string, content []byte, validators http.Validators) error {
+	if err := c.fs.MkdirAll(c.repositoryPath(repoName), 0755); err != nil {
+		return err
+	}
+	if err := afero.WriteFile(c.fs, c.repositoryPath(repoName, cachedIndexFileName), content, 0644); err != nil {
+		return err
+	}
+	v, err := yaml.Marshal(validators)
+	if err != nil {
+		return err
+	}
+	return afero.WriteFile(c.fs, c.repositoryPath(repoName, cachedValidatorsFileName), v, 0644)
+}
+
+// Package returns the cached package of a repository with the given digest and its detached signature, if one was
+// cached with it. The content is verified against the digest before it is returned; a package that does not match is
+// removed from the cache.
+// The returned content is nil if the package is not cached, the returned signature is nil if no signature is cached.
+func (c *Cache) Package(repoName string, digest string) ([]byte, []byte, error) {
+	path := c.packagePath(repoName, digest)
+	exists, err := afero.Exists(c.fs, path)
+	if err != nil || !exists {
+		return nil, nil, err
+	}
+	content, err := afero.ReadFile(c.fs, path)
+	if err != nil {
+		return nil, nil, err
+	}
+	actual, err := files.Sha256Sum(bytes.NewReader(content))
+	if err != nil {
+		return nil, nil, err
+	}
+	if actual != digest {
+		fmt.Printf("Warning: removing cached package %s with wrong digest %s\n", path, actual)
+		return nil, nil, c.RemovePackage(repoName, digest)
+	}
+
+	signaturePath := path + bundle.SignatureExtension
+	exists, err = afero.Exists(c.fs, signaturePath)
+	if err != nil || !exists {
+		return content, nil, err
+	}
+	signature, err := afero.ReadFile(c.fs, signaturePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return content, signature, nil
+}
+
+// StorePackage stores a package of a repository together with its detached signature, which is stored next to the
+// package unless it is nil. Packages that do not match the given digest are not stored.
+func (c *Cache) StorePackage(repoName string, digest string, content []byte, signature []byte) error {
+	actual, err := files.Sha256Sum(bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	if actual != digest {
+		return fmt.Errorf("package digest %s does not match expected digest %s", actual, digest)
+	}
+	if err := c.fs.MkdirAll(c.repositoryPath(repoName, cachedPackagesDirName), 0755); err != nil {
+		return err
+	}
+	path := c.packagePath(repoName, digest)
+	if signature != nil {
+		if err := afero.WriteFile(c.fs, path+bundle.SignatureExtension, signature, 0644); err != nil {
+			return err
+		}
+	}
+	return afero.WriteFile(c.fs, path, content, 0644)
+}
+
+// RemovePackage removes a cached package of a repository and its signature
+func (c *Cache) RemovePackage(repoName string, digest string) error {
+	path := c.packagePath(repoName, digest)
+	for _, p := range []string{path, path + bundle.SignatureExtension} {
+		if err := c.fs.Remove(p); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Cache) packagePath(repoName string, digest string) string {
+	return c.repositoryPath(repoName, cachedPackagesDirName, digest+".tgz")
+}
@@ -0,0 +1,146 @@
+package repo
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kudobuilder/kudo/pkg/kudoctl/bundle"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/files"
+	kudohttp "github.com/kudobuilder/kudo/pkg/kudoctl/http"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/kudohome"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_Index(t *testing.T) {
+	cache := NewCache(afero.NewMemMapFs(), kudohome.Home("/kudo"))
+
+	content, _, err := cache.Index("community")
+	assert.NoError(t, err)
+	assert.Nil(t, content)
+
+	validators := kudohttp.Validators{ETag: `"abc"`}
+	assert.NoError(t, cache.StoreIndex("community", []byte("apiVersion: v1"), validators))
+
+	content, cachedValidators, err := cache.Index("community")
+	assert.NoError(t, err)
+	assert.Equal(t, "apiVersion: v1", string(content))
+	assert.Equal(t, validators, cachedValidators)
+}
+
+func TestCache_Package(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	cache := NewCache(fs, kudohome.Home("/kudo"))
+	pkg := []byte("package")
+	digest, _ := files.Sha256Sum(bytes.NewReader(pkg))
+
+	content, signature, err := cache.Package("community", digest)
+	assert.NoError(t, err)
+	assert.Nil(t, content)
+	assert.Nil(t, signature)
+
+	assert.Error(t, cache.StorePackage("community", "wrong-digest", pkg, nil), "packages not matching the digest are not stored")
+	assert.NoError(t, cache.StorePackage("community", digest, pkg, nil))
+
+	content, signature, err = cache.Package("community", digest)
+	assert.NoError(t, err)
+	assert.Equal(t, pkg, content)
+	assert.Nil(t, signature)
+
+	assert.NoError(t, cache.StorePackage("community", digest, pkg, []byte("signature")))
+	content, signature, err = cache.Package("community", digest)
+	assert.NoError(t, err)
+	assert.Equal(t, pkg, content)
+	assert.Equal(t, []byte("signature"), signature)
+
+	// a corrupted cache entry is dropped
+	path := cache.repositoryPath("community", cachedPackagesDirName, digest+".tgz")
+	assert.NoError(t, afero.WriteFile(fs, path, []byte("corrupted"), 0644))
+	content, _, err = cache.Package("community", digest)
+	assert.NoError(t, err)
+	assert.Nil(t, content)
+	exists, _ := afero.Exists(fs, path)
+	assert.False(t, exists)
+	exists, _ = afero.Exists(fs, path+bundle.SignatureExtension)
+	assert.False(t, exists, "the signature is dropped with the package")
+}
+
+func TestClient_CachedDownloads(t *testing.T) {
+	pkg := []byte("package")
+	digest, _ := files.Sha256Sum(bytes.NewReader(pkg))
+	index := `apiVersion: v1
+entries:
+  kafka:
+  - name: kafka
+    version: 0.1.0
+    digest: ` + digest + "\n"
+
+	indexRequests, notModified, packageRequests := 0, 0, 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/index.yaml", func(w http.ResponseWriter, r *http.Request) {
+		indexRequests++
+		if r.Header.Get("If-None-Match") == `"1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"1"`)
+		w.Write([]byte(index))
+	})
+	mux.HandleFunc("/kafka-0.1.0.tgz", func(w http.ResponseWriter, r *http.Request) {
+		packageRequests++
+		w.Write(pkg)
+	})
+	server := httptest.NewServer(mux)
+
+	fs := afero.NewMemMapFs()
+	client, _ := NewClient(&Configuration{Name: "test", URL: server.URL})
+	client.Cache = NewCache(fs, kudohome.Home("/kudo"))
+
+	for i := 0; i < 2; i++ {
+		reader, err := client.GetPackageReader("kafka", "")
+		assert.NoError(t, err)
+		content, _ := ioutil.ReadAll(reader)
+		assert.Equal(t, pkg, content)
+	}
+	assert.Equal(t, 2, indexRequests)
+	assert.Equal(t, 1, notModified, "the cached index file is revalidated")
+	assert.Equal(t, 1, packageRequests, "the cached package is used")
+
+	// the cache is used once the repository is gone
+	server.Close()
+	client.Offline = true
+	reader, err := client.GetPackageReader("kafka", "0.1.0")
+	assert.NoError(t, err)
+	content, _ := ioutil.ReadAll(reader)
+	assert.Equal(t, pkg, content)
+
+	_, err = client.GetPackageReader("kafka", "0.2.0")
+	assert.EqualError(t, err, "getting kafka in index file: no operator version found for kafka-0.2.0")
+
+	offline, _ := NewClient(&Configuration{Name: "other", URL: server.URL})
+	offline.Cache = client.Cache
+	offline.Offline = true
+	_, err = offline.DownloadIndexFile()
+	assert.EqualError(t, err, "no cached index file for repository other, run 'kudo repo update' while online")
+}
+
+func TestCache_RepositoryPath(t *testing.T) {
+	cache := &Cache{fs: afero.NewMemMapFs(), path: "/home/cache"}
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"community", "/home/cache/community/index.yaml"},
+		{"a/b", "/home/cache/a%2Fb/index.yaml"},
+		{".", "/home/cache/%2E/index.yaml"},
+		{"..", "/home/cache/%2E%2E/index.yaml"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, cache.repositoryPath(tt.name, cachedIndexFileName), tt.name)
+	}
+}
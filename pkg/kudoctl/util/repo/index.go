@@ -5,6 +5,7 @@ import (
 	"io"
 	"path/filepath"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/kudobuilder/kudo/pkg/kudoctl/bundle"
@@ -99,26 +100,88 @@ func (i IndexFile) Write(w io.Writer) error {
 
 // GetByNameAndVersion returns the operator of given name and version.
 // If no specific version is required, pass an empty string as version and the
-// the latest version will be returned.
+// the latest version will be returned. The version can also be a semver constraint like `~2.3` or `>=1.0 <2.0`,
+// in which case the latest matching version is returned. Removed versions are only returned if they are
+// requested by their exact version.
 func (i IndexFile) GetByNameAndVersion(name, version string) (*PackageVersion, error) {
 	vs, ok := i.Entries[name]
 	if !ok || len(vs) == 0 {
 		return nil, fmt.Errorf("no operator found for: %s", name)
 	}
 
+	if version == "" {
+		for _, ver := range vs {
+			if !ver.Removed {
+				return ver, nil
+			}
+		}
+		return nil, fmt.Errorf("no operator version found for %s", name)
+	}
+
 	for _, ver := range vs {
-		if ver.Version == version || version == "" {
+		if ver.Version == version {
 			return ver, nil
 		}
 	}
 
-	if version == "" {
-		return nil, fmt.Errorf("no operator version found for %s", name)
+	constraint, err := bundle.ParseVersionConstraint(version)
+	if err != nil {
+		return nil, err
+	}
+	for _, ver := range vs {
+		if ver.Removed {
+			continue
+		}
+		v, err := semver.NewVersion(ver.Version)
+		if err != nil {
+			continue
+		}
+		if constraint.Check(v) {
+			return ver, nil
+		}
 	}
 
 	return nil, fmt.Errorf("no operator version found for %s-%v", name, version)
 }
 
+// Search returns the most recent version that is not removed of every operator whose name, description or maintainers contain
+// the term, ignoring case. An empty term matches all operators. The result is sorted by operator name.
+func (i IndexFile) Search(term string) PackageVersions {
+	term = strings.ToLower(term)
+	result := PackageVersions{}
+	for _, vs := range i.Entries {
+		for _, latest := range vs {
+			if latest.Removed {
+				continue
+			}
+			if latest.Metadata != nil && latest.matches(term) {
+				result = append(result, latest)
+			}
+			break
+		}
+	}
+	sort.Slice(result, func(x, y int) bool {
+		return result[x].Name < result[y].Name
+	})
+	return result
+}
+
+// matches returns true if the name, description or a maintainer of the package version contains the lower case term
+func (pv *PackageVersion) matches(term string) bool {
+	if strings.Contains(strings.ToLower(pv.Name), term) || strings.Contains(strings.ToLower(pv.Description), term) {
+		return true
+	}
+	for _, m := range pv.Maintainers {
+		if m == nil {
+			continue
+		}
+		if strings.Contains(strings.ToLower(m.Name), term) || strings.Contains(strings.ToLower(m.Email), term) {
+			return true
+		}
+	}
+	return false
+}
+
 // AddPackageVersion adds an entry to the IndexFile (does not allow dups)
 func (i *IndexFile) AddPackageVersion(pv *PackageVersion) error {
 	name := pv.Name
@@ -204,6 +267,71 @@ func newIndexFile(t *time.Time) *IndexFile {
 	return &i
 }
 
+// Update adds the package versions of another index file, usually created from a directory of packages, to this
+// index file. Existing entries are preserved together with their digest and removed and deprecated flags, because
+// published package versions are expected to be immutable. A package version whose digest changed is an error
+// unless replaceChanged is set, in which case the entry is replaced but keeps its flags.
+func (i *IndexFile) Update(other *IndexFile, replaceChanged bool) error {
+	var errs []string
+	for _, pvs := range other.Entries {
+		for _, pv := range pvs {
+			existing := i.find(pv.Name, pv.Version)
+			if existing == nil {
+				if err := i.AddPackageVersion(pv); err != nil {
+					errs = append(errs, err.Error())
+				}
+				continue
+			}
+			if existing.Digest == pv.Digest {
+				continue
+			}
+			if !replaceChanged {
+				errs = append(errs, fmt.Sprintf("operator '%v' version: %v changed since it was indexed, publish a new version instead", pv.Name, pv.Version))
+				continue
+			}
+			removed, deprecated := existing.Removed, existing.Deprecated
+			*existing = *pv
+			existing.Removed, existing.Deprecated = removed, deprecated
+		}
+	}
+	i.sortPackages()
+	if errs != nil {
+		sort.Strings(errs)
+		return errors.New(strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// SetRemoved marks a package version as removed (yanked) or restores it
+func (i *IndexFile) SetRemoved(name, version string, removed bool) error {
+	pv := i.find(name, version)
+	if pv == nil {
+		return fmt.Errorf("no operator version found for %s-%v", name, version)
+	}
+	pv.Removed = removed
+	return nil
+}
+
+// SetDeprecated marks a package version as deprecated or not deprecated
+func (i *IndexFile) SetDeprecated(name, version string, deprecated bool) error {
+	pv := i.find(name, version)
+	if pv == nil {
+		return fmt.Errorf("no operator version found for %s-%v", name, version)
+	}
+	pv.Deprecated = deprecated
+	return nil
+}
+
+// find returns the package version with exactly the given name and version or nil
+func (i IndexFile) find(name, version string) *PackageVersion {
+	for _, pv := range i.Entries[name] {
+		if pv.Version == version {
+			return pv
+		}
+	}
+	return nil
+}
+
 // IndexDirectory creates an index file for the operators in the path
 func IndexDirectory(fs afero.Fs, path string, url string, now *time.Time) (*IndexFile, error) {
 	archives, err := afero.Glob(fs, filepath.Join(path, "*.tgz"))
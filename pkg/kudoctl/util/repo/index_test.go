@@ -178,3 +178,123 @@ func TestMapPackageFileToPackageVersion(t *testing.T) {
 	assert.Equal(t, pv.URLs[0], "http://localhost/kafka-1.0.0.tgz")
 	assert.Equal(t, pv.Digest, "1234")
 }
+
+func TestIndexFile_Search(t *testing.T) {
+	index := newIndexFile(nil)
+	for _, pv := range []*PackageVersion{
+		{Metadata: &Metadata{Name: "kafka", Version: "0.1.0", Description: "Apache Kafka"}},
+		{Metadata: &Metadata{Name: "kafka", Version: "0.2.0", Description: "Apache Kafka"}},
+		{Metadata: &Metadata{Name: "zookeeper", Version: "0.1.0", Description: "Apache ZooKeeper", Maintainers: []*v1alpha1.Maintainer{{Name: "Ken Sipe", Email: "kensipe@gmail.com"}}}},
+		{Metadata: &Metadata{Name: "mysql", Version: "0.1.0"}},
+	} {
+		if err := index.AddPackageVersion(pv); err != nil {
+			t.Fatal(err)
+		}
+	}
+	index.sortPackages()
+
+	tests := []struct {
+		term     string
+		expected []string
+	}{
+		{"kafka", []string{"kafka-0.2.0"}},
+		{"apache", []string{"kafka-0.2.0", "zookeeper-0.1.0"}},
+		{"KENSIPE", []string{"zookeeper-0.1.0"}},
+		{"", []string{"kafka-0.2.0", "mysql-0.1.0", "zookeeper-0.1.0"}},
+		{"cassandra", []string{}},
+	}
+
+	for _, tt := range tests {
+		found := []string{}
+		for _, pv := range index.Search(tt.term) {
+			found = append(found, pv.Name+"-"+pv.Version)
+		}
+		assert.Equal(t, found, tt.expected, tt.term)
+	}
+}
+
+func TestIndexFile_GetByNameAndVersion(t *testing.T) {
+	index := newIndexFile(nil)
+	for _, pv := range []*PackageVersion{
+		{Metadata: &Metadata{Name: "kafka", Version: "0.9.0"}},
+		{Metadata: &Metadata{Name: "kafka", Version: "1.0.0"}},
+		{Metadata: &Metadata{Name: "kafka", Version: "1.2.0"}},
+		{Metadata: &Metadata{Name: "kafka", Version: "2.3.1"}},
+		{Metadata: &Metadata{Name: "kafka", Version: "2.3.4"}, Removed: true},
+		{Metadata: &Metadata{Name: "kafka", Version: "2.4.0"}},
+	} {
+		if err := index.AddPackageVersion(pv); err != nil {
+			t.Fatal(err)
+		}
+	}
+	index.sortPackages()
+
+	tests := []struct {
+		version  string
+		expected string
+		err      string
+	}{
+		{"", "2.4.0", ""},
+		{"1.0.0", "1.0.0", ""},
+		{"~2.3", "2.3.1", ""},
+		{"2.3.4", "2.3.4", ""},
+		{">=1.0 <2.0", "1.2.0", ""},
+		{">= 1.0, < 1.1", "1.0.0", ""},
+		{"^0.9", "0.9.0", ""},
+		{"1.0 - 1.1", "1.0.0", ""},
+		{"~3.0", "", "no operator version found for kafka-~3.0"},
+		{"latest", "", `invalid version constraint "latest": improper constraint: latest`},
+	}
+
+	for _, tt := range tests {
+		pv, err := index.GetByNameAndVersion("kafka", tt.version)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("%s: expected error %q but got %v", tt.version, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.version, err)
+			continue
+		}
+		assert.Equal(t, pv.Version, tt.expected, tt.version)
+	}
+}
+
+func TestIndexFile_Update(t *testing.T) {
+	index := newIndexFile(nil)
+	published := &PackageVersion{Metadata: &Metadata{Name: "kafka", Version: "1.0.0", Deprecated: true}, Digest: "1234", Removed: true}
+	if err := index.AddPackageVersion(published); err != nil {
+		t.Fatal(err)
+	}
+
+	directory := newIndexFile(nil)
+	for _, pv := range []*PackageVersion{
+		{Metadata: &Metadata{Name: "kafka", Version: "1.0.0"}, Digest: "1234"},
+		{Metadata: &Metadata{Name: "kafka", Version: "1.1.0"}, Digest: "5678"},
+	} {
+		if err := directory.AddPackageVersion(pv); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := index.Update(directory, false); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(index.Entries["kafka"]), 2)
+	assert.Equal(t, index.Entries["kafka"][0].Version, "1.1.0")
+	existing, _ := index.GetByNameAndVersion("kafka", "1.0.0")
+	assert.Equal(t, existing.Removed && existing.Deprecated, true, "flags of existing entries are preserved")
+
+	directory.Entries["kafka"][0].Digest = "changed"
+	err := index.Update(directory, false)
+	assert.Equal(t, err.Error(), "operator 'kafka' version: 1.0.0 changed since it was indexed, publish a new version instead")
+
+	if err := index.Update(directory, true); err != nil {
+		t.Fatal(err)
+	}
+	existing, _ = index.GetByNameAndVersion("kafka", "1.0.0")
+	assert.Equal(t, existing.Digest, "changed")
+	assert.Equal(t, existing.Removed, true)
+}
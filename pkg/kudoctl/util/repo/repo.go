@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/kudobuilder/kudo/pkg/apis/kudo/v1alpha1"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/http"
 	"github.com/kudobuilder/kudo/pkg/kudoctl/kudohome"
 
 	"github.com/spf13/afero"
@@ -26,6 +27,42 @@ const (
 type Configuration struct {
 	URL  string `json:"url"`
 	Name string `json:"name"`
+	// Priority decides which repository an operator is installed from if several repositories provide it.
+	// Repositories with a higher priority win.
+	Priority int `json:"priority,omitempty"`
+	// TrustedKeys are PEM encoded ed25519 public keys. If any are set, packages of the repository need a
+	// detached signature made by one of them.
+	TrustedKeys []string `json:"trustedKeys,omitempty"`
+
+	// Username and Password are used for basic auth with the repository
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// BearerToken is used for token auth with the repository if no username is set
+	BearerToken string `json:"bearerToken,omitempty"`
+	// CAFile is the path of a PEM encoded CA bundle to verify the certificate of the repository
+	CAFile string `json:"caFile,omitempty"`
+	// CertFile and KeyFile are the paths of a PEM encoded client certificate and key
+	CertFile string `json:"certFile,omitempty"`
+	KeyFile  string `json:"keyFile,omitempty"`
+	// InsecureSkipTLSVerify disables verification of the certificate of the repository
+	InsecureSkipTLSVerify bool `json:"insecureSkipTLSVerify,omitempty"`
+}
+
+// HasCredentials returns true if the configuration contains a password or token
+func (c *Configuration) HasCredentials() bool {
+	return c.Password != "" || c.BearerToken != ""
+}
+
+func (c *Configuration) httpOptions() http.Options {
+	return http.Options{
+		Username:              c.Username,
+		Password:              c.Password,
+		BearerToken:           c.BearerToken,
+		CAFile:                c.CAFile,
+		CertFile:              c.CertFile,
+		KeyFile:               c.KeyFile,
+		InsecureSkipTLSVerify: c.InsecureSkipTLSVerify,
+	}
 }
 
 // Repositories represents the repositories.yaml file usually in the $KUDO_HOME
@@ -33,6 +70,8 @@ type Repositories struct {
 	RepoVersion  string           `json:"repoVersion"`
 	Context      string           `json:"context"`
 	Repositories []*Configuration `json:"repositories"`
+	// Pins maps operator names to the name of the repository they are always installed from
+	Pins map[string]string `json:"pins,omitempty"`
 }
 
 // Default initialized repository.
@@ -117,7 +156,7 @@ func (r *Repositories) Add(repo ...*Configuration) {
 	r.Repositories = append(r.Repositories, repo...)
 }
 
-// Remove removes the repo config with the provided name
+// Remove removes the repo config with the provided name and the operators pinned to it
 func (r *Repositories) Remove(name string) bool {
 	repos := []*Configuration{}
 	found := false
@@ -129,9 +168,35 @@ func (r *Repositories) Remove(name string) bool {
 		repos = append(repos, repo)
 	}
 	r.Repositories = repos
+	for operator, repoName := range r.Pins {
+		if repoName == name {
+			delete(r.Pins, operator)
+		}
+	}
 	return found
 }
 
+// Pin makes an operator always resolve to the repository with the given name.  errors if no repo found.
+func (r *Repositories) Pin(operator string, repoName string) error {
+	if r.GetConfiguration(repoName) == nil {
+		return fmt.Errorf("no config found with name: %s", repoName)
+	}
+	if r.Pins == nil {
+		r.Pins = map[string]string{}
+	}
+	r.Pins[operator] = repoName
+	return nil
+}
+
+// Unpin removes the pin of an operator. Returns false if the operator was not pinned.
+func (r *Repositories) Unpin(operator string) bool {
+	if _, ok := r.Pins[operator]; !ok {
+		return false
+	}
+	delete(r.Pins, operator)
+	return true
+}
+
 // SetContext switches the context to another repo config in the repositories file.  errors if no repo found.
 func (r *Repositories) SetContext(context string) error {
 	config := r.GetConfiguration(context)
@@ -1,6 +1,7 @@
 package repo
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/kudobuilder/kudo/pkg/apis/kudo/v1alpha1"
 	"github.com/kudobuilder/kudo/pkg/kudoctl/bundle"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/files"
 	"github.com/kudobuilder/kudo/pkg/kudoctl/http"
 	"github.com/kudobuilder/kudo/pkg/kudoctl/kudohome"
 
@@ -25,6 +27,10 @@ type Repository interface {
 type Client struct {
 	Config *Configuration
 	Client http.Client
+	// Cache stores downloaded index files and packages. Nothing is cached if it is nil.
+	Cache *Cache
+	// Offline makes the client use index files and packages from the cache only.
+	Offline bool
 }
 
 // ClientFromSettings retrieves the operator repo for the configured repo in settings
@@ -34,7 +40,12 @@ func ClientFromSettings(fs afero.Fs, home kudohome.Home, repoName string) (*Clie
 		return nil, err
 	}
 
-	return NewClient(rc)
+	client, err := NewClient(rc)
+	if err != nil {
+		return nil, err
+	}
+	client.Cache = NewCache(fs, home)
+	return client, nil
 }
 
 // NewClient constructs repository client
@@ -44,7 +55,10 @@ func NewClient(conf *Configuration) (*Client, error) {
 		return nil, fmt.Errorf("invalid repository URL: %s", conf.URL)
 	}
 
-	client := http.NewClient()
+	client, err := http.NewClientWithOptions(conf.httpOptions())
+	if err != nil {
+		return nil, fmt.Errorf("repository %s: %v", conf.Name, err)
+	}
 
 	return &Client{
 		Config: conf,
@@ -53,6 +67,8 @@ func NewClient(conf *Configuration) (*Client, error) {
 }
 
 // DownloadIndexFile fetches the index file from a repository.
+// With a cache, a cached index file is revalidated with the repository and used if it is unchanged or if the
+// repository cannot be reached. In offline mode only the cached index file is used.
 func (r *Client) DownloadIndexFile() (*IndexFile, error) {
 	var indexURL string
 	parsedURL, err := url.Parse(r.Config.URL)
@@ -63,28 +79,67 @@ func (r *Client) DownloadIndexFile() (*IndexFile, error) {
 
 	indexURL = parsedURL.String()
 
-	resp, err := r.Client.Get(indexURL)
+	if r.Cache == nil {
+		resp, err := r.Client.Get(indexURL)
+		if err != nil {
+			return nil, errors.Wrap(err, "getting index url")
+		}
+
+		indexBytes, err := ioutil.ReadAll(resp)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading index response")
+		}
+
+		return ParseIndexFile(indexBytes)
+	}
+
+	cached, validators, err := r.Cache.Index(r.Config.Name)
 	if err != nil {
-		return nil, errors.Wrap(err, "getting index url")
+		return nil, errors.Wrap(err, "reading cached index file")
+	}
+	if r.Offline {
+		if cached == nil {
+			return nil, fmt.Errorf("no cached index file for repository %s, run 'kudo repo update' while online", r.Config.Name)
+		}
+		return ParseIndexFile(cached)
 	}
 
-	indexBytes, err := ioutil.ReadAll(resp)
+	resp, validators, err := r.Client.GetIfChanged(indexURL, validators)
 	if err != nil {
-		return nil, errors.Wrap(err, "reading index response")
+		if cached != nil {
+			fmt.Printf("Warning: using cached index file of repository %s: %v\n", r.Config.Name, err)
+			return ParseIndexFile(cached)
+		}
+		return nil, errors.Wrap(err, "getting index url")
+	}
+	if resp == nil {
+		// index file not modified since it was cached
+		return ParseIndexFile(cached)
 	}
 
-	indexFile, err := ParseIndexFile(indexBytes)
-	return indexFile, err
+	indexFile, err := ParseIndexFile(resp.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	if err := r.Cache.StoreIndex(r.Config.Name, resp.Bytes(), validators); err != nil {
+		fmt.Printf("Warning: unable to cache index file of repository %s: %v\n", r.Config.Name, err)
+	}
+	return indexFile, nil
 }
 
 // getPackageReaderByFullPackageName downloads the tgz file from the remote repository and unmarshals it to the package CRDs
 func (r *Client) getPackageReaderByFullPackageName(fullPackageName string) (io.Reader, error) {
+	return r.getFileReader(fullPackageName + ".tgz")
+}
+
+// getFileReader downloads a file relative to the repository URL
+func (r *Client) getFileReader(fileName string) (io.Reader, error) {
 	var fileURL string
 	parsedURL, err := url.Parse(r.Config.URL)
 	if err != nil {
 		return nil, errors.Wrap(err, "parsing config url")
 	}
-	parsedURL.Path = fmt.Sprintf("%s/%s.tgz", parsedURL.Path, fullPackageName)
+	parsedURL.Path = fmt.Sprintf("%s/%s", parsedURL.Path, fileName)
 
 	fileURL = parsedURL.String()
 	return r.getPackageReaderByURL(fileURL)
@@ -111,10 +166,123 @@ func (r *Client) GetPackageReader(name string, version string) (io.Reader, error
 	if err != nil {
 		return nil, errors.Wrapf(err, "getting %s in index file", name)
 	}
+	if err := r.checkInstallable(bundleVersion); err != nil {
+		return nil, err
+	}
 
-	packageName := bundleVersion.Name + "-" + bundleVersion.Version
+	return r.getPackageReaderByPackageVersion(bundleVersion)
+}
 
-	return r.getPackageReaderByFullPackageName(packageName)
+// checkInstallable refuses package versions removed from the repository and warns about deprecated ones
+func (r *Client) checkInstallable(pv *PackageVersion) error {
+	if pv.Removed {
+		return fmt.Errorf("version %s of operator %s has been removed from repository %s", pv.Version, pv.Name, r.Config.Name)
+	}
+	if pv.Deprecated {
+		fmt.Printf("Warning: version %s of operator %s is deprecated\n", pv.Version, pv.Name)
+	}
+	return nil
+}
+
+// getPackageReaderByPackageVersion provides the package from the cache if a package with the digest of the package
+// version is cached, otherwise it is downloaded, verified and cached.
+// Every package needs to match the digest from the index file. If the repository has trusted keys, the package
+// also needs a detached signature made by one of them. Cached packages are verified as well, a cached package that
+// fails verification is dropped and downloaded again unless the client is offline.
+func (r *Client) getPackageReaderByPackageVersion(pv *PackageVersion) (io.Reader, error) {
+	packageName := pv.Name + "-" + pv.Version
+	if pv.Digest == "" {
+		return nil, fmt.Errorf("package %s has no digest in the index file of repository %s and can not be verified", packageName, r.Config.Name)
+	}
+
+	if r.Cache != nil {
+		content, signature, err := r.Cache.Package(r.Config.Name, pv.Digest)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading cached package %s", packageName)
+		}
+		if content != nil {
+			err := r.verifyPackage(packageName, pv.Digest, content, signature)
+			if err == nil {
+				return bytes.NewReader(content), nil
+			}
+			if r.Offline {
+				return nil, errors.Wrap(err, "verifying cached package")
+			}
+			fmt.Printf("Warning: dropping cached package %s: %v\n", packageName, err)
+			if err := r.Cache.RemovePackage(r.Config.Name, pv.Digest); err != nil {
+				fmt.Printf("Warning: unable to remove cached package %s: %v\n", packageName, err)
+			}
+		}
+	}
+	if r.Offline {
+		return nil, fmt.Errorf("package %s of repository %s is not cached", packageName, r.Config.Name)
+	}
+
+	reader, err := r.getPackageReaderByFullPackageName(packageName)
+	if err != nil {
+		return nil, err
+	}
+	content, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading package %s", packageName)
+	}
+	var signature []byte
+	if len(r.Config.TrustedKeys) > 0 {
+		if signature, err = r.getSignature(packageName); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.verifyPackage(packageName, pv.Digest, content, signature); err != nil {
+		return nil, err
+	}
+
+	if r.Cache != nil {
+		if err := r.Cache.StorePackage(r.Config.Name, pv.Digest, content, signature); err != nil {
+			fmt.Printf("Warning: unable to cache package %s: %v\n", packageName, err)
+		}
+	}
+	return bytes.NewReader(content), nil
+}
+
+// getSignature downloads the detached signature of a package
+func (r *Client) getSignature(packageName string) ([]byte, error) {
+	reader, err := r.getFileReader(packageName + ".tgz" + bundle.SignatureExtension)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting signature of package %s", packageName)
+	}
+	signature, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading signature of package %s", packageName)
+	}
+	return signature, nil
+}
+
+// verifyPackage checks the digest of the package content and, if the repository has trusted keys, its signature
+func (r *Client) verifyPackage(packageName string, digest string, content []byte, signature []byte) error {
+	actual, err := files.Sha256Sum(bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	if actual != digest {
+		return fmt.Errorf("package %s has digest %s but the index file of repository %s expects %s", packageName, actual, r.Config.Name, digest)
+	}
+
+	if len(r.Config.TrustedKeys) == 0 {
+		return nil
+	}
+	if signature == nil {
+		return fmt.Errorf("package %s has no signature", packageName)
+	}
+	return errors.Wrapf(bundle.VerifySignature(content, signature, r.Config.TrustedKeys), "verifying package %s", packageName)
+}
+
+// GetBundleForPackageVersion provides a Bundle for a package version taken from the repository index file
+func (r *Client) GetBundleForPackageVersion(pv *PackageVersion) (bundle.Bundle, error) {
+	reader, err := r.getPackageReaderByPackageVersion(pv)
+	if err != nil {
+		return nil, err
+	}
+	return bundle.NewBundleFromReader(reader), nil
 }
 
 // GetBundle provides an Bundle for a provided package name and optional version
@@ -0,0 +1,140 @@
+package repo
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kudobuilder/kudo/pkg/kudoctl/files"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/kudohome"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_GetPackageReaderVerification(t *testing.T) {
+	content := []byte("package content")
+	digest, _ := files.Sha256Sum(bytes.NewReader(content))
+
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicDER, _ := x509.MarshalPKIXPublicKey(public)
+	trustedKey := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+	otherPublic, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherDER, _ := x509.MarshalPKIXPublicKey(otherPublic)
+	otherKey := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: otherDER}))
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(private, content))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/zk-0.1.0.tgz":
+			w.Write(content)
+		case "/zk-0.1.0.tgz.sig":
+			w.Write([]byte(signature))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		digest      string
+		trustedKeys []string
+		err         string
+	}{
+		{"matching digest", digest, nil, ""},
+		{"missing digest", "", nil, "package zk-0.1.0 has no digest in the index file of repository test and can not be verified"},
+		{"wrong digest", "1234", nil, "package zk-0.1.0 has digest " + digest + " but the index file of repository test expects 1234"},
+		{"trusted signature", digest, []string{trustedKey}, ""},
+		{"untrusted signature", digest, []string{otherKey}, "verifying package zk-0.1.0: package signature does not match any trusted key"},
+	}
+
+	for _, tt := range tests {
+		client, err := NewClient(&Configuration{Name: "test", URL: server.URL, TrustedKeys: tt.trustedKeys})
+		if err != nil {
+			t.Fatal(err)
+		}
+		pv := &PackageVersion{Metadata: &Metadata{Name: "zk", Version: "0.1.0"}, Digest: tt.digest}
+
+		reader, err := client.getPackageReaderByPackageVersion(pv)
+		if tt.err != "" {
+			assert.EqualError(t, err, tt.err, tt.name)
+			continue
+		}
+		assert.NoError(t, err, tt.name)
+		actual, _ := ioutil.ReadAll(reader)
+		assert.Equal(t, content, actual, tt.name)
+	}
+}
+
+func TestClient_CachedPackageVerification(t *testing.T) {
+	content := []byte("package content")
+	digest, _ := files.Sha256Sum(bytes.NewReader(content))
+
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicDER, _ := x509.MarshalPKIXPublicKey(public)
+	trustedKey := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(private, content))
+
+	packageRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/zk-0.1.0.tgz":
+			packageRequests++
+			w.Write(content)
+		case "/zk-0.1.0.tgz.sig":
+			w.Write([]byte(signature))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	cache := NewCache(afero.NewMemMapFs(), kudohome.Home("/kudo"))
+	pv := &PackageVersion{Metadata: &Metadata{Name: "zk", Version: "0.1.0"}, Digest: digest}
+	client, _ := NewClient(&Configuration{Name: "test", URL: server.URL, TrustedKeys: []string{trustedKey}})
+	client.Cache = cache
+
+	// a package cached without a signature can not be verified offline
+	assert.NoError(t, cache.StorePackage("test", digest, content, nil))
+	client.Offline = true
+	_, err = client.getPackageReaderByPackageVersion(pv)
+	assert.EqualError(t, err, "verifying cached package: package zk-0.1.0 has no signature")
+
+	// online it is dropped and downloaded again together with its signature
+	client.Offline = false
+	_, err = client.getPackageReaderByPackageVersion(pv)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, packageRequests)
+	_, cachedSignature, _ := cache.Package("test", digest)
+	assert.Equal(t, signature, string(cachedSignature))
+
+	// the cached signature is verified offline
+	client.Offline = true
+	reader, err := client.getPackageReaderByPackageVersion(pv)
+	assert.NoError(t, err)
+	actual, _ := ioutil.ReadAll(reader)
+	assert.Equal(t, content, actual)
+	assert.Equal(t, 1, packageRequests)
+
+	// a cached signature by an untrusted key is rejected
+	assert.NoError(t, cache.StorePackage("test", digest, content, []byte(base64.StdEncoding.EncodeToString([]byte("forged")))))
+	_, err = client.getPackageReaderByPackageVersion(pv)
+	assert.Error(t, err)
+}
@@ -0,0 +1,153 @@
+package repo
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/kudobuilder/kudo/pkg/kudoctl/bundle"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/kudohome"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+// Resolver is a Repository which looks up operators in all configured repositories.
+// An operator pinned to a repository is only looked up there. Otherwise the repository with the highest priority
+// that provides the requested version wins. If several repositories with the same priority provide it, the
+// operator can not be resolved until it is pinned or a repository is chosen explicitly.
+type Resolver struct {
+	repos   *Repositories
+	fs      afero.Fs
+	home    kudohome.Home
+	offline bool
+}
+
+// candidate is a repository that provides a version of an operator
+type candidate struct {
+	client   *Client
+	pv       *PackageVersion
+	versions []string
+}
+
+// ResolverFromSettings creates a resolver for the repositories configured in the KUDO home.
+// In offline mode only cached index files and packages are used.
+func ResolverFromSettings(fs afero.Fs, home kudohome.Home, offline bool) (*Resolver, error) {
+	repos, err := LoadRepositories(fs, home.RepositoryFile())
+	if err != nil {
+		// this allows for no client init, the same as ConfigurationFromSettings
+		repos = NewRepositories()
+	}
+	return &Resolver{repos: repos, fs: fs, home: home, offline: offline}, nil
+}
+
+func (r *Resolver) client(config *Configuration) (*Client, error) {
+	client, err := NewClient(config)
+	if err != nil {
+		return nil, err
+	}
+	client.Cache = NewCache(r.fs, r.home)
+	client.Offline = r.offline
+	return client, nil
+}
+
+// GetBundle provides the Bundle for an operator name and optional version from the repository it resolves to
+func (r *Resolver) GetBundle(name string, version string) (bundle.Bundle, error) {
+	client, pv, err := r.Resolve(name, version)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.checkInstallable(pv); err != nil {
+		return nil, err
+	}
+	return client.GetBundleForPackageVersion(pv)
+}
+
+// Resolve returns the client of the repository the operator resolves to and the package version it provides
+func (r *Resolver) Resolve(name string, version string) (*Client, *PackageVersion, error) {
+	if repoName, ok := r.repos.Pins[name]; ok {
+		config := r.repos.GetConfiguration(repoName)
+		if config == nil {
+			return nil, nil, fmt.Errorf("operator %s is pinned to repository %s which does not exist", name, repoName)
+		}
+		client, err := r.client(config)
+		if err != nil {
+			return nil, nil, err
+		}
+		index, err := client.DownloadIndexFile()
+		if err != nil {
+			return nil, nil, errors.WithMessage(err, "could not download repository index file")
+		}
+		pv, err := index.GetByNameAndVersion(name, version)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "getting %s in index file of repository %s", name, repoName)
+		}
+		return client, pv, nil
+	}
+
+	candidates, err := r.candidates(name, version)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(candidates) == 0 {
+		if version != "" {
+			return nil, nil, fmt.Errorf("version %s of operator %s not found in any repository", version, name)
+		}
+		return nil, nil, fmt.Errorf("operator %s not found in any repository", name)
+	}
+
+	best := candidates[0]
+	if len(candidates) > 1 && candidates[1].client.Config.Priority == best.client.Config.Priority {
+		return nil, nil, ambiguousError(name, candidates)
+	}
+	return best.client, best.pv, nil
+}
+
+// candidates returns the repositories providing the version of the operator, ordered by priority.
+// A repository whose index file can not be downloaded might provide the operator, too. It fails the resolution unless a
+// repository with a higher priority provides the operator, in which case it is skipped with a warning.
+func (r *Resolver) candidates(name string, version string) ([]candidate, error) {
+	configs := make([]*Configuration, len(r.repos.Repositories))
+	copy(configs, r.repos.Repositories)
+	sort.SliceStable(configs, func(i, j int) bool {
+		return configs[i].Priority > configs[j].Priority
+	})
+
+	var candidates []candidate
+	for _, config := range configs {
+		client, err := r.client(config)
+		if err != nil {
+			return nil, err
+		}
+		index, err := client.DownloadIndexFile()
+		if err != nil {
+			// repositories are sorted by priority, the first candidate has the highest priority of all candidates
+			if len(candidates) == 0 || candidates[0].client.Config.Priority == config.Priority {
+				return nil, errors.Wrapf(err, "could not read repository %s which may provide operator %s, "+
+					"use --repo or pin it with 'kudo repo pin %s <repo>'", config.Name, name, name)
+			}
+			fmt.Fprintf(os.Stderr, "Warning: skipping repository %q: %v\n", config.Name, err)
+			continue
+		}
+		pv, err := index.GetByNameAndVersion(name, version)
+		if err != nil {
+			continue
+		}
+		c := candidate{client: client, pv: pv}
+		for _, v := range index.Entries[name] {
+			c.versions = append(c.versions, v.Version)
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, nil
+}
+
+func ambiguousError(name string, candidates []candidate) error {
+	lines := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		lines = append(lines, fmt.Sprintf("  %s (priority %d): %s", c.client.Config.Name, c.client.Config.Priority, strings.Join(c.versions, ", ")))
+	}
+	return fmt.Errorf("operator %s is provided by several repositories with the same priority, "+
+		"use --repo or pin it with 'kudo repo pin %s <repo>':\n%s", name, name, strings.Join(lines, "\n"))
+}
@@ -0,0 +1,127 @@
+package repo
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kudobuilder/kudo/pkg/kudoctl/kudohome"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestIndexServer serves an index file with the given versions of kafka, versions ending with ! are removed
+func newTestIndexServer(versions ...string) *httptest.Server {
+	index := "apiVersion: v1\nentries:\n  kafka:\n"
+	for _, v := range versions {
+		index += fmt.Sprintf("  - name: kafka\n    version: %s\n", strings.TrimSuffix(v, "!"))
+		if strings.HasSuffix(v, "!") {
+			index += "    removed: true\n"
+		}
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(index))
+	}))
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	community := newTestIndexServer("1.0.0", "0.9.0", "0.8.0!")
+	defer community.Close()
+	internal := newTestIndexServer("0.9.0")
+	defer internal.Close()
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unavailable.Close()
+
+	tests := []struct {
+		name     string
+		repos    []*Configuration
+		pins     map[string]string
+		version  string
+		expected string
+		err      string
+	}{
+		{
+			name:     "higher priority wins",
+			repos:    []*Configuration{{Name: "community", URL: community.URL}, {Name: "internal", URL: internal.URL, Priority: 10}},
+			expected: "internal-0.9.0",
+		},
+		{
+			name:     "only repository with the version",
+			repos:    []*Configuration{{Name: "community", URL: community.URL}, {Name: "internal", URL: internal.URL, Priority: 10}},
+			version:  "1.0.0",
+			expected: "community-1.0.0",
+		},
+		{
+			name:     "pinned operator",
+			repos:    []*Configuration{{Name: "community", URL: community.URL}, {Name: "internal", URL: internal.URL, Priority: 10}},
+			pins:     map[string]string{"kafka": "community"},
+			expected: "community-1.0.0",
+		},
+		{
+			name:  "same priority",
+			repos: []*Configuration{{Name: "community", URL: community.URL}, {Name: "internal", URL: internal.URL}},
+			err: "operator kafka is provided by several repositories with the same priority, use --repo or pin it with 'kudo repo pin kafka <repo>':\n" +
+				"  community (priority 0): 1.0.0, 0.9.0, 0.8.0\n" +
+				"  internal (priority 0): 0.9.0",
+		},
+		{
+			name:  "unavailable repository with higher priority",
+			repos: []*Configuration{{Name: "community", URL: community.URL}, {Name: "internal", URL: unavailable.URL, Priority: 10}},
+			err: "could not read repository internal which may provide operator kafka, use --repo or pin it with 'kudo repo pin kafka <repo>': " +
+				"getting index url: failed to fetch " + unavailable.URL + "/index.yaml : 503 Service Unavailable",
+		},
+		{
+			name:  "unavailable repository with same priority",
+			repos: []*Configuration{{Name: "community", URL: community.URL}, {Name: "internal", URL: unavailable.URL}},
+			err: "could not read repository internal which may provide operator kafka, use --repo or pin it with 'kudo repo pin kafka <repo>': " +
+				"getting index url: failed to fetch " + unavailable.URL + "/index.yaml : 503 Service Unavailable",
+		},
+		{
+			name:     "unavailable repository with lower priority",
+			repos:    []*Configuration{{Name: "community", URL: unavailable.URL}, {Name: "internal", URL: internal.URL, Priority: 10}},
+			expected: "internal-0.9.0",
+		},
+		{
+			name:     "unavailable repository of pinned operator is the only one read",
+			repos:    []*Configuration{{Name: "community", URL: community.URL}, {Name: "internal", URL: unavailable.URL, Priority: 10}},
+			pins:     map[string]string{"kafka": "community"},
+			expected: "community-1.0.0",
+		},
+		{
+			name:    "removed version",
+			repos:   []*Configuration{{Name: "community", URL: community.URL}},
+			version: "0.8.0",
+			err:     "version 0.8.0 of operator kafka has been removed from repository community",
+		},
+		{
+			name:    "unknown version",
+			repos:   []*Configuration{{Name: "community", URL: community.URL}},
+			version: "2.0.0",
+			err:     "version 2.0.0 of operator kafka not found in any repository",
+		},
+	}
+
+	for _, tt := range tests {
+		resolver := &Resolver{
+			repos: &Repositories{Repositories: tt.repos, Pins: tt.pins},
+			fs:    afero.NewMemMapFs(),
+			home:  kudohome.Home("/kudo"),
+		}
+		client, pv, err := resolver.Resolve("kafka", tt.version)
+		if err == nil {
+			err = client.checkInstallable(pv)
+		}
+		if tt.err != "" {
+			assert.EqualError(t, err, tt.err, tt.name)
+			continue
+		}
+		if assert.NoError(t, err, tt.name) {
+			assert.Equal(t, tt.expected, client.Config.Name+"-"+pv.Version, tt.name)
+		}
+	}
+}
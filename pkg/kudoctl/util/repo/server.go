@@ -0,0 +1,164 @@
+package repo
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kudobuilder/kudo/pkg/kudoctl/bundle"
+
+	"github.com/spf13/afero"
+)
+
+// indexFileName is the name of the index file of a repository
+const indexFileName = "index.yaml"
+
+// DirectoryServer serves a directory of operator packages as a repository. The index file is generated from the
+// packages with IndexDirectory and generated again whenever the packages in the directory change. An index file in the
+// directory is updated with the packages instead, so that versions removed or deprecated in it stay that way.
+type DirectoryServer struct {
+	fs  afero.Fs
+	dir string
+	// url of the packages in the index file, if empty the URL the server was reached with is used
+	url string
+	out io.Writer
+
+	mu          sync.Mutex
+	fingerprint string
+	indexURL    string
+	index       []byte
+}
+
+// NewDirectoryServer creates a server for the packages in dir. Re-indexing is reported to out.
+func NewDirectoryServer(fs afero.Fs, dir string, url string, out io.Writer) *DirectoryServer {
+	return &DirectoryServer{fs: fs, dir: dir, url: url, out: out}
+}
+
+func (s *DirectoryServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "HEAD" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+	switch {
+	case name == indexFileName:
+		url := s.url
+		if url == "" {
+			url = fmt.Sprintf("http://%s/", r.Host)
+		}
+		index, err := s.getIndex(url)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-yaml")
+		w.Write(index)
+
+	case !strings.Contains(name, "/") && (strings.HasSuffix(name, ".tgz") || strings.HasSuffix(name, ".tgz"+bundle.SignatureExtension)):
+		f, err := s.fs.Open(filepath.Join(s.dir, name))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		defer f.Close()
+		fi, err := f.Stat()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		http.ServeContent(w, r, name, fi.ModTime(), f)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// getIndex returns the index file for the packages in the directory, indexing them again if they changed
+func (s *DirectoryServer) getIndex(url string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fingerprint, count, err := s.packagesFingerprint()
+	if err != nil {
+		return nil, err
+	}
+	if s.index != nil && fingerprint == s.fingerprint && url == s.indexURL {
+		return s.index, nil
+	}
+
+	now := time.Now()
+	index := newIndexFile(&now)
+	if count > 0 {
+		index, err = IndexDirectory(s.fs, s.dir, url, &now)
+		if err != nil {
+			return nil, err
+		}
+	}
+	index, err = s.updateIndexFile(index)
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	if err := index.Write(buf); err != nil {
+		return nil, err
+	}
+
+	s.fingerprint, s.indexURL, s.index = fingerprint, url, buf.Bytes()
+	fmt.Fprintf(s.out, "Indexed %d packages in %s\n", count, s.dir)
+	return s.index, nil
+}
+
+// updateIndexFile updates the index file in the directory, if there is one, with the indexed packages. The packages are
+// downloaded from this server, their URLs are the ones of the indexed packages.
+func (s *DirectoryServer) updateIndexFile(index *IndexFile) (*IndexFile, error) {
+	path := filepath.Join(s.dir, indexFileName)
+	if exists, err := afero.Exists(s.fs, path); err != nil || !exists {
+		return index, err
+	}
+	b, err := afero.ReadFile(s.fs, path)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := ParseIndexFile(b)
+	if err != nil {
+		return nil, fmt.Errorf("parsing index %v: %v", path, err)
+	}
+	if err := existing.Update(index, true); err != nil {
+		return nil, fmt.Errorf("updating index %v: %v", path, err)
+	}
+	for _, pvs := range index.Entries {
+		for _, pv := range pvs {
+			existing.find(pv.Name, pv.Version).URLs = pv.URLs
+		}
+	}
+	existing.Generated = index.Generated
+	return existing, nil
+}
+
+// packagesFingerprint identifies the current state of the packages and the index file in the directory by their names,
+// sizes and modification times. Returns the number of packages as well.
+func (s *DirectoryServer) packagesFingerprint() (string, int, error) {
+	archives, err := afero.Glob(s.fs, filepath.Join(s.dir, "*.tgz"))
+	if err != nil {
+		return "", 0, err
+	}
+	var b strings.Builder
+	if fi, err := s.fs.Stat(filepath.Join(s.dir, indexFileName)); err == nil {
+		fmt.Fprintf(&b, "%s:%d:%d\n", indexFileName, fi.Size(), fi.ModTime().UnixNano())
+	}
+	for _, a := range archives {
+		fi, err := s.fs.Stat(a)
+		if err != nil {
+			return "", 0, err
+		}
+		fmt.Fprintf(&b, "%s:%d:%d\n", a, fi.Size(), fi.ModTime().UnixNano())
+	}
+	return b.String(), len(archives), nil
+}
@@ -0,0 +1,103 @@
+package repo
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kudobuilder/kudo/pkg/kudoctl/files"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirectoryServer(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := fs.MkdirAll("/opt/repo", 0755); err != nil {
+		t.Fatal(err)
+	}
+	out := &bytes.Buffer{}
+	server := httptest.NewServer(NewDirectoryServer(fs, "/opt/repo", "", out))
+	defer server.Close()
+
+	client, err := NewClient(&Configuration{Name: "local", URL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// an empty directory is an empty repository
+	index, err := client.DownloadIndexFile()
+	assert.NoError(t, err)
+	assert.Empty(t, index.Entries)
+
+	// packages added to the directory are indexed on the next request
+	files.CopyOperatorToFs(fs, "../../bundle/testdata/zk.tgz", "/opt")
+	if err := fs.Rename("/opt/zk.tgz", "/opt/repo/zookeeper-0.1.0.tgz"); err != nil {
+		t.Fatal(err)
+	}
+	index, err = client.DownloadIndexFile()
+	assert.NoError(t, err)
+	pv, err := index.GetByNameAndVersion("zookeeper", "")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{server.URL + "/zookeeper-0.1.0.tgz"}, pv.URLs)
+	}
+
+	// unchanged packages are not indexed again
+	_, err = client.DownloadIndexFile()
+	assert.NoError(t, err)
+	assert.Equal(t, "Indexed 0 packages in /opt/repo\nIndexed 1 packages in /opt/repo\n", out.String())
+
+	// packages are served and can be installed
+	b, err := client.GetBundle("zookeeper", "")
+	if assert.NoError(t, err) {
+		crds, err := b.GetCRDs()
+		assert.NoError(t, err)
+		assert.Equal(t, "zookeeper", crds.Operator.Name)
+	}
+
+	resp, err := http.Get(server.URL + "/../etc/passwd")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestDirectoryServerUpdatesIndexFile(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	files.CopyOperatorToFs(fs, "../../bundle/testdata/zk.tgz", "/opt")
+	if err := fs.MkdirAll("/opt/repo", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Rename("/opt/zk.tgz", "/opt/repo/zookeeper-0.1.0.tgz"); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(NewDirectoryServer(fs, "/opt/repo", "", &bytes.Buffer{}))
+	defer server.Close()
+
+	client, err := NewClient(&Configuration{Name: "local", URL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	index, err := client.DownloadIndexFile()
+	assert.NoError(t, err)
+	assert.False(t, index.Entries["zookeeper"][0].Removed)
+
+	// versions removed with 'kudo repo yank' stay removed, the packages are still served by this server
+	yanked := `apiVersion: v1
+entries:
+  zookeeper:
+  - name: zookeeper
+    version: 0.1.0
+    removed: true
+    urls:
+    - https://operators.example.com/zookeeper-0.1.0.tgz
+`
+	if err := afero.WriteFile(fs, "/opt/repo/index.yaml", []byte(yanked), 0644); err != nil {
+		t.Fatal(err)
+	}
+	index, err = client.DownloadIndexFile()
+	assert.NoError(t, err)
+	pv := index.Entries["zookeeper"][0]
+	assert.True(t, pv.Removed)
+	assert.Equal(t, []string{server.URL + "/zookeeper-0.1.0.tgz"}, pv.URLs)
+	assert.NotEmpty(t, pv.Digest)
+}
@@ -31,6 +31,9 @@ type Case struct {
 	SkipDelete bool
 	Timeout    int
 
+	// Packages are the objects of operator packages to install into the namespace of the test.
+	Packages []runtime.Object
+
 	Client          func(forceNew bool) (client.Client, error)
 	DiscoveryClient func() (discovery.DiscoveryInterface, error)
 
@@ -75,6 +78,24 @@ func (t *Case) CreateNamespace(namespace string) error {
 	})
 }
 
+// InstallPackages installs the operators and operator versions of the packages into the namespace of the test.
+func (t *Case) InstallPackages(namespace string) error {
+	cl, err := t.Client(false)
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range t.Packages {
+		obj = testutils.WithNamespace(obj, namespace)
+		t.Logger.Log("Installing", testutils.ResourceID(obj))
+		if _, err := testutils.CreateOrUpdate(context.TODO(), cl, obj, true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // byFirstTimestamp sorts a slice of events by first timestamp, using their involvedObject's name as a tie breaker.
 type byFirstTimestamp []eventsbeta1.Event
 
@@ -132,6 +153,10 @@ func (t *Case) Run(test *testing.T) {
 		defer t.DeleteNamespace(ns)
 	}
 
+	if err := t.InstallPackages(ns); err != nil {
+		test.Fatal(err)
+	}
+
 	for _, testStep := range t.Steps {
 		testStep.Client = t.Client
 		testStep.DiscoveryClient = t.DiscoveryClient
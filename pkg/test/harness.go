@@ -15,8 +15,11 @@ import (
 	docker "github.com/docker/docker/client"
 	kudo "github.com/kudobuilder/kudo/pkg/apis/kudo/v1alpha1"
 	"github.com/kudobuilder/kudo/pkg/controller"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/bundle"
 	testutils "github.com/kudobuilder/kudo/pkg/test/utils"
 	"github.com/kudobuilder/kudo/pkg/webhook"
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -45,6 +48,8 @@ type Harness struct {
 	kind          *kind.Context
 	clientLock    sync.Mutex
 	configLock    sync.Mutex
+	// tempDirs are the temporary directories the tests of package tarballs are extracted to
+	tempDirs []string
 }
 
 // LoadTests loads all of the tests in a given directory.
@@ -310,12 +315,81 @@ func (h *Harness) DockerClient() (testutils.DockerClient, error) {
 	return h.docker, err
 }
 
+// LoadPackages loads the objects to install for the operator packages of the test suite and returns them with the
+// directories of the tests bundled with the packages. The tests of package tarballs are extracted to temporary
+// directories which are removed when the harness stops.
+func (h *Harness) LoadPackages() ([]runtime.Object, []string, error) {
+	fs := afero.NewOsFs()
+	objects := []runtime.Object{}
+	testDirs := []string{}
+
+	for _, path := range h.TestSuite.Packages {
+		b, err := bundle.NewBundle(fs, path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("loading package %s: %v", path, err)
+		}
+		crds, err := b.GetCRDs()
+		if err != nil {
+			return nil, nil, fmt.Errorf("loading package %s: %v", path, err)
+		}
+		objects = append(objects, crds.Operator, crds.OperatorVersion)
+
+		testDir, err := h.packageTestDir(fs, path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("loading tests of package %s: %v", path, err)
+		}
+		if testDir != "" {
+			testDirs = append(testDirs, testDir)
+		}
+	}
+
+	return objects, testDirs, nil
+}
+
+// packageTestDir returns the directory of the tests bundled with the package at path or an empty string if the
+// package has no tests. A package tarball is extracted to a temporary directory first.
+func (h *Harness) packageTestDir(fs afero.Fs, path string) (string, error) {
+	fi, err := fs.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	dir := path
+	if !fi.IsDir() {
+		f, err := fs.Open(path)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+
+		dir, err = ioutil.TempDir("", "kudo-test-package")
+		if err != nil {
+			return "", err
+		}
+		h.tempDirs = append(h.tempDirs, dir)
+		if err := bundle.Untar(fs, dir, f); err != nil {
+			return "", err
+		}
+	}
+
+	testDir := filepath.Join(dir, bundle.TestsDirName)
+	if exists, _ := afero.DirExists(fs, testDir); !exists {
+		return "", nil
+	}
+	return testDir, nil
+}
+
 // RunTests should be called from within a Go test (t) and launches all of the KUDO integration
 // tests at dir.
 func (h *Harness) RunTests() {
 	tests := []*Case{}
 
-	for _, testDir := range h.TestSuite.TestDirs {
+	packages, packageTestDirs, err := h.LoadPackages()
+	if err != nil {
+		h.T.Fatal(err)
+	}
+
+	for _, testDir := range append(h.TestSuite.TestDirs, packageTestDirs...) {
 		tempTests, err := h.LoadTests(testDir)
 		if err != nil {
 			h.T.Fatal(err)
@@ -325,6 +399,7 @@ func (h *Harness) RunTests() {
 
 	h.T.Run("harness", func(t *testing.T) {
 		for _, test := range tests {
+			test.Packages = packages
 			test.Client = h.Client
 			test.DiscoveryClient = h.DiscoveryClient
 
@@ -401,6 +476,11 @@ func (h *Harness) Run() {
 
 // Stop the test environment and KUDO, clean up the harness.
 func (h *Harness) Stop() {
+	for _, dir := range h.tempDirs {
+		os.RemoveAll(dir)
+	}
+	h.tempDirs = nil
+
 	if h.managerStopCh != nil {
 		close(h.managerStopCh)
 		h.managerStopCh = nil
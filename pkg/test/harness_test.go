@@ -3,13 +3,21 @@ package test
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	kindConfig "sigs.k8s.io/kind/pkg/apis/config/v1alpha3"
 
 	dockertypes "github.com/docker/docker/api/types"
 	volumetypes "github.com/docker/docker/api/types/volume"
+	kudo "github.com/kudobuilder/kudo/pkg/apis/kudo/v1alpha1"
+	"github.com/kudobuilder/kudo/pkg/kudoctl/bundle"
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 func TestGetTimeout(t *testing.T) {
@@ -62,3 +70,72 @@ func TestAddNodeCaches(t *testing.T) {
 	assert.Equal(t, "/var/lib/docker/data/kind-0", kindCfg.Nodes[0].ExtraMounts[0].HostPath)
 	assert.Equal(t, "/var/lib/docker/data/kind-1", kindCfg.Nodes[1].ExtraMounts[0].HostPath)
 }
+
+func TestLoadPackages(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kudo-test-packages")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path, err := bundle.NewSkeleton(afero.NewOsFs(), dir, "my-operator")
+	assert.NoError(t, err)
+
+	h := Harness{T: t}
+	h.TestSuite.Packages = []string{path}
+
+	objects, testDirs, err := h.LoadPackages()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(path, "tests")}, testDirs)
+	if assert.Len(t, objects, 2) {
+		assert.Equal(t, "my-operator", objects[0].(*kudo.Operator).Name)
+		assert.Equal(t, "my-operator-0.1.0", objects[1].(*kudo.OperatorVersion).Name)
+	}
+
+	tests, err := h.LoadTests(testDirs[0])
+	assert.NoError(t, err)
+	if assert.Len(t, tests, 1) {
+		assert.Equal(t, "deploy", tests[0].Name)
+	}
+
+	h.TestSuite.Packages = []string{filepath.Join(dir, "missing")}
+	_, _, err = h.LoadPackages()
+	assert.Error(t, err)
+}
+
+func TestLoadPackagesFromTarball(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kudo-test-packages")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fs := afero.NewOsFs()
+	path, err := bundle.NewSkeleton(fs, dir, "my-operator")
+	assert.NoError(t, err)
+	tarball, err := bundle.ToTarBundle(fs, path, dir, false)
+	assert.NoError(t, err)
+
+	h := Harness{T: t}
+	h.TestSuite.Packages = []string{tarball}
+
+	objects, testDirs, err := h.LoadPackages()
+	assert.NoError(t, err)
+	assert.Len(t, objects, 2)
+	if !assert.Len(t, testDirs, 1) {
+		return
+	}
+
+	tests, err := h.LoadTests(testDirs[0])
+	assert.NoError(t, err)
+	if assert.Len(t, tests, 1) {
+		assert.Equal(t, "deploy", tests[0].Name)
+		assert.NoError(t, tests[0].LoadTestSteps())
+		if assert.Len(t, tests[0].Steps, 1) {
+			instance, err := runtime.DefaultUnstructuredConverter.ToUnstructured(tests[0].Steps[0].Apply[0])
+			assert.NoError(t, err)
+			operatorVersion, _, _ := unstructured.NestedString(instance, "spec", "operatorVersion", "name")
+			assert.Equal(t, "my-operator-0.1.0", operatorVersion)
+		}
+	}
+
+	h.Stop()
+	exists, _ := afero.DirExists(fs, filepath.Dir(testDirs[0]))
+	assert.False(t, exists, "the extracted tests are removed when the harness stops")
+}
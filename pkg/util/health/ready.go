@@ -3,17 +3,34 @@ package health
 import (
 	"context"
 	"fmt"
-	"log"
+	"reflect"
 
 	kudov1alpha1 "github.com/kudobuilder/kudo/pkg/apis/kudo/v1alpha1"
+	"github.com/kudobuilder/kudo/pkg/util/metrics"
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+var log = logf.Log.WithName("health")
+
 // IsHealthy returns whether an object is healthy. Must be implemented for each type.
 func IsHealthy(c client.Client, obj runtime.Object) error {
+	err := isHealthy(c, obj)
+	if err != nil {
+		// unstructured objects of custom resources only tell their kind by the GVK
+		kind := obj.GetObjectKind().GroupVersionKind().Kind
+		if kind == "" {
+			kind = reflect.Indirect(reflect.ValueOf(obj)).Type().Name()
+		}
+		metrics.HealthCheckFailures.WithLabelValues(kind).Inc()
+	}
+	return err
+}
+
+func isHealthy(c client.Client, obj runtime.Object) error {
 
 	switch obj := obj.(type) {
 	case *appsv1.StatefulSet:
@@ -21,23 +38,23 @@ func IsHealthy(c client.Client, obj runtime.Object) error {
 			return fmt.Errorf("replicas not set, so can't be healthy")
 		}
 		if obj.Status.ReadyReplicas == *obj.Spec.Replicas {
-			log.Printf("Statefulset %v is marked healthy\n", obj.Name)
+			log.V(1).Info("StatefulSet is healthy", "statefulset", obj.Name)
 			return nil
 		}
-		log.Printf("HealthUtil: Statefulset %v is NOT healthy. Not enough ready replicas: %v/%v", obj.Name, obj.Status.ReadyReplicas, obj.Status.Replicas)
+		log.Info("StatefulSet is not healthy, not enough ready replicas", "statefulset", obj.Name, "readyReplicas", obj.Status.ReadyReplicas, "replicas", obj.Status.Replicas)
 		return fmt.Errorf("ready replicas (%v) does not equal requested replicas (%v)", obj.Status.ReadyReplicas, obj.Status.Replicas)
 	case *appsv1.Deployment:
 		if obj.Spec.Replicas != nil && obj.Status.ReadyReplicas == *obj.Spec.Replicas {
-			log.Printf("HealthUtil: Deployment %v is marked healthy", obj.Name)
+			log.V(1).Info("Deployment is healthy", "deployment", obj.Name)
 			return nil
 		}
-		log.Printf("HealthUtil: Deployment %v is NOT healthy. Not enough ready replicas: %v/%v", obj.Name, obj.Status.ReadyReplicas, *obj.Spec.Replicas)
+		log.Info("Deployment is not healthy, not enough ready replicas", "deployment", obj.Name, "readyReplicas", obj.Status.ReadyReplicas, "replicas", obj.Spec.Replicas)
 		return fmt.Errorf("ready replicas (%v) does not equal requested replicas (%v)", obj.Status.ReadyReplicas, *obj.Spec.Replicas)
 	case *batchv1.Job:
 
 		if obj.Status.Succeeded == int32(1) {
 			// Done!
-			log.Printf("HealthUtil: Job \"%v\" is marked healthy", obj.Name)
+			log.V(1).Info("Job is healthy", "job", obj.Name)
 			return nil
 		}
 		return fmt.Errorf("job \"%v\" still running or failed", obj.Name)
@@ -52,10 +69,10 @@ func IsHealthy(c client.Client, obj runtime.Object) error {
 			Namespace: obj.Status.ActivePlan.Namespace,
 		}, plan)
 		if err != nil {
-			log.Printf("Error getting PlaneExecution %v/%v: %v\n", obj.Status.ActivePlan.Name, obj.Status.ActivePlan.Namespace, err)
+			log.Error(err, "Error getting active plan of instance", "instance", obj.Name, "planExecution", obj.Status.ActivePlan.Namespace+"/"+obj.Status.ActivePlan.Name)
 			return fmt.Errorf("instance active plan not found: %v", err)
 		}
-		log.Printf("HealthUtil: Instance %v is in state %v", obj.Name, plan.Status.State)
+		log.V(1).Info("Instance health", "instance", obj.Name, "state", plan.Status.State)
 
 		if plan.Status.State == kudov1alpha1.PhaseStateComplete {
 			return nil
@@ -64,7 +81,7 @@ func IsHealthy(c client.Client, obj runtime.Object) error {
 
 	// unless we build logic for what a healthy object is, assume it's healthy when created.
 	default:
-		log.Printf("HealthUtil: Unknown type is marked healthy by default")
+		log.V(1).Info("Unknown type is healthy by default", "type", fmt.Sprintf("%T", obj))
 		return nil
 	}
 }
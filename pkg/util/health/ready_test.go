@@ -0,0 +1,22 @@
+package health
+
+import (
+	"testing"
+
+	"github.com/kudobuilder/kudo/pkg/util/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsHealthyCountsFailuresByKind(t *testing.T) {
+	before := testutil.ToFloat64(metrics.HealthCheckFailures.WithLabelValues("Job"))
+
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "job", Namespace: "default"}}
+	assert.Error(t, IsHealthy(nil, job))
+	job.TypeMeta = metav1.TypeMeta{APIVersion: "batch/v1", Kind: "Job"}
+	assert.Error(t, IsHealthy(nil, job))
+
+	assert.Equal(t, before+2, testutil.ToFloat64(metrics.HealthCheckFailures.WithLabelValues("Job")))
+}
@@ -18,4 +18,17 @@ const (
 	PhaseAnnotation = "kudo.dev/phase"
 	// StepAnnotation is k8s annotation key for step that created this object
 	StepAnnotation = "kudo.dev/step"
+
+	// LastAppliedConfigAnnotation is k8s annotation key for the configuration KUDO applied to this object last
+	LastAppliedConfigAnnotation = "kudo.dev/last-applied-configuration"
+	// HashAnnotation is k8s annotation key for the hash of the rendered manifest KUDO applied to this object last
+	HashAnnotation = "kudo.dev/rendered-hash"
+	// DriftDetectionAnnotation is k8s annotation key to enable drift detection of an instance with the value "report"
+	// or "heal"
+	DriftDetectionAnnotation = "kudo.dev/drift-detection"
+	// PruneAnnotation is k8s annotation key to opt out of pruning an object with the value "false"
+	PruneAnnotation = "kudo.dev/prune"
+	// ServiceAccountAnnotation is k8s annotation key for the service account KUDO impersonates when applying the resources
+	// of an instance
+	ServiceAccountAnnotation = "kudo.dev/service-account"
 )
@@ -0,0 +1,36 @@
+package kudo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// watchedNamespaces are the namespaces the cache of the manager is restricted to, empty when it watches all namespaces
+var watchedNamespaces []string
+
+// SetWatchedNamespaces sets the namespaces the cache of the manager is restricted to, empty for all namespaces
+func SetWatchedNamespaces(namespaces []string) {
+	watchedNamespaces = namespaces
+}
+
+// IsNamespaceWatched returns whether the manager watches objects in the namespace
+func IsNamespaceWatched(namespace string) bool {
+	if len(watchedNamespaces) == 0 {
+		return true
+	}
+	for _, ns := range watchedNamespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckNamespaceWatched returns an error naming the object if it is in a namespace the manager does not watch, as the
+// manager can not read it from its cache
+func CheckNamespaceWatched(kind, namespace, name string) error {
+	if IsNamespaceWatched(namespace) {
+		return nil
+	}
+	return fmt.Errorf("%s %s/%s is in a namespace not watched by the KUDO manager, which watches the namespaces %s", kind, namespace, name, strings.Join(watchedNamespaces, ", "))
+}
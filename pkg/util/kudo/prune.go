@@ -0,0 +1,26 @@
+package kudo
+
+import "k8s.io/apimachinery/pkg/runtime/schema"
+
+// PrunedPlans are the plans after which objects of prior operator versions are pruned, the plans an upgrade falls back
+// to: upgrade, update and deploy
+var PrunedPlans = map[string]bool{"deploy": true, "update": true, "upgrade": true}
+
+// PruneKinds are the kinds which are checked for objects to prune in addition to the kinds rendered by the plan, unless
+// the plan is applied impersonating a service account.
+// Similar to the default whitelist of `kubectl apply --prune`, PersistentVolumeClaims are left out to never lose data.
+var PruneKinds = []schema.GroupVersionKind{
+	{Version: "v1", Kind: "ConfigMap"},
+	{Version: "v1", Kind: "Secret"},
+	{Version: "v1", Kind: "Service"},
+	{Version: "v1", Kind: "ServiceAccount"},
+	{Version: "v1", Kind: "Pod"},
+	{Group: "apps", Version: "v1", Kind: "Deployment"},
+	{Group: "apps", Version: "v1", Kind: "StatefulSet"},
+	{Group: "apps", Version: "v1", Kind: "DaemonSet"},
+	{Group: "batch", Version: "v1", Kind: "Job"},
+	{Group: "batch", Version: "v1beta1", Kind: "CronJob"},
+	{Group: "policy", Version: "v1beta1", Kind: "PodDisruptionBudget"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "Role"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "RoleBinding"},
+}
@@ -0,0 +1,147 @@
+// Package metrics contains the KUDO specific Prometheus metrics of the manager. They are registered on the registry of
+// controller-runtime and exposed on the metrics endpoint of the manager.
+package metrics
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	kudov1alpha1 "github.com/kudobuilder/kudo/pkg/apis/kudo/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var log = logf.Log.WithName("metrics")
+
+var (
+	// PlanExecutions counts the plan executions which completed or failed by operator, plan and result
+	PlanExecutions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kudo_plan_executions_total",
+		Help: "Number of plan executions which completed or failed, by operator, plan and result.",
+	}, []string{"operator", "plan", "result"})
+
+	// PlanDuration observes the time from the creation of a plan execution until it completed
+	PlanDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "kudo_plan_duration_seconds",
+		Help:    "Duration of completed plan executions in seconds, by operator and plan.",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 14),
+	}, []string{"operator", "plan"})
+
+	// PhaseDuration observes the time a phase was in progress until it completed
+	PhaseDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "kudo_phase_duration_seconds",
+		Help:    "Duration of completed phases in seconds, by operator, plan and phase.",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 14),
+	}, []string{"operator", "plan", "phase"})
+
+	// StepDuration observes the time a step was in progress until it completed
+	StepDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "kudo_step_duration_seconds",
+		Help:    "Duration of completed steps in seconds, by operator, plan, phase and step.",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 14),
+	}, []string{"operator", "plan", "phase", "step"})
+
+	// HealthCheckFailures counts the objects found not healthy by kind
+	HealthCheckFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kudo_health_check_failures_total",
+		Help: "Number of health checks of objects which were not healthy, by kind.",
+	}, []string{"kind"})
+
+	// RenderErrors counts the errors rendering the templates of an operator by stage, "template" or "kustomize"
+	RenderErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kudo_render_errors_total",
+		Help: "Number of errors rendering the templates of operators, by operator and stage.",
+	}, []string{"operator", "stage"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(PlanExecutions, PlanDuration, PhaseDuration, StepDuration, HealthCheckFailures, RenderErrors)
+}
+
+// Timers keeps the start times of running phases and steps in memory to observe their duration once they completed.
+// Durations of phases and steps which started before a restart of the manager are not observed.
+type Timers struct {
+	mu     sync.Mutex
+	starts map[string]time.Time
+}
+
+// NewTimers returns empty timers
+func NewTimers() *Timers {
+	return &Timers{starts: map[string]time.Time{}}
+}
+
+// Start starts the timer of key if it is not running yet
+func (t *Timers) Start(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.starts[key]; !ok {
+		t.starts[key] = time.Now()
+	}
+}
+
+// Stop stops the timer of key and observes its duration. Nothing is observed if the timer is not running.
+func (t *Timers) Stop(key string, observer prometheus.Observer) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if start, ok := t.starts[key]; ok {
+		observer.Observe(time.Since(start).Seconds())
+		delete(t.starts, key)
+	}
+}
+
+// Clear removes the running timers whose key starts with prefix without observing them, e.g. of a failed or deleted
+// plan execution
+func (t *Timers) Clear(prefix string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key := range t.starts {
+		if strings.HasPrefix(key, prefix) {
+			delete(t.starts, key)
+		}
+	}
+}
+
+// InstanceCollector collects the number of instances by status when the metrics are scraped
+type InstanceCollector struct {
+	client client.Client
+	desc   *prometheus.Desc
+}
+
+// NewInstanceCollector returns a collector listing the instances with the client
+func NewInstanceCollector(c client.Client) *InstanceCollector {
+	return &InstanceCollector{
+		client: c,
+		desc:   prometheus.NewDesc("kudo_instances", "Number of instances by status.", []string{"status"}, nil),
+	}
+}
+
+// RegisterInstanceCollector registers an InstanceCollector listing the instances with the client
+func RegisterInstanceCollector(c client.Client) error {
+	return metrics.Registry.Register(NewInstanceCollector(c))
+}
+
+// Describe implements prometheus.Collector
+func (c *InstanceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect implements prometheus.Collector
+func (c *InstanceCollector) Collect(ch chan<- prometheus.Metric) {
+	instances := &kudov1alpha1.InstanceList{}
+	if err := c.client.List(context.TODO(), instances); err != nil {
+		log.Error(err, "Error listing instances")
+		return
+	}
+
+	counts := map[kudov1alpha1.PhaseState]int{}
+	for _, i := range instances.Items {
+		counts[i.Status.Status]++
+	}
+	for status, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(count), string(status))
+	}
+}
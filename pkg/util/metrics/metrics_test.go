@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	kudov1alpha1 "github.com/kudobuilder/kudo/pkg/apis/kudo/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestInstanceCollector(t *testing.T) {
+	s := runtime.NewScheme()
+	assert.NoError(t, kudov1alpha1.AddToScheme(s))
+
+	instance := func(name string, status kudov1alpha1.PhaseState) runtime.Object {
+		return &kudov1alpha1.Instance{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Status:     kudov1alpha1.InstanceStatus{Status: status},
+		}
+	}
+	c := fake.NewFakeClientWithScheme(s,
+		instance("a", kudov1alpha1.PhaseStateComplete),
+		instance("b", kudov1alpha1.PhaseStateComplete),
+		instance("c", kudov1alpha1.PhaseStateInProgress),
+	)
+
+	expected := `
+# HELP kudo_instances Number of instances by status.
+# TYPE kudo_instances gauge
+kudo_instances{status="COMPLETE"} 2
+kudo_instances{status="IN_PROGRESS"} 1
+`
+	assert.NoError(t, testutil.CollectAndCompare(NewInstanceCollector(c), strings.NewReader(expected)))
+}
+
+// observations records the observed values
+type observations []float64
+
+func (o *observations) Observe(v float64) {
+	*o = append(*o, v)
+}
+
+func TestTimers(t *testing.T) {
+	observed := &observations{}
+	timers := NewTimers()
+
+	// stopping a timer which is not running observes nothing
+	timers.Stop("key", observed)
+	assert.Empty(t, *observed)
+
+	// starting a running timer again keeps the first start
+	timers.Start("key")
+	timers.Start("key")
+	timers.Stop("key", observed)
+	timers.Stop("key", observed)
+	if assert.Len(t, *observed, 1) {
+		assert.True(t, (*observed)[0] >= 0)
+	}
+}
+
+func TestTimersClear(t *testing.T) {
+	observed := &observations{}
+	timers := NewTimers()
+
+	timers.Start("pe-1/phase")
+	timers.Start("pe-1/phase/step")
+	timers.Start("pe-10/phase")
+	timers.Clear("pe-1/")
+
+	timers.Stop("pe-1/phase", observed)
+	timers.Stop("pe-1/phase/step", observed)
+	assert.Empty(t, *observed)
+	timers.Stop("pe-10/phase", observed)
+	assert.Len(t, *observed, 1)
+}
@@ -1,9 +1,15 @@
 package template
 
 import (
+	"bufio"
+	"io"
 	"strings"
 
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	apijson "k8s.io/apimachinery/pkg/util/json"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
 
 	"k8s.io/client-go/kubernetes/scheme"
 )
@@ -28,3 +34,33 @@ func ParseKubernetesObjects(yaml string) (objs []runtime.Object, err error) {
 	}
 	return
 }
+
+// ParseUnstructuredObjects parses the documents of the provided yaml into unstructured objects, which contain only the
+// fields set in the yaml. Empty documents are skipped.
+func ParseUnstructuredObjects(content string) ([]*unstructured.Unstructured, error) {
+	reader := k8syaml.NewYAMLReader(bufio.NewReader(strings.NewReader(content)))
+	var objs []*unstructured.Unstructured
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			return objs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// decoding the JSON with apimachinery keeps integers as int64 like the unstructured client does
+		json, err := yaml.YAMLToJSON(doc)
+		if err != nil {
+			return nil, err
+		}
+		obj := map[string]interface{}{}
+		if err := apijson.Unmarshal(json, &obj); err != nil {
+			return nil, err
+		}
+		if len(obj) == 0 {
+			continue
+		}
+		objs = append(objs, &unstructured.Unstructured{Object: obj})
+	}
+}